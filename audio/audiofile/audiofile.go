@@ -0,0 +1,205 @@
+// Package audiofile opens and creates audio files in the supported formats
+// (WAV, FLAC, Ogg Opus and headerless raw PCM). The format is chosen by the
+// extension of the file or by the Format option. Ogg Opus files can only
+// be played; they are recorded from the encoded frames by the oggWriter.
+package audiofile
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/sinks/flacWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/pcmWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/wavWriter"
+	"github.com/dh1tw/remoteAudio/audio/sources/flacReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/oggReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/pcmReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/wavReader"
+)
+
+// supported file formats
+const (
+	WAV   = "wav"
+	FLAC  = "flac"
+	OPUS  = "opus"          // Ogg Opus
+	S16LE = pcmReader.S16LE // raw PCM, signed 16 bit integer, little endian
+	F32LE = pcmReader.F32LE // raw PCM, 32 bit float, little endian
+)
+
+// extensions maps the file extensions to the formats
+var extensions = map[string]string{
+	".wav":  WAV,
+	".flac": FLAC,
+	".opus": OPUS,
+	".raw":  S16LE,
+	".pcm":  S16LE,
+	".s16":  S16LE,
+	".f32":  F32LE,
+}
+
+// Source is an audio.Source which plays a file.
+type Source interface {
+	audio.Source
+	Samplerate() float64
+	Channels() int
+	Duration() time.Duration
+	Position() time.Duration
+	Seek(time.Duration) error
+	Pause() error
+	SetLoop(bool)
+}
+
+// Sink is an audio.Sink which records into a file.
+type Sink interface {
+	audio.Sink
+	Size() int
+}
+
+// FormatOf returns the format of the file derived from its extension.
+func FormatOf(path string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	format, ok := extensions[ext]
+	if !ok {
+		return "", fmt.Errorf("unsupported audio file type '%s'", ext)
+	}
+	return format, nil
+}
+
+// Extension returns the file extension (including the dot) of a format.
+func Extension(format string) (string, error) {
+	switch strings.ToLower(format) {
+	case WAV:
+		return ".wav", nil
+	case FLAC:
+		return ".flac", nil
+	case OPUS:
+		return ".opus", nil
+	case S16LE:
+		return ".s16", nil
+	case F32LE:
+		return ".f32", nil
+	}
+	return "", fmt.Errorf("unsupported audio format '%s'", format)
+}
+
+// NewSource opens an audio file for playback.
+func NewSource(path string, opts ...Option) (Source, error) {
+
+	options := Options{}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	format, err := formatOf(path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var src Source
+
+	switch format {
+	case FLAC:
+		fOpts := []flacReader.Option{flacReader.Loop(options.Loop)}
+		if options.FramesPerBuffer > 0 {
+			fOpts = append(fOpts, flacReader.FramesPerBuffer(options.FramesPerBuffer))
+		}
+		src, err = flacReader.NewFlacReader(path, fOpts...)
+
+	case OPUS:
+		oOpts := []oggReader.Option{oggReader.Loop(options.Loop)}
+		if options.FramesPerBuffer > 0 {
+			oOpts = append(oOpts, oggReader.FramesPerBuffer(options.FramesPerBuffer))
+		}
+		src, err = oggReader.NewOggReader(path, oOpts...)
+
+	case S16LE, F32LE:
+		pOpts := []pcmReader.Option{
+			pcmReader.Encoding(format),
+			pcmReader.Loop(options.Loop),
+		}
+		if options.FramesPerBuffer > 0 {
+			pOpts = append(pOpts, pcmReader.FramesPerBuffer(options.FramesPerBuffer))
+		}
+		if options.Samplerate > 0 {
+			pOpts = append(pOpts, pcmReader.Samplerate(options.Samplerate))
+		}
+		if options.Channels > 0 {
+			pOpts = append(pOpts, pcmReader.Channels(options.Channels))
+		}
+		src, err = pcmReader.NewPcmReader(path, pOpts...)
+
+	default:
+		wOpts := []wavReader.Option{wavReader.Loop(options.Loop)}
+		if options.FramesPerBuffer > 0 {
+			wOpts = append(wOpts, wavReader.FramesPerBuffer(options.FramesPerBuffer))
+		}
+		src, err = wavReader.NewWavReader(path, wOpts...)
+	}
+
+	// don't return a typed nil pointer in the interface
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// NewSink creates an audio file for recording. An existing file will
+// be overwritten.
+func NewSink(path string, opts ...Option) (Sink, error) {
+
+	options := Options{
+		Samplerate: wavWriter.DefaultSamplerate,
+		Channels:   wavWriter.DefaultChannels,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	format, err := formatOf(path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink Sink
+
+	switch format {
+	case FLAC:
+		sink, err = flacWriter.NewFlacWriter(path,
+			flacWriter.Samplerate(options.Samplerate),
+			flacWriter.Channels(options.Channels))
+
+	case OPUS:
+		return nil, fmt.Errorf("can not record decoded audio as %s", format)
+
+	case S16LE, F32LE:
+		sink, err = pcmWriter.NewPcmWriter(path,
+			pcmWriter.Encoding(format),
+			pcmWriter.Samplerate(options.Samplerate),
+			pcmWriter.Channels(options.Channels))
+
+	default:
+		sink, err = wavWriter.NewWavWriter(path,
+			wavWriter.Samplerate(options.Samplerate),
+			wavWriter.Channels(options.Channels))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+// formatOf returns the format set in the options or otherwise the format
+// derived from the extension of the file.
+func formatOf(path string, options Options) (string, error) {
+	if len(options.Format) == 0 {
+		return FormatOf(path)
+	}
+	if _, err := Extension(options.Format); err != nil {
+		return "", err
+	}
+	return strings.ToLower(options.Format), nil
+}
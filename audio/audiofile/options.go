@@ -0,0 +1,55 @@
+package audiofile
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options contains the parameters for opening or creating audio files.
+// Parameters which are contained in the header of a file (e.g. the
+// samplerate of a WAV file) are only used when the file is created.
+type Options struct {
+	Format          string
+	Samplerate      float64
+	Channels        int
+	FramesPerBuffer int
+	Loop            bool
+}
+
+// Format is a functional option to set the format of the file (WAV, FLAC,
+// OPUS, S16LE or F32LE). By default the format is derived from the extension
+// of the file.
+func Format(f string) Option {
+	return func(args *Options) {
+		args.Format = f
+	}
+}
+
+// Samplerate is a functional option to set the samplerate of a new file
+// or of a raw PCM file.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// Channels is a functional option to set the amount of channels of a new
+// file or of a raw PCM file.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// FramesPerBuffer is a functional option which sets the amount of audio
+// frames a Source will provide when executing the callback.
+func FramesPerBuffer(s int) Option {
+	return func(args *Options) {
+		args.FramesPerBuffer = s
+	}
+}
+
+// Loop is a functional option which enables the looping of a Source.
+func Loop(enabled bool) Option {
+	return func(args *Options) {
+		args.Loop = enabled
+	}
+}
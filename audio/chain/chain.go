@@ -57,6 +57,10 @@ func NewChain(opts ...Option) (*Chain, error) {
 	nc.defaultSource = options.DefaultSource
 	nc.Nodes = options.Nodes
 
+	if options.Selector != nil {
+		nc.Sources = options.Selector
+	}
+
 	nodesCount := len(nc.Nodes)
 
 	// Wire up the chain, connect the source->nodes->sink with each other
@@ -87,6 +91,11 @@ func NewChain(opts ...Option) (*Chain, error) {
 	return nc, nil
 }
 
+// DefaultSource returns the name of the chain's default source.
+func (nc *Chain) DefaultSource() string {
+	return nc.defaultSource
+}
+
 // Enable will enable or disable the chain. This is done be enabling
 // or disableing the selected default sink in the chain.
 func (nc *Chain) Enable(state bool) error {
@@ -113,14 +122,5 @@ func (nc *Chain) defaultSourceToSinkCb(data audio.Msg) {
 		if err := nc.Sources.SetSource(nc.defaultSource); err != nil {
 			log.Println(err)
 		}
-		// if len(nc.dvkPlaying) > 0 {
-		// 	defer func() { nc.dvkPlaying = "" }()
-		// 	if err := nc.ToRadioSources.RemoveSource(nc.dvkPlaying); err != nil {
-		// 		log.Println(err)
-		// 	}
-		// 	if err := nc.FromRadioSources.RemoveSource(nc.dvkPlaying); err != nil {
-		// 		log.Println(err)
-		// 	}
-		// }
 	}
 }
@@ -11,6 +11,7 @@ type Options struct {
 	DefaultSource string
 	DefaultSink   string
 	Nodes         []audio.Node
+	Selector      audio.Selector
 }
 
 // DefaultSource is a functional option which sets the name of the default source
@@ -38,3 +39,12 @@ func Node(n audio.Node) Option {
 		args.Nodes = append(args.Nodes, n)
 	}
 }
+
+// Selector is a functional option which replaces the DefaultSelector of the
+// audio chain, e.g. with an audio.Mixer to hear several sources at the
+// same time.
+func Selector(s audio.Selector) Option {
+	return func(args *Options) {
+		args.Selector = s
+	}
+}
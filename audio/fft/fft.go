@@ -0,0 +1,95 @@
+// Package fft provides a radix-2 fast fourier transformation and window
+// functions for the audio nodes which process the audio in the frequency
+// domain.
+package fft
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// FFT calculates in-place the discrete fourier transformation of x. The
+// length of x must be a power of two.
+func FFT(x []complex128) error {
+	return transform(x, false)
+}
+
+// IFFT calculates in-place the inverse discrete fourier transformation of
+// x (including the 1/n scaling). The length of x must be a power of two.
+func IFFT(x []complex128) error {
+	return transform(x, true)
+}
+
+// IsPowerOfTwo returns true if n is a power of two
+func IsPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
+
+// Hann returns a periodic hann window of length n
+func Hann(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+	return w
+}
+
+// SqrtHann returns the square root of a periodic hann window of length
+// n. Used as analysis and synthesis window with an overlap of 50%, the
+// windows sum up to one.
+func SqrtHann(n int) []float64 {
+	w := Hann(n)
+	for i := range w {
+		w[i] = math.Sqrt(w[i])
+	}
+	return w
+}
+
+func transform(x []complex128, inverse bool) error {
+	n := len(x)
+	if !IsPowerOfTwo(n) {
+		return fmt.Errorf("fft: length %d is not a power of two", n)
+	}
+	if n == 1 {
+		return nil
+	}
+
+	// bit reversal permutation
+	shift := 64 - uint(bits.TrailingZeros(uint(n)))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < half; k++ {
+				t := w * x[start+k+half]
+				x[start+k+half] = x[start+k] - t
+				x[start+k] += t
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+
+	return nil
+}
@@ -1,5 +1,11 @@
 package audio
 
+import (
+	"time"
+
+	"github.com/chewxy/math32"
+)
+
 // AdjustChannels is a helper function which will either add or
 // remove a channel (e.g. converting from Mono to Stereo or vice versa).
 func AdjustChannels(iChs, oChs int, audioFrames []float32) []float32 {
@@ -35,3 +41,21 @@ func AdjustVolume(volume float32, aBuffer []float32) {
 		aBuffer[i] *= volume
 	}
 }
+
+// NewBuffer returns the buffer into which a node writes the processed
+// audio. The data of a Msg might be shared with other nodes or sinks
+// (e.g. when the Router or a tap distributes it), therefore nodes must
+// never modify msg.Data in place.
+func NewBuffer(samples int) []float32 {
+	return make([]float32, samples)
+}
+
+// OnePoleCoefficient returns the coefficient of a one-pole smoothing
+// filter (e.g. the attack / release of an envelope follower) with the time
+// constant t. A time constant <= 0 results in no smoothing.
+func OnePoleCoefficient(t time.Duration, samplerate float64) float32 {
+	if t <= 0 {
+		return 1
+	}
+	return 1 - math32.Exp(-1/float32(t.Seconds()*samplerate))
+}
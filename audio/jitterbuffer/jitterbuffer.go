@@ -0,0 +1,317 @@
+package jitterbuffer
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is returned by the JitterBuffer when a packet is requested for
+// playout.
+type Status int
+
+const (
+	// Buffering indicates that the buffer is (re)filling and that no
+	// packet should be played out yet.
+	Buffering Status = iota
+	// Ready indicates that the next packet in sequence has been returned.
+	Ready
+	// Missing indicates that the packet which is due for playout has
+	// not arrived (yet). It is considered lost.
+	Missing
+)
+
+// maxSeqJump is the maximum distance (in packets) between the sequence
+// number of a received packet and the one which is due for playout.
+// Packets further away belong to a new stream (e.g. the sender has
+// been restarted).
+const maxSeqJump = 500
+
+// Packet is an element stored in the JitterBuffer. The sequence number
+// and the timestamp are set by the sender.
+type Packet struct {
+	Seq       uint32        // sequence number; incremented by the sender for each packet
+	Timestamp time.Time     // capture time; zero if unknown
+	Duration  time.Duration // amount of audio contained in the packet
+	Data      interface{}   // payload, typically an encoded audio frame
+}
+
+// Stats contains the counters and the current state of a JitterBuffer.
+type Stats struct {
+	Received    int           // packets put into the buffer
+	Lost        int           // packets which were not available at playout time
+	Late        int           // packets which arrived after their playout time
+	Duplicates  int           // packets which have been received more than once
+	Dropped     int           // packets discarded to limit the latency
+	Jitter      time.Duration // measured inter-arrival jitter
+	TargetDelay time.Duration // amount of audio the buffer aims to hold
+	Delay       time.Duration // amount of audio currently buffered
+}
+
+// JitterBuffer is an adaptive jitter buffer. Packets can be put into the
+// buffer in any order. The buffer reorders them, drops duplicates and late
+// packets and releases them in sequence. The amount of buffered audio
+// (target delay) adapts to the measured inter-arrival jitter.
+type JitterBuffer struct {
+	sync.Mutex
+	options       Options
+	packets       []Packet // sorted by sequence number
+	nextSeq       uint32
+	started       bool
+	buffering     bool
+	newestTs      time.Time // timestamp of the newest packet received
+	jitter        float64   // inter-arrival jitter in seconds (RFC3550)
+	lastTransit   float64
+	hasTransit    bool
+	frameDuration time.Duration
+	stats         Stats
+}
+
+// New returns an initialized JitterBuffer. By default the target delay
+// will be kept between 20ms and 400ms.
+func New(opts ...Option) *JitterBuffer {
+	jb := &JitterBuffer{
+		options: Options{
+			MinDelay:     time.Millisecond * 20,
+			MaxDelay:     time.Millisecond * 400,
+			JitterFactor: 3,
+			MaxPackets:   50,
+		},
+		packets:       []Packet{},
+		buffering:     true,
+		frameDuration: time.Millisecond * 20,
+	}
+
+	for _, opt := range opts {
+		opt(&jb.options)
+	}
+
+	return jb
+}
+
+// Put inserts a packet into the buffer. Duplicates and packets which
+// arrive after their playout time are discarded. A packet which doesn't
+// fit into the sequence of the current stream (see discontinuity) resets
+// the buffer and starts a new stream.
+func (jb *JitterBuffer) Put(p Packet) {
+	jb.Lock()
+	defer jb.Unlock()
+
+	jb.stats.Received++
+
+	if p.Duration > 0 {
+		jb.frameDuration = p.Duration
+	}
+
+	if jb.started && jb.discontinuity(p) {
+		jb.reset()
+	}
+
+	jb.updateJitter(p.Timestamp, time.Now())
+
+	if p.Timestamp.After(jb.newestTs) {
+		jb.newestTs = p.Timestamp
+	}
+
+	if jb.started && seqLess(p.Seq, jb.nextSeq) {
+		jb.stats.Late++
+		return
+	}
+
+	idx := sort.Search(len(jb.packets), func(i int) bool {
+		return !seqLess(jb.packets[i].Seq, p.Seq)
+	})
+
+	if idx < len(jb.packets) && jb.packets[idx].Seq == p.Seq {
+		jb.stats.Duplicates++
+		return
+	}
+
+	jb.packets = append(jb.packets, Packet{})
+	copy(jb.packets[idx+1:], jb.packets[idx:])
+	jb.packets[idx] = p
+
+	if len(jb.packets) > jb.options.MaxPackets {
+		jb.dropOldest()
+	}
+}
+
+// Get returns the packet which is due for playout. It is expected to be
+// called periodically with the interval of the packet duration. In case
+// the packet is missing, an empty packet with the expected sequence number
+// and the status Missing is returned.
+func (jb *JitterBuffer) Get() (Packet, Status) {
+	jb.Lock()
+	defer jb.Unlock()
+
+	if len(jb.packets) == 0 {
+		// buffer underrun; refill the buffer before continuing the playout
+		jb.buffering = true
+		return Packet{}, Buffering
+	}
+
+	if jb.buffering {
+		if jb.delay() < jb.targetDelay() {
+			return Packet{}, Buffering
+		}
+		if jb.started && seqLess(jb.nextSeq, jb.packets[0].Seq) {
+			jb.stats.Lost += int(jb.packets[0].Seq - jb.nextSeq)
+		}
+		jb.nextSeq = jb.packets[0].Seq
+		jb.started = true
+		jb.buffering = false
+	}
+
+	// limit the latency in case much more audio than necessary has
+	// been accumulated (e.g. after a network stall or due to clock drift)
+	maxDelay := jb.targetDelay()*2 + jb.frameDuration*2
+	for len(jb.packets) > 1 && jb.delay() > maxDelay {
+		jb.dropOldest()
+	}
+
+	if jb.packets[0].Seq != jb.nextSeq {
+		p := Packet{Seq: jb.nextSeq, Duration: jb.frameDuration}
+		jb.nextSeq++
+		jb.stats.Lost++
+		return p, Missing
+	}
+
+	p := jb.packets[0]
+	jb.packets = jb.packets[1:]
+	jb.nextSeq++
+
+	return p, Ready
+}
+
+// Peek returns the next packet in sequence without removing it from
+// the buffer. The boolean return value indicates if the packet is
+// available.
+func (jb *JitterBuffer) Peek() (Packet, bool) {
+	jb.Lock()
+	defer jb.Unlock()
+
+	if len(jb.packets) == 0 || jb.packets[0].Seq != jb.nextSeq {
+		return Packet{}, false
+	}
+	return jb.packets[0], true
+}
+
+// Reset discards all buffered packets. The next packet put into the
+// buffer will be considered the beginning of a new stream.
+func (jb *JitterBuffer) Reset() {
+	jb.Lock()
+	defer jb.Unlock()
+	jb.reset()
+}
+
+// reset discards all buffered packets. This method is not safe for
+// concurrent access.
+func (jb *JitterBuffer) reset() {
+	jb.packets = []Packet{}
+	jb.started = false
+	jb.buffering = true
+	jb.hasTransit = false
+	jb.newestTs = time.Time{}
+}
+
+// discontinuity returns true if the packet doesn't belong to the current
+// stream. This is the case if its sequence number is far away from the
+// one which is due for playout, or if the packet is late although it
+// has been captured after the newest packet received so far. Both
+// happen when the sender has restarted its sequence numbers. This method
+// is not safe for concurrent access.
+func (jb *JitterBuffer) discontinuity(p Packet) bool {
+	d := int32(p.Seq - jb.nextSeq)
+	if d < -maxSeqJump || d > maxSeqJump {
+		return true
+	}
+
+	return d < 0 && !p.Timestamp.IsZero() && !jb.newestTs.IsZero() &&
+		p.Timestamp.After(jb.newestTs)
+}
+
+// Stats returns a snapshot of the JitterBuffer's statistics.
+func (jb *JitterBuffer) Stats() Stats {
+	jb.Lock()
+	defer jb.Unlock()
+
+	s := jb.stats
+	s.Jitter = time.Duration(jb.jitter * float64(time.Second))
+	s.TargetDelay = jb.targetDelay()
+	s.Delay = jb.delay()
+
+	return s
+}
+
+// TargetDelay returns the amount of audio the buffer currently aims
+// to hold.
+func (jb *JitterBuffer) TargetDelay() time.Duration {
+	jb.Lock()
+	defer jb.Unlock()
+	return jb.targetDelay()
+}
+
+// updateJitter calculates the interarrival jitter as specified in RFC3550.
+// Since only the differences of the transit times are taken into account,
+// the clocks of the sender and the receiver don't have to be in sync.
+func (jb *JitterBuffer) updateJitter(sent, arrival time.Time) {
+	if sent.IsZero() {
+		return
+	}
+
+	transit := arrival.Sub(sent).Seconds()
+
+	if jb.hasTransit {
+		d := math.Abs(transit - jb.lastTransit)
+		jb.jitter += (d - jb.jitter) / 16
+	}
+
+	jb.lastTransit = transit
+	jb.hasTransit = true
+}
+
+// targetDelay returns the amount of audio which should be buffered. This
+// method is not safe for concurrent access.
+func (jb *JitterBuffer) targetDelay() time.Duration {
+	jitter := time.Duration(jb.options.JitterFactor * jb.jitter * float64(time.Second))
+	target := jb.frameDuration + jitter
+
+	if target < jb.options.MinDelay {
+		target = jb.options.MinDelay
+	}
+	if target > jb.options.MaxDelay {
+		target = jb.options.MaxDelay
+	}
+
+	return target
+}
+
+// delay returns the amount of audio between the oldest and the newest
+// packet in the buffer, including the gaps. This method is not safe for
+// concurrent access.
+func (jb *JitterBuffer) delay() time.Duration {
+	if len(jb.packets) == 0 {
+		return 0
+	}
+	first := jb.packets[0].Seq
+	last := jb.packets[len(jb.packets)-1].Seq
+
+	return time.Duration(last-first+1) * jb.frameDuration
+}
+
+// dropOldest discards the oldest packet in the buffer. This method is not
+// safe for concurrent access.
+func (jb *JitterBuffer) dropOldest() {
+	jb.packets = jb.packets[1:]
+	jb.stats.Dropped++
+	if jb.started && len(jb.packets) > 0 {
+		jb.nextSeq = jb.packets[0].Seq
+	}
+}
+
+// seqLess returns true if sequence number a is older than b. Wrap arounds
+// of the sequence numbers are taken into account.
+func seqLess(a, b uint32) bool {
+	return int32(a-b) < 0
+}
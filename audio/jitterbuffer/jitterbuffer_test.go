@@ -0,0 +1,209 @@
+package jitterbuffer
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// drain dequeues all packets from the buffer and returns the sequence
+// numbers of the played out and the missing packets.
+func drain(jb *JitterBuffer) (ready, missing []uint32) {
+	for i := 0; i < 100; i++ {
+		p, status := jb.Get()
+		switch status {
+		case Ready:
+			ready = append(ready, p.Seq)
+		case Missing:
+			missing = append(missing, p.Seq)
+		case Buffering:
+			return ready, missing
+		}
+	}
+	return ready, missing
+}
+
+func TestJitterBufferSequence(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		put        []uint32
+		ready      []uint32
+		missing    []uint32
+		duplicates int
+		lost       int
+	}{
+		{
+			name:  "in order",
+			put:   []uint32{1, 2, 3, 4},
+			ready: []uint32{1, 2, 3, 4},
+		},
+		{
+			name:  "reordered",
+			put:   []uint32{3, 1, 4, 2},
+			ready: []uint32{1, 2, 3, 4},
+		},
+		{
+			name:       "duplicates",
+			put:        []uint32{1, 2, 2, 3, 1},
+			ready:      []uint32{1, 2, 3},
+			duplicates: 2,
+		},
+		{
+			name:    "loss",
+			put:     []uint32{1, 2, 5},
+			ready:   []uint32{1, 2, 5},
+			missing: []uint32{3, 4},
+			lost:    2,
+		},
+		{
+			name:  "wraparound",
+			put:   []uint32{math.MaxUint32, 0, math.MaxUint32 - 1, 1},
+			ready: []uint32{math.MaxUint32 - 1, math.MaxUint32, 0, 1},
+		},
+		{
+			name:    "loss across wraparound",
+			put:     []uint32{math.MaxUint32 - 1, 1},
+			ready:   []uint32{math.MaxUint32 - 1, 1},
+			missing: []uint32{math.MaxUint32, 0},
+			lost:    2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jb := New(MinDelay(time.Millisecond * 60))
+			for _, seq := range tc.put {
+				jb.Put(Packet{Seq: seq, Duration: time.Millisecond * 20})
+			}
+
+			ready, missing := drain(jb)
+			if !reflect.DeepEqual(ready, tc.ready) {
+				t.Errorf("ready: got %v, expected %v", ready, tc.ready)
+			}
+			if !reflect.DeepEqual(missing, tc.missing) {
+				t.Errorf("missing: got %v, expected %v", missing, tc.missing)
+			}
+
+			stats := jb.Stats()
+			if stats.Received != len(tc.put) {
+				t.Errorf("received: got %d, expected %d", stats.Received, len(tc.put))
+			}
+			if stats.Duplicates != tc.duplicates {
+				t.Errorf("duplicates: got %d, expected %d", stats.Duplicates, tc.duplicates)
+			}
+			if stats.Lost != tc.lost {
+				t.Errorf("lost: got %d, expected %d", stats.Lost, tc.lost)
+			}
+		})
+	}
+}
+
+func TestJitterBufferLate(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		late  Packet
+		ready []uint32
+		count int // expected amount of late packets
+	}{
+		{
+			name:  "late packet is discarded",
+			late:  Packet{Seq: 1001},
+			count: 1,
+		},
+		{
+			name:  "late packet with older timestamp is discarded",
+			late:  Packet{Seq: 1001, Timestamp: time.Unix(100, 0)},
+			count: 1,
+		},
+		{
+			name:  "large backwards jump starts a new stream",
+			late:  Packet{Seq: 1},
+			ready: []uint32{1},
+		},
+		{
+			name:  "large forward jump starts a new stream",
+			late:  Packet{Seq: 100000},
+			ready: []uint32{100000},
+		},
+		{
+			name:  "late packet with newer timestamp starts a new stream",
+			late:  Packet{Seq: 999, Timestamp: time.Unix(200, 0)},
+			ready: []uint32{999},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// the jitter measured with the local clock must not delay the
+			// playout
+			jb := New(JitterFactor(0))
+			for seq := uint32(1000); seq < 1005; seq++ {
+				jb.Put(Packet{
+					Seq:       seq,
+					Timestamp: time.Unix(100, int64(seq-1000)*int64(time.Millisecond*20)),
+					Duration:  time.Millisecond * 20,
+				})
+				jb.Get()
+			}
+
+			tc.late.Duration = time.Millisecond * 20
+			jb.Put(tc.late)
+
+			ready, _ := drain(jb)
+			if !reflect.DeepEqual(ready, tc.ready) {
+				t.Errorf("ready: got %v, expected %v", ready, tc.ready)
+			}
+			if late := jb.Stats().Late; late != tc.count {
+				t.Errorf("late: got %d, expected %d", late, tc.count)
+			}
+		})
+	}
+}
+
+func TestJitterBufferJitter(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		transits  []time.Duration
+		minJitter time.Duration
+		maxJitter time.Duration
+	}{
+		{
+			name:     "constant transit time",
+			transits: []time.Duration{50, 50, 50, 50, 50, 50},
+		},
+		{
+			name:      "varying transit time",
+			transits:  []time.Duration{0, 10, 0, 10, 0, 10, 0, 10},
+			minJitter: time.Millisecond * 3,
+			maxJitter: time.Millisecond * 5,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jb := New(MinDelay(0))
+			sent := time.Unix(100, 0)
+			for _, transit := range tc.transits {
+				jb.updateJitter(sent, sent.Add(transit*time.Millisecond))
+				sent = sent.Add(time.Millisecond * 20)
+			}
+
+			stats := jb.Stats()
+			if stats.Jitter < tc.minJitter || stats.Jitter > tc.maxJitter {
+				t.Errorf("jitter: got %v, expected %v...%v", stats.Jitter,
+					tc.minJitter, tc.maxJitter)
+			}
+
+			// the target delay covers the frame and the weighted jitter
+			target := time.Millisecond*20 + 3*stats.Jitter
+			if diff := stats.TargetDelay - target; diff < -time.Microsecond ||
+				diff > time.Microsecond {
+				t.Errorf("target delay: got %v, expected %v", stats.TargetDelay, target)
+			}
+		})
+	}
+}
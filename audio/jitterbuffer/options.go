@@ -0,0 +1,52 @@
+package jitterbuffer
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options is the data structure which holds the option values. The values
+// are set through functional options.
+type Options struct {
+	MinDelay     time.Duration
+	MaxDelay     time.Duration
+	JitterFactor float64
+	MaxPackets   int
+}
+
+// MinDelay is a functional option to set the minimum amount of audio
+// which will be buffered before the playout starts. By default 20ms
+// are buffered.
+func MinDelay(d time.Duration) Option {
+	return func(args *Options) {
+		args.MinDelay = d
+	}
+}
+
+// MaxDelay is a functional option to set the upper limit of the target
+// delay. If the measured jitter is very high, the target delay will be
+// clipped to this value. The default value is 400ms.
+func MaxDelay(d time.Duration) Option {
+	return func(args *Options) {
+		args.MaxDelay = d
+	}
+}
+
+// JitterFactor is a functional option to set the factor with which the
+// measured inter-arrival jitter is multiplied to determine the target
+// delay. Larger values make the buffer more robust, but add latency.
+// By default a factor of 3 is used.
+func JitterFactor(f float64) Option {
+	return func(args *Options) {
+		args.JitterFactor = f
+	}
+}
+
+// MaxPackets is a functional option to set the maximum amount of packets
+// which can be stored in the buffer. When the buffer is full, the oldest
+// packet will be discarded. The default value is 50.
+func MaxPackets(n int) Option {
+	return func(args *Options) {
+		args.MaxPackets = n
+	}
+}
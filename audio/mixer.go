@@ -0,0 +1,556 @@
+package audio
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Mixer is an implementation of an audio Selector which sums the audio of
+// several active sources (e.g. a voice keyer and the microphone). Each
+// source has its own gain and pan. The sources are buffered and mixed
+// into frames of a common format which are provided through the OnDataCb
+// callback at the pace of the mixer's clock. The audio of the sources is
+// converted (linear interpolation) to the samplerate of the mixer. When
+// all sources in the mix have reached their end, the last buffer is
+// marked as EOF.
+type Mixer struct {
+	sync.Mutex
+	sources    map[string]*mixerSource
+	onDataCb   OnDataCb
+	samplerate float64
+	channels   int
+	frames     int // frames per buffer
+	stopCh     chan struct{}
+	closed     bool
+}
+
+// mixerSource holds the state of a source of the Mixer. The buffer has its
+// own lock, since some sources execute the callback while holding their
+// own lock; the mixer's lock might be held while stopping the source.
+type mixerSource struct {
+	Source
+	active bool // protected by the mixer's lock
+	sync.Mutex
+	gain    float32
+	pan     float32   // -1 (left) ... 0 (center) ... 1 (right)
+	buffer  []float32 // interleaved audio in the format of the mixer
+	primed  bool      // enough audio has been buffered to start mixing
+	eof     bool      // the source has reached its end
+	monitor OnDataCb  // called with the audio of the source before it is mixed
+	// state of the samplerate conversion
+	pos  float64   // position of the next output frame in the input
+	last []float32 // last frame of the previous msg
+}
+
+// MixerOption is the type for a function option of the Mixer
+type MixerOption func(*Mixer)
+
+// MixerSamplerate is a functional option to set the samplerate of the
+// mixed audio (default: 48000Hz).
+func MixerSamplerate(samplerate float64) MixerOption {
+	return func(m *Mixer) {
+		m.samplerate = samplerate
+	}
+}
+
+// MixerChannels is a functional option to set the amount of channels of
+// the mixed audio (1 = mono, 2 = stereo; default: mono). The pan of the
+// sources is only applied to stereo audio.
+func MixerChannels(chs int) MixerOption {
+	return func(m *Mixer) {
+		m.channels = chs
+	}
+}
+
+// MixerFrames is a functional option to set the amount of frames in each
+// buffer of mixed audio (default: 480 frames). It also determines the
+// interval of the mixer's clock.
+func MixerFrames(frames int) MixerOption {
+	return func(m *Mixer) {
+		m.frames = frames
+	}
+}
+
+// mixerMaxBuffers is the maximum amount of buffers which are kept for
+// a source. If a source delivers faster than the mixer's clock, the
+// oldest audio is dropped.
+const mixerMaxBuffers = 5
+
+// NewMixer returns an initialized, but empty Mixer. The mixer's clock
+// runs until the mixer is closed.
+func NewMixer(opts ...MixerOption) (*Mixer, error) {
+
+	m := &Mixer{
+		sources:    make(map[string]*mixerSource),
+		samplerate: 48000,
+		channels:   1,
+		frames:     480,
+		stopCh:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.samplerate <= 0 {
+		return nil, fmt.Errorf("invalid mixer samplerate %v", m.samplerate)
+	}
+	if m.channels < 1 || m.channels > 2 {
+		return nil, fmt.Errorf("invalid amount of mixer channels %v", m.channels)
+	}
+	if m.frames <= 0 {
+		return nil, fmt.Errorf("invalid amount of mixer frames %v", m.frames)
+	}
+
+	interval := time.Duration(float64(m.frames) / m.samplerate * float64(time.Second))
+	go m.run(interval)
+
+	return m, nil
+}
+
+// run is the mixer's clock. It mixes one buffer of audio for each tick.
+func (m *Mixer) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+			m.mix()
+		}
+	}
+}
+
+// mix sums the buffered audio of all active sources and provides it
+// through the callback. If no source is active, nothing is provided.
+// If the last active sources have reached their end, the msg is marked
+// as EOF.
+func (m *Mixer) mix() {
+	m.Lock()
+
+	if m.onDataCb == nil {
+		m.Unlock()
+		return
+	}
+
+	cb := m.onDataCb
+	size := m.frames * m.channels
+	data := make([]float32, size)
+	active := false
+	ended := false
+
+	for name, src := range m.sources {
+		if !src.active {
+			continue
+		}
+		active = true
+
+		src.Lock()
+		if !src.primed {
+			src.Unlock()
+			continue
+		}
+
+		n := size
+		if len(src.buffer) < n {
+			n = len(src.buffer)
+		}
+		for i, s := range src.buffer[:n] {
+			data[i] += s
+		}
+		src.buffer = src.buffer[:copy(src.buffer, src.buffer[n:])]
+
+		// wait for the buffer to fill up again, if the source
+		// can't keep up
+		finished := false
+		if len(src.buffer) == 0 {
+			src.primed = false
+			finished = src.eof
+		}
+		src.Unlock()
+
+		if finished {
+			ended = true
+			src.active = false
+			if err := src.Stop(); err != nil {
+				log.Printf("mixer: unable to stop source %s: %v", name, err)
+			}
+			src.SetCb(nil)
+		}
+	}
+
+	eof := false
+	if ended {
+		eof = true
+		for _, src := range m.sources {
+			if src.active {
+				eof = false
+				break
+			}
+		}
+	}
+
+	m.Unlock()
+
+	if !active {
+		return
+	}
+
+	for i, s := range data {
+		if s > 1 {
+			data[i] = 1
+		} else if s < -1 {
+			data[i] = -1
+		}
+	}
+
+	cb(Msg{
+		Data:       data,
+		Samplerate: m.samplerate,
+		Channels:   m.channels,
+		Frames:     m.frames,
+		EOF:        eof,
+	})
+}
+
+// write converts the msg of a source into the format of the mixer and
+// adds it to the source's buffer. The format of the mixer is immutable,
+// therefore the mixer's lock is not needed.
+func (m *Mixer) write(src *mixerSource, msg Msg) {
+	src.Lock()
+	defer src.Unlock()
+
+	if msg.EOF {
+		src.eof = true
+		// the remaining audio is mixed, even if it is less than
+		// a complete buffer
+		src.primed = true
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if len(msg.Data) < chs || msg.Samplerate <= 0 {
+		return
+	}
+
+	// gain of the left and right channel (constant power isn't
+	// needed for the small pan ranges used for monitoring)
+	gainL, gainR := src.gain, src.gain
+	if m.channels == 2 {
+		if src.pan > 0 {
+			gainL *= 1 - src.pan
+		} else if src.pan < 0 {
+			gainR *= 1 + src.pan
+		}
+	}
+
+	frame := func(i int) (float32, float32) {
+		var in []float32
+		if i < 0 {
+			in = src.last
+		} else {
+			in = msg.Data[i*chs : i*chs+chs]
+		}
+		if chs == 1 {
+			return in[0], in[0]
+		}
+		return in[0], in[1]
+	}
+
+	if len(src.last) != chs {
+		src.last = make([]float32, chs)
+		src.pos = 0
+	}
+
+	frames := len(msg.Data) / chs
+	step := msg.Samplerate / m.samplerate
+
+	// src.pos is relative to the first frame of this msg; -1
+	// refers to the last frame of the previous msg
+	for ; src.pos < float64(frames-1); src.pos += step {
+		i := int(src.pos + 1) // index of the next input frame
+		frac := float32(src.pos + 1 - float64(i))
+		l0, r0 := frame(i - 1)
+		l1, r1 := frame(i)
+		l := l0 + (l1-l0)*frac
+		r := r0 + (r1-r0)*frac
+
+		if m.channels == 1 {
+			src.buffer = append(src.buffer, (l+r)/2*src.gain)
+		} else {
+			src.buffer = append(src.buffer, l*gainL, r*gainR)
+		}
+	}
+	src.pos -= float64(frames)
+	copy(src.last, msg.Data[(frames-1)*chs:])
+
+	size := m.frames * m.channels
+
+	if len(src.buffer) > size*mixerMaxBuffers {
+		drop := len(src.buffer) - size*mixerMaxBuffers
+		src.buffer = src.buffer[:copy(src.buffer, src.buffer[drop:])]
+	}
+
+	// keep one extra buffer to absorb the jitter between the
+	// source and the mixer's clock
+	if len(src.buffer) >= size*2 {
+		src.primed = true
+	}
+}
+
+// AddSource adds an audio device which implements the audio.Source
+// interface to the Mixer. The source is added with unity gain, centered
+// and inactive.
+func (m *Mixer) AddSource(name string, src Source) {
+	m.Lock()
+	defer m.Unlock()
+	m.sources[name] = &mixerSource{
+		Source: src,
+		gain:   1,
+	}
+}
+
+// RemoveSource stops and removes an audio Source from the Mixer.
+func (m *Mixer) RemoveSource(name string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+	if src.active {
+		src.Stop()
+		src.SetCb(nil)
+	}
+	delete(m.sources, name)
+	return nil
+}
+
+// SetSource selects the audio source which will be the only one in the
+// mix. All other sources are stopped. This is the behaviour of the
+// DefaultSelector; use EnableSource to mix several sources.
+func (m *Mixer) SetSource(name string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.onDataCb == nil {
+		return fmt.Errorf("mixer callback not set")
+	}
+
+	if _, ok := m.sources[name]; !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+
+	for srcName, src := range m.sources {
+		if srcName != name {
+			m.deactivate(src)
+		}
+	}
+
+	return m.activate(m.sources[name])
+}
+
+// EnableSource adds (enable = true) or removes (enable = false) a source
+// to / from the mix without affecting the other sources.
+func (m *Mixer) EnableSource(name string, enable bool) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.onDataCb == nil {
+		return fmt.Errorf("mixer callback not set")
+	}
+
+	src, ok := m.sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+
+	if !enable {
+		m.deactivate(src)
+		return nil
+	}
+
+	return m.activate(src)
+}
+
+// activate starts the source and adds it to the mix. This method is not
+// safe for concurrent access.
+func (m *Mixer) activate(src *mixerSource) error {
+	if src.active {
+		return nil
+	}
+
+	src.active = true
+	src.Lock()
+	src.buffer = nil
+	src.primed = false
+	src.eof = false
+	src.last = nil
+	src.Unlock()
+	src.SetCb(func(msg Msg) {
+		src.Lock()
+		monitor := src.monitor
+		src.Unlock()
+		if monitor != nil {
+			monitor(msg)
+		}
+		m.write(src, msg)
+	})
+
+	return src.Start()
+}
+
+// deactivate stops the source and removes it from the mix. This method
+// is not safe for concurrent access.
+func (m *Mixer) deactivate(src *mixerSource) {
+	if !src.active {
+		return
+	}
+	src.active = false
+	if err := src.Stop(); err != nil {
+		log.Println(err)
+	}
+	src.SetCb(nil)
+}
+
+// ActiveSources returns the names of the sources which are currently
+// in the mix.
+func (m *Mixer) ActiveSources() []string {
+	m.Lock()
+	defer m.Unlock()
+
+	names := []string{}
+	for name, src := range m.sources {
+		if src.active {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SetGain sets the gain of a source. Only values between 0...2 are
+// allowed. Values below or above will be clipped to the minimum or
+// maximum.
+func (m *Mixer) SetGain(name string, gain float32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+
+	if gain < 0 {
+		gain = 0
+	} else if gain > 2 {
+		gain = 2
+	}
+	src.Lock()
+	src.gain = gain
+	src.Unlock()
+	return nil
+}
+
+// Gain returns the gain of a source.
+func (m *Mixer) Gain(name string) (float32, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown source %s", name)
+	}
+	src.Lock()
+	defer src.Unlock()
+	return src.gain, nil
+}
+
+// SetPan sets the pan of a source from -1 (left) through 0 (center) to
+// 1 (right). Values below or above will be clipped to the minimum or
+// maximum. The pan has no effect if the mixer's audio is mono.
+func (m *Mixer) SetPan(name string, pan float32) error {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+
+	if pan < -1 {
+		pan = -1
+	} else if pan > 1 {
+		pan = 1
+	}
+	src.Lock()
+	src.pan = pan
+	src.Unlock()
+	return nil
+}
+
+// Pan returns the pan of a source.
+func (m *Mixer) Pan(name string) (float32, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown source %s", name)
+	}
+	src.Lock()
+	defer src.Unlock()
+	return src.pan, nil
+}
+
+// SetMonitorCb sets a callback which is executed with the audio of a
+// source before it is mixed (e.g. to evaluate the level of the
+// microphone while other sources are mixed in). A nil callback removes
+// the monitor.
+func (m *Mixer) SetMonitorCb(name string, cb OnDataCb) error {
+	m.Lock()
+	defer m.Unlock()
+
+	src, ok := m.sources[name]
+	if !ok {
+		return fmt.Errorf("unknown source %s", name)
+	}
+	src.Lock()
+	src.monitor = cb
+	src.Unlock()
+	return nil
+}
+
+// SetOnDataCb sets the callback function which will be executed with
+// each buffer of mixed audio.
+func (m *Mixer) SetOnDataCb(cb OnDataCb) {
+	m.Lock()
+	defer m.Unlock()
+	m.onDataCb = cb
+}
+
+// Close stops the mixer's clock and stops & closes all the Sources of
+// the mixer.
+func (m *Mixer) Close() {
+	m.Lock()
+	defer m.Unlock()
+
+	if !m.closed {
+		close(m.stopCh)
+		m.closed = true
+	}
+
+	for _, src := range m.sources {
+		m.deactivate(src)
+		if err := src.Close(); err != nil {
+			log.Println(err)
+		}
+	}
+}
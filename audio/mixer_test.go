@@ -0,0 +1,167 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// testSource is a Source which provides a constant signal when it is
+// started. The last msg is marked as EOF.
+type testSource struct {
+	cb      OnDataCb
+	value   float32
+	buffers int
+	frames  int
+}
+
+func (s *testSource) Start() error {
+	for i := 0; i < s.buffers; i++ {
+		data := make([]float32, s.frames)
+		for j := range data {
+			data[j] = s.value
+		}
+		s.cb(Msg{
+			Data:       data,
+			Samplerate: 48000,
+			Channels:   1,
+			Frames:     s.frames,
+			EOF:        i == s.buffers-1,
+		})
+	}
+	return nil
+}
+
+func (s *testSource) Stop() error       { return nil }
+func (s *testSource) Close() error      { return nil }
+func (s *testSource) SetCb(cb OnDataCb) { s.cb = cb }
+
+// newTestMixer returns a mixer without a clock, so that the test can
+// call mix() itself.
+func newTestMixer() *Mixer {
+	return &Mixer{
+		sources:    make(map[string]*mixerSource),
+		samplerate: 48000,
+		channels:   1,
+		frames:     480,
+		stopCh:     make(chan struct{}),
+	}
+}
+
+func TestMixerTwoSources(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		a, b   testSource
+		gainA  float32
+		gainB  float32
+		levels []float32 // expected level of each mixed buffer
+	}{
+		{
+			name:   "sum",
+			a:      testSource{value: 0.25, buffers: 3, frames: 480},
+			b:      testSource{value: 0.5, buffers: 3, frames: 480},
+			gainA:  1,
+			gainB:  0.5,
+			levels: []float32{0.5, 0.5, 0.5},
+		},
+		{
+			name:   "clipping",
+			a:      testSource{value: 0.8, buffers: 3, frames: 480},
+			b:      testSource{value: 0.8, buffers: 3, frames: 480},
+			gainA:  1,
+			gainB:  1,
+			levels: []float32{1, 1, 1},
+		},
+		{
+			name:   "source ends earlier",
+			a:      testSource{value: 0.25, buffers: 2, frames: 480},
+			b:      testSource{value: 0.5, buffers: 4, frames: 480},
+			gainA:  1,
+			gainB:  1,
+			levels: []float32{0.75, 0.75, 0.5, 0.5},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMixer()
+
+			var msgs []Msg
+			m.SetOnDataCb(func(msg Msg) {
+				msgs = append(msgs, msg)
+			})
+
+			m.AddSource("a", &tc.a)
+			m.AddSource("b", &tc.b)
+			if err := m.SetGain("a", tc.gainA); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.SetGain("b", tc.gainB); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.EnableSource("a", true); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.EnableSource("b", true); err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < len(tc.levels)+2; i++ {
+				m.mix()
+			}
+
+			if len(msgs) != len(tc.levels) {
+				t.Fatalf("got %d msgs, expected %d", len(msgs), len(tc.levels))
+			}
+
+			for i, msg := range msgs {
+				if msg.Frames != 480 || len(msg.Data) != 480 {
+					t.Errorf("msg %d: got %d frames, expected 480", i, msg.Frames)
+				}
+				// the interpolation holds back the last input frame,
+				// so the last buffer of a source is one sample short
+				for j, s := range msg.Data[:len(msg.Data)-1] {
+					if math.Abs(float64(s-tc.levels[i])) > 1e-6 {
+						t.Errorf("msg %d, sample %d: got %v, expected %v", i, j, s, tc.levels[i])
+						break
+					}
+				}
+				if eof := i == len(msgs)-1; msg.EOF != eof {
+					t.Errorf("msg %d: got EOF %v, expected %v", i, msg.EOF, eof)
+				}
+			}
+
+			if active := m.ActiveSources(); len(active) != 0 {
+				t.Errorf("sources %v still active after EOF", active)
+			}
+		})
+	}
+}
+
+func TestMixerGain(t *testing.T) {
+
+	m := newTestMixer()
+	m.AddSource("a", &testSource{})
+
+	tests := []struct {
+		gain     float32
+		expected float32
+	}{
+		{gain: 0.5, expected: 0.5},
+		{gain: -1, expected: 0},
+		{gain: 3, expected: 2},
+	}
+
+	for _, tc := range tests {
+		if err := m.SetGain("a", tc.gain); err != nil {
+			t.Fatal(err)
+		}
+		if g, _ := m.Gain("a"); g != tc.expected {
+			t.Errorf("SetGain(%v): got %v, expected %v", tc.gain, g, tc.expected)
+		}
+	}
+
+	if err := m.SetGain("unknown", 1); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
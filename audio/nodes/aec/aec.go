@@ -0,0 +1,347 @@
+package aec
+
+import (
+	"math"
+	"math/cmplx"
+	"sync"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/fft"
+)
+
+// blockSize is the amount of samples processed at once (~5ms @ 48kHz)
+const blockSize = 256
+
+// fftSize is the length of the transformations (overlap-save)
+const fftSize = 2 * blockSize
+
+// maxReferenceDelay is the maximum amount of reference audio which is
+// buffered ahead of the microphone audio. If the rx audio arrives faster
+// than the tx audio, the oldest reference audio is dropped.
+const maxReferenceDelay = time.Millisecond * 100
+
+// doubleTalkHold is the amount of blocks for which the adaption is
+// frozen after near end speech has been detected
+const doubleTalkHold = 30
+
+// AEC is an Audio Node which removes the acoustic echo of the speaker
+// from the microphone audio (e.g. when operating with laptop speakers
+// instead of a headset). The rx audio, which is played on the speaker,
+// is provided as a reference through WriteReference (see tap.Tap). A
+// partitioned block frequency domain NLMS filter models the echo path
+// and the estimated echo is subtracted from the microphone audio. The
+// adaption is frozen while the operator speaks (double talk). Stereo
+// microphone audio is mixed down to mono. The node delays the audio by
+// 256 samples.
+type AEC struct {
+	sync.Mutex
+	enabled    bool
+	cb         audio.OnDataCb
+	tailLength time.Duration
+	mu         float64
+	samplerate float64
+
+	// the reference (speaker) audio; has its own lock since it is
+	// written from the rx chain
+	refMu      sync.Mutex
+	ref        []float64
+	refRate    float64
+	refMaxSize int
+
+	in  []float64 // microphone samples which haven't been processed
+	out []float32 // processed samples which haven't been sent
+
+	partitions int
+	x          []float64      // the latest two blocks of reference samples
+	xSpectra   [][]complex128 // ring buffer of the reference spectra
+	xPos       int            // position of the latest spectrum in xSpectra
+	weights    [][]complex128 // frequency domain filter; one per partition
+	power      []float64      // smoothed power of the reference per bin
+	refPeak    []float64      // peak of the reference in each block (tail)
+	constrain  int            // partition which is constrained next
+	dtHold     int            // blocks until the adaption is resumed
+	buf        []complex128
+}
+
+// New is the constructor method for an AEC Object. AEC implements an
+// audio.Node. By default the echo canceller covers echoes which are
+// delayed up to 250ms with a step size of 0.5.
+func New(opts ...Option) *AEC {
+	a := &AEC{
+		tailLength: time.Millisecond * 250,
+		mu:         0.5,
+		buf:        make([]complex128, fftSize),
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if a.tailLength < time.Millisecond*10 {
+		a.tailLength = time.Millisecond * 10
+	}
+
+	return a
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// (the microphone audio) will start the processing.
+func (a *AEC) Write(msg audio.Msg) error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.cb == nil {
+		return nil
+	}
+
+	if !a.enabled || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		// forget the state; it would be outdated when the node is
+		// enabled again
+		a.samplerate = 0
+		go a.cb(msg)
+		return nil
+	}
+
+	// the echo can only be removed if the reference has the same
+	// samplerate
+	a.refMu.Lock()
+	refRate := a.refRate
+	a.refMu.Unlock()
+	if refRate != 0 && refRate != msg.Samplerate {
+		go a.cb(msg)
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if msg.Samplerate != a.samplerate {
+		a.reset(msg.Samplerate)
+	}
+
+	frames := len(msg.Data) / chs
+
+	for i := 0; i < frames; i++ {
+		var s float64
+		for ch := 0; ch < chs; ch++ {
+			s += float64(msg.Data[i*chs+ch])
+		}
+		a.in = append(a.in, s/float64(chs))
+	}
+
+	for len(a.in) >= blockSize {
+		a.process()
+		a.in = a.in[:copy(a.in, a.in[blockSize:])]
+	}
+
+	data := audio.NewBuffer(frames * chs)
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < chs; ch++ {
+			data[i*chs+ch] = a.out[i]
+		}
+	}
+	a.out = a.out[:copy(a.out, a.out[frames:])]
+
+	msg.Data = data
+
+	go a.cb(msg)
+
+	return nil
+}
+
+// WriteReference adds the audio which is played on the speaker to the
+// reference of the echo canceller. The reference is only buffered while
+// the echo canceller is enabled.
+func (a *AEC) WriteReference(msg audio.Msg) {
+	a.Lock()
+	enabled := a.enabled
+	a.Unlock()
+
+	a.refMu.Lock()
+	defer a.refMu.Unlock()
+
+	if !enabled || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		a.ref = a.ref[:0]
+		return
+	}
+
+	if msg.Samplerate != a.refRate {
+		a.refRate = msg.Samplerate
+		a.refMaxSize = int(maxReferenceDelay.Seconds() * msg.Samplerate)
+		a.ref = a.ref[:0]
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	for i := 0; i+chs <= len(msg.Data); i += chs {
+		var s float64
+		for ch := 0; ch < chs; ch++ {
+			s += float64(msg.Data[i+ch])
+		}
+		a.ref = append(a.ref, s/float64(chs))
+	}
+
+	if len(a.ref) > a.refMaxSize {
+		a.ref = a.ref[:copy(a.ref, a.ref[len(a.ref)-a.refMaxSize:])]
+	}
+}
+
+// reset initializes the filter for the samplerate. This method is not
+// safe for concurrent access.
+func (a *AEC) reset(samplerate float64) {
+	a.samplerate = samplerate
+	a.partitions = int(math.Ceil(a.tailLength.Seconds() * samplerate / blockSize))
+
+	a.x = make([]float64, fftSize)
+	a.xSpectra = make([][]complex128, a.partitions)
+	a.weights = make([][]complex128, a.partitions)
+	for p := 0; p < a.partitions; p++ {
+		a.xSpectra[p] = make([]complex128, fftSize)
+		a.weights[p] = make([]complex128, fftSize)
+	}
+	a.xPos = 0
+	a.power = make([]float64, fftSize)
+	a.refPeak = make([]float64, a.partitions)
+	a.constrain = 0
+	a.dtHold = 0
+
+	// prefill the output, so that there are always enough processed
+	// samples to be sent
+	a.in = make([]float64, 0, blockSize*2)
+	a.out = make([]float32, blockSize, blockSize*4)
+}
+
+// process removes the echo from the first blockSize samples of the input
+// and adds them to the output. This method is not safe for concurrent
+// access.
+func (a *AEC) process() {
+
+	// take the next block of the reference; missing samples (e.g. no
+	// rx audio) are silence
+	copy(a.x, a.x[blockSize:])
+	a.refMu.Lock()
+	n := copy(a.x[blockSize:], a.ref)
+	a.ref = a.ref[:copy(a.ref, a.ref[n:])]
+	a.refMu.Unlock()
+	for i := blockSize + n; i < fftSize; i++ {
+		a.x[i] = 0
+	}
+
+	a.xPos = (a.xPos + 1) % a.partitions
+	X := a.xSpectra[a.xPos]
+	for i, s := range a.x {
+		X[i] = complex(s, 0)
+	}
+	fft.FFT(X)
+
+	var peak float64
+	for _, s := range a.x[blockSize:] {
+		peak = math.Max(peak, math.Abs(s))
+	}
+	a.refPeak[a.xPos] = peak
+
+	// estimate the echo: sum of the partitions' filters applied to the
+	// correspondingly delayed reference spectra
+	for i := range a.buf {
+		a.buf[i] = 0
+	}
+	for p := 0; p < a.partitions; p++ {
+		Xp := a.xSpectra[(a.xPos-p+a.partitions)%a.partitions]
+		W := a.weights[p]
+		for k := range a.buf {
+			a.buf[k] += W[k] * Xp[k]
+		}
+	}
+	fft.IFFT(a.buf)
+
+	// error = microphone - estimated echo (overlap-save: only the
+	// second half of the output is valid)
+	e := make([]float64, blockSize)
+	var micPeak float64
+	for i := range e {
+		e[i] = a.in[i] - real(a.buf[blockSize+i])
+		micPeak = math.Max(micPeak, math.Abs(a.in[i]))
+		a.out = append(a.out, float32(math.Max(-1, math.Min(1, e[i]))))
+	}
+
+	// geigel double talk detector: the echo is always weaker than the
+	// reference; a louder microphone indicates near end speech
+	var tailPeak float64
+	for _, p := range a.refPeak {
+		tailPeak = math.Max(tailPeak, p)
+	}
+	if micPeak > 0.5*tailPeak {
+		a.dtHold = doubleTalkHold
+	}
+	if a.dtHold > 0 {
+		a.dtHold--
+		return
+	}
+	if tailPeak == 0 {
+		return
+	}
+
+	// adapt the filter (normalized per frequency bin)
+	for i := range a.buf {
+		a.buf[i] = 0
+	}
+	for i, s := range e {
+		a.buf[blockSize+i] = complex(s, 0)
+	}
+	fft.FFT(a.buf)
+
+	for k := range a.power {
+		p := real(X[k])*real(X[k]) + imag(X[k])*imag(X[k])
+		a.power[k] = 0.9*a.power[k] + 0.1*p
+	}
+
+	for p := 0; p < a.partitions; p++ {
+		Xp := a.xSpectra[(a.xPos-p+a.partitions)%a.partitions]
+		W := a.weights[p]
+		for k := range W {
+			step := a.mu / (float64(a.partitions)*a.power[k] + 1e-6)
+			W[k] += complex(step, 0) * cmplx.Conj(Xp[k]) * a.buf[k]
+		}
+	}
+
+	// constrain one partition per block to a linear convolution (the
+	// second half of its impulse response must be zero)
+	W := a.weights[a.constrain]
+	fft.IFFT(W)
+	for i := blockSize; i < fftSize; i++ {
+		W[i] = 0
+	}
+	fft.FFT(W)
+	a.constrain = (a.constrain + 1) % a.partitions
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (a *AEC) SetCb(cb audio.OnDataCb) {
+	a.Lock()
+	defer a.Unlock()
+	a.cb = cb
+}
+
+// Enable or disable the echo canceller. If the echo canceller is
+// disabled, the audio data will be passed on unmodified to the next
+// audio node in the chain.
+func (a *AEC) Enable(state bool) {
+	a.Lock()
+	defer a.Unlock()
+	a.enabled = state
+}
+
+// Enabled returns a boolean value indicating if the echo canceller
+// is enabled.
+func (a *AEC) Enabled() bool {
+	a.Lock()
+	defer a.Unlock()
+	return a.enabled
+}
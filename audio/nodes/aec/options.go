@@ -0,0 +1,31 @@
+package aec
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*AEC)
+
+// Enabled is a functional option to initialize the AEC object
+// with an enabled or disabled echo canceller.
+func Enabled(enabled bool) Option {
+	return func(a *AEC) {
+		a.enabled = enabled
+	}
+}
+
+// TailLength is a functional option to set the longest echo delay
+// (speaker -> microphone, including the buffers of the soundcard) which
+// can be removed. Longer tails require more processing power.
+func TailLength(t time.Duration) Option {
+	return func(a *AEC) {
+		a.tailLength = t
+	}
+}
+
+// Mu is a functional option to set the step size (0...1) of the adaptive
+// filter. Larger values converge faster, smaller values are more robust.
+func Mu(mu float64) Option {
+	return func(a *AEC) {
+		a.mu = mu
+	}
+}
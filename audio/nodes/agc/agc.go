@@ -0,0 +1,233 @@
+package agc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chewxy/math32"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// AGC is an Audio Node which automatically adjusts the gain of the audio
+// so that its peak level follows a target level. The peak level is tracked
+// by an envelope follower with separate attack and decay time constants.
+// After a peak, the gain is held for the hang time before it starts to
+// increase again.
+type AGC struct {
+	sync.Mutex
+	enabled  bool
+	cb       audio.OnDataCb
+	target   float32
+	attack   time.Duration
+	decay    time.Duration
+	hangTime time.Duration
+	maxGain  float32
+	level    float32 // current envelope of the peak level
+	gain     float32 // last applied gain
+	hang     int     // remaining samples of the hang time
+}
+
+// New is the constructor method for an AGC Object. AGC implements an
+// audio.Node. By default the target level is set to 0.5, the attack time
+// to 10ms, the decay time to 500ms, the hang time to 200ms and the
+// maximum gain to 10 (20dB).
+func New(opts ...Option) *AGC {
+	a := &AGC{
+		target:   0.5,
+		attack:   time.Millisecond * 10,
+		decay:    time.Millisecond * 500,
+		hangTime: time.Millisecond * 200,
+		maxGain:  10,
+		gain:     1,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (a *AGC) Write(msg audio.Msg) error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.cb == nil {
+		return nil
+	}
+
+	if !a.enabled || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		go a.cb(msg)
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	attackCoef := audio.OnePoleCoefficient(a.attack, msg.Samplerate)
+	decayCoef := audio.OnePoleCoefficient(a.decay, msg.Samplerate)
+	hangSamples := int(a.hangTime.Seconds() * msg.Samplerate)
+
+	data := audio.NewBuffer(len(msg.Data))
+
+	for i := 0; i+chs <= len(msg.Data); i += chs {
+
+		// the peak over all channels of a frame determines the gain
+		var peak float32
+		for _, s := range msg.Data[i : i+chs] {
+			if abs := math32.Abs(s); abs > peak {
+				peak = abs
+			}
+		}
+
+		if peak > a.level {
+			a.level += attackCoef * (peak - a.level)
+			a.hang = hangSamples
+		} else if a.hang > 0 {
+			a.hang--
+		} else {
+			a.level += decayCoef * (peak - a.level)
+		}
+
+		a.gain = a.maxGain
+		if a.level > 0 && a.target/a.level < a.gain {
+			a.gain = a.target / a.level
+		}
+
+		for j := i; j < i+chs; j++ {
+			data[j] = clip(msg.Data[j] * a.gain)
+		}
+	}
+
+	msg.Data = data
+
+	go a.cb(msg)
+
+	return nil
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (a *AGC) SetCb(cb audio.OnDataCb) {
+	a.Lock()
+	defer a.Unlock()
+	a.cb = cb
+}
+
+// Enable or disable the AGC. If the AGC is disabled, the audio data
+// will be passed on unmodified to the next audio node in the chain.
+func (a *AGC) Enable(state bool) {
+	a.Lock()
+	defer a.Unlock()
+	a.enabled = state
+}
+
+// Enabled returns a boolean value indicating if the AGC is enabled.
+func (a *AGC) Enabled() bool {
+	a.Lock()
+	defer a.Unlock()
+	return a.enabled
+}
+
+// SetTarget sets the target peak level. Only values between 0...1 are
+// allowed. Values below or above will be clipped to the minimum or maximum.
+func (a *AGC) SetTarget(level float32) {
+	a.Lock()
+	defer a.Unlock()
+	if level > 1.0 {
+		a.target = 1.0
+	} else if level < 0.0 {
+		a.target = 0.0
+	} else {
+		a.target = level
+	}
+}
+
+// Target returns the target peak level.
+func (a *AGC) Target() float32 {
+	a.Lock()
+	defer a.Unlock()
+	return a.target
+}
+
+// SetAttack sets the attack time constant.
+func (a *AGC) SetAttack(t time.Duration) {
+	a.Lock()
+	defer a.Unlock()
+	a.attack = t
+}
+
+// Attack returns the attack time constant.
+func (a *AGC) Attack() time.Duration {
+	a.Lock()
+	defer a.Unlock()
+	return a.attack
+}
+
+// SetDecay sets the decay time constant.
+func (a *AGC) SetDecay(t time.Duration) {
+	a.Lock()
+	defer a.Unlock()
+	a.decay = t
+}
+
+// Decay returns the decay time constant.
+func (a *AGC) Decay() time.Duration {
+	a.Lock()
+	defer a.Unlock()
+	return a.decay
+}
+
+// SetHangTime sets the time during which the gain is held after a peak.
+func (a *AGC) SetHangTime(t time.Duration) {
+	a.Lock()
+	defer a.Unlock()
+	a.hangTime = t
+}
+
+// HangTime returns the hang time.
+func (a *AGC) HangTime() time.Duration {
+	a.Lock()
+	defer a.Unlock()
+	return a.hangTime
+}
+
+// SetMaxGain sets the maximum (linear) gain. Values below 1 are set to 1.
+func (a *AGC) SetMaxGain(gain float32) {
+	a.Lock()
+	defer a.Unlock()
+	if gain < 1 {
+		gain = 1
+	}
+	a.maxGain = gain
+}
+
+// MaxGain returns the maximum (linear) gain.
+func (a *AGC) MaxGain() float32 {
+	a.Lock()
+	defer a.Unlock()
+	return a.maxGain
+}
+
+// Gain returns the (linear) gain which has been applied to the last
+// processed sample.
+func (a *AGC) Gain() float32 {
+	a.Lock()
+	defer a.Unlock()
+	return a.gain
+}
+
+// clip limits a sample to the range -1...1
+func clip(s float32) float32 {
+	if s > 1 {
+		return 1
+	} else if s < -1 {
+		return -1
+	}
+	return s
+}
@@ -0,0 +1,54 @@
+package agc
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*AGC)
+
+// Enabled is a functional option to initialize the AGC object
+// with an enabled or disabled gain control.
+func Enabled(enabled bool) Option {
+	return func(a *AGC) {
+		a.enabled = enabled
+	}
+}
+
+// Target is a functional option to set the peak level (0...1) to which
+// the audio is amplified or attenuated.
+func Target(level float32) Option {
+	return func(a *AGC) {
+		a.target = level
+	}
+}
+
+// Attack is a functional option to set the time constant with which the
+// gain is reduced when the audio level raises above the target level.
+func Attack(t time.Duration) Option {
+	return func(a *AGC) {
+		a.attack = t
+	}
+}
+
+// Decay is a functional option to set the time constant with which the
+// gain is increased when the audio level falls below the target level.
+func Decay(t time.Duration) Option {
+	return func(a *AGC) {
+		a.decay = t
+	}
+}
+
+// HangTime is a functional option to set the time during which the gain
+// is held after a peak, before it starts to decay.
+func HangTime(t time.Duration) Option {
+	return func(a *AGC) {
+		a.hangTime = t
+	}
+}
+
+// MaxGain is a functional option to set the maximum amplification
+// (linear, e.g. 10 = 20dB) applied to weak signals.
+func MaxGain(gain float32) Option {
+	return func(a *AGC) {
+		a.maxGain = gain
+	}
+}
@@ -0,0 +1,235 @@
+package analyser
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/fft"
+)
+
+// minLevel is the lowest level (dBFS) reported in a Spectrum
+const minLevel = -150
+
+// Spectrum contains the magnitudes of the audio in dBFS. The bins are
+// equally spaced from 0Hz up to half of the samplerate (size/2+1 bins).
+type Spectrum struct {
+	Samplerate float64   `json:"samplerate"`
+	Size       int       `json:"size"`
+	Bins       []float32 `json:"bins"`
+}
+
+// OnSpectrumCb is the callback which is executed when a new Spectrum
+// has been calculated.
+type OnSpectrumCb func(Spectrum)
+
+// Analyser is an Audio Node which calculates the magnitude spectrum of the
+// audio (e.g. for a spectrum or waterfall display). The audio is passed on
+// unmodified. Stereo audio is mixed down to mono before the analysis.
+// The size of the FFT determines the resolution (samplerate / size) and
+// the rate how many spectra per second are calculated.
+type Analyser struct {
+	sync.Mutex
+	cb         audio.OnDataCb
+	spectrumCb OnSpectrumCb
+	size       int
+	rate       float64
+	window     []float64
+	windowSum  float64
+	history    []float64 // ring buffer with the latest size samples
+	pos        int       // position of the next sample in history
+	pending    int       // samples received since the last spectrum
+	samplerate float64
+	buf        []complex128
+}
+
+// New is the constructor method for an Analyser Object. Analyser
+// implements an audio.Node. By default the FFT size is 2048 and 10
+// spectra per second are calculated.
+func New(opts ...Option) (*Analyser, error) {
+	a := &Analyser{
+		size: 2048,
+		rate: 10,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	if err := checkSize(a.size); err != nil {
+		return nil, err
+	}
+	if err := checkRate(a.rate); err != nil {
+		return nil, err
+	}
+
+	a.reset()
+
+	return a, nil
+}
+
+// checkSize returns an error if the size can not be used for the FFT
+func checkSize(size int) error {
+	if !fft.IsPowerOfTwo(size) || size < 256 || size > 16384 {
+		return fmt.Errorf("fft size must be a power of two within [256...16384]")
+	}
+	return nil
+}
+
+// checkRate returns an error if the rate is out of range
+func checkRate(rate float64) error {
+	if rate < 1 || rate > 50 {
+		return fmt.Errorf("rate must be within [1...50] spectra per second")
+	}
+	return nil
+}
+
+// reset allocates the buffers for the current size. This method is
+// not safe for concurrent access.
+func (a *Analyser) reset() {
+	a.window = fft.Hann(a.size)
+	a.windowSum = 0
+	for _, w := range a.window {
+		a.windowSum += w
+	}
+	a.history = make([]float64, a.size)
+	a.buf = make([]complex128, a.size)
+	a.pos = 0
+	a.pending = 0
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (a *Analyser) Write(msg audio.Msg) error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.cb == nil {
+		return nil
+	}
+
+	// forward the msg asap to the next node
+	go a.cb(msg)
+
+	if a.spectrumCb == nil || msg.Samplerate <= 0 {
+		return nil
+	}
+
+	if msg.Samplerate != a.samplerate {
+		a.samplerate = msg.Samplerate
+		a.reset()
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	interval := int(a.samplerate / a.rate)
+
+	for i := 0; i+chs <= len(msg.Data); i += chs {
+		var s float64
+		for ch := 0; ch < chs; ch++ {
+			s += float64(msg.Data[i+ch])
+		}
+		a.history[a.pos] = s / float64(chs)
+		a.pos = (a.pos + 1) % a.size
+		a.pending++
+
+		if a.pending >= interval {
+			a.pending = 0
+			go a.spectrumCb(a.spectrum())
+		}
+	}
+
+	return nil
+}
+
+// spectrum calculates the magnitude spectrum of the latest size samples.
+// This method is not safe for concurrent access.
+func (a *Analyser) spectrum() Spectrum {
+
+	// history is a ring buffer; pos points to the oldest sample
+	for i := range a.buf {
+		a.buf[i] = complex(a.history[(a.pos+i)%a.size]*a.window[i], 0)
+	}
+	fft.FFT(a.buf)
+
+	sp := Spectrum{
+		Samplerate: a.samplerate,
+		Size:       a.size,
+		Bins:       make([]float32, a.size/2+1),
+	}
+
+	// normalize the magnitudes, so that a full scale sine results in 0dBFS
+	scale := 2 / a.windowSum
+
+	for k := range sp.Bins {
+		db := float64(minLevel)
+		if mag := cmplx.Abs(a.buf[k]) * scale; mag > 0 {
+			db = math.Max(20*math.Log10(mag), minLevel)
+		}
+		// a resolution of 0.1dB is sufficient for displaying
+		sp.Bins[k] = float32(math.Round(db*10) / 10)
+	}
+
+	return sp
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (a *Analyser) SetCb(cb audio.OnDataCb) {
+	a.Lock()
+	defer a.Unlock()
+	a.cb = cb
+}
+
+// SetSpectrumCb sets the callback which will be called with each new
+// Spectrum. No spectra are calculated as long as no callback is set.
+func (a *Analyser) SetSpectrumCb(cb OnSpectrumCb) {
+	a.Lock()
+	defer a.Unlock()
+	a.spectrumCb = cb
+}
+
+// SetSize sets the size of the FFT. The size must be a power of two
+// within 256...16384.
+func (a *Analyser) SetSize(size int) error {
+	if err := checkSize(size); err != nil {
+		return err
+	}
+	a.Lock()
+	defer a.Unlock()
+	if size != a.size {
+		a.size = size
+		a.reset()
+	}
+	return nil
+}
+
+// Size returns the size of the FFT.
+func (a *Analyser) Size() int {
+	a.Lock()
+	defer a.Unlock()
+	return a.size
+}
+
+// SetRate sets the amount of spectra per second (1...50).
+func (a *Analyser) SetRate(rate float64) error {
+	if err := checkRate(rate); err != nil {
+		return err
+	}
+	a.Lock()
+	defer a.Unlock()
+	a.rate = rate
+	return nil
+}
+
+// Rate returns the amount of spectra calculated per second.
+func (a *Analyser) Rate() float64 {
+	a.Lock()
+	defer a.Unlock()
+	return a.rate
+}
@@ -0,0 +1,22 @@
+package analyser
+
+// Option is the type for a function option
+type Option func(*Analyser)
+
+// Size is a functional option to set the size of the FFT. The size must
+// be a power of two (256...16384). Larger sizes increase the frequency
+// resolution (samplerate / size), but also the time over which the audio
+// is averaged.
+func Size(size int) Option {
+	return func(a *Analyser) {
+		a.size = size
+	}
+}
+
+// Rate is a functional option to set the amount of spectra which are
+// calculated per second (1...50).
+func Rate(rate float64) Option {
+	return func(a *Analyser) {
+		a.rate = rate
+	}
+}
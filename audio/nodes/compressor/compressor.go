@@ -0,0 +1,249 @@
+package compressor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/chewxy/math32"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// Compressor is an Audio Node which reduces the dynamic range of the audio.
+// Audio above the threshold is compressed by the ratio and then amplified
+// by the makeup gain. A brickwall limiter at the end makes sure that the
+// output never exceeds the limit, so that the average level (e.g. the
+// talk power on SSB) can be raised without overdriving the radio.
+type Compressor struct {
+	sync.Mutex
+	enabled    bool
+	cb         audio.OnDataCb
+	threshold  float32 // dBFS
+	ratio      float32
+	makeupGain float32 // dB
+	limit      float32 // dBFS
+	attack     time.Duration
+	release    time.Duration
+	level      float32 // envelope of the input peak level
+	limGain    float32 // current gain of the limiter
+	reduction  float32 // gain reduction (dB) applied to the last sample
+}
+
+// New is the constructor method for a Compressor Object. Compressor
+// implements an audio.Node. By default the threshold is set to -20dBFS,
+// the ratio to 4:1, the makeup gain to 6dB, the limit to -1dBFS, the
+// attack time to 5ms and the release time to 100ms.
+func New(opts ...Option) *Compressor {
+	c := &Compressor{
+		threshold:  -20,
+		ratio:      4,
+		makeupGain: 6,
+		limit:      -1,
+		attack:     time.Millisecond * 5,
+		release:    time.Millisecond * 100,
+		limGain:    1,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (c *Compressor) Write(msg audio.Msg) error {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.cb == nil {
+		return nil
+	}
+
+	if !c.enabled || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		go c.cb(msg)
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	attackCoef := audio.OnePoleCoefficient(c.attack, msg.Samplerate)
+	releaseCoef := audio.OnePoleCoefficient(c.release, msg.Samplerate)
+	ceiling := dbToLinear(c.limit)
+	slope := 1 - 1/c.ratio
+
+	data := audio.NewBuffer(len(msg.Data))
+
+	for i := 0; i+chs <= len(msg.Data); i += chs {
+
+		var peak float32
+		for _, s := range msg.Data[i : i+chs] {
+			if abs := math32.Abs(s); abs > peak {
+				peak = abs
+			}
+		}
+
+		// compressor
+		if peak > c.level {
+			c.level += attackCoef * (peak - c.level)
+		} else {
+			c.level += releaseCoef * (peak - c.level)
+		}
+
+		var reduction float32
+		if level := linearToDb(c.level); level > c.threshold {
+			reduction = (level - c.threshold) * slope
+		}
+		gain := dbToLinear(c.makeupGain - reduction)
+
+		// brickwall limiter; the gain is reduced instantly if the
+		// output would exceed the ceiling
+		if out := peak * gain; out*c.limGain > ceiling {
+			c.limGain = ceiling / out
+		} else {
+			c.limGain += releaseCoef * (1 - c.limGain)
+		}
+		gain *= c.limGain
+
+		c.reduction = reduction - linearToDb(c.limGain)
+
+		for j := i; j < i+chs; j++ {
+			data[j] = clip(msg.Data[j]*gain, ceiling)
+		}
+	}
+
+	msg.Data = data
+
+	go c.cb(msg)
+
+	return nil
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (c *Compressor) SetCb(cb audio.OnDataCb) {
+	c.Lock()
+	defer c.Unlock()
+	c.cb = cb
+}
+
+// Enable or disable the compressor. If the compressor is disabled, the
+// audio data will be passed on unmodified to the next audio node in
+// the chain.
+func (c *Compressor) Enable(state bool) {
+	c.Lock()
+	defer c.Unlock()
+	c.enabled = state
+}
+
+// Enabled returns a boolean value indicating if the compressor is enabled.
+func (c *Compressor) Enabled() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.enabled
+}
+
+// SetThreshold sets the threshold (in dBFS). Only values between
+// -60...0 are allowed. Values below or above will be clipped to the
+// minimum or maximum.
+func (c *Compressor) SetThreshold(db float32) {
+	c.Lock()
+	defer c.Unlock()
+	c.threshold = limit(db, -60, 0)
+}
+
+// Threshold returns the threshold (in dBFS).
+func (c *Compressor) Threshold() float32 {
+	c.Lock()
+	defer c.Unlock()
+	return c.threshold
+}
+
+// SetRatio sets the compression ratio. Only values between 1...20 are
+// allowed. Values below or above will be clipped to the minimum or maximum.
+func (c *Compressor) SetRatio(ratio float32) {
+	c.Lock()
+	defer c.Unlock()
+	c.ratio = limit(ratio, 1, 20)
+}
+
+// Ratio returns the compression ratio.
+func (c *Compressor) Ratio() float32 {
+	c.Lock()
+	defer c.Unlock()
+	return c.ratio
+}
+
+// SetMakeupGain sets the makeup gain (in dB). Only values between
+// 0...30 are allowed. Values below or above will be clipped to the
+// minimum or maximum.
+func (c *Compressor) SetMakeupGain(db float32) {
+	c.Lock()
+	defer c.Unlock()
+	c.makeupGain = limit(db, 0, 30)
+}
+
+// MakeupGain returns the makeup gain (in dB).
+func (c *Compressor) MakeupGain() float32 {
+	c.Lock()
+	defer c.Unlock()
+	return c.makeupGain
+}
+
+// SetLimit sets the ceiling of the limiter (in dBFS). Only values
+// between -30...0 are allowed. Values below or above will be clipped to
+// the minimum or maximum.
+func (c *Compressor) SetLimit(db float32) {
+	c.Lock()
+	defer c.Unlock()
+	c.limit = limit(db, -30, 0)
+}
+
+// Limit returns the ceiling of the limiter (in dBFS).
+func (c *Compressor) Limit() float32 {
+	c.Lock()
+	defer c.Unlock()
+	return c.limit
+}
+
+// GainReduction returns the gain reduction (in dB) of the compressor
+// and the limiter applied to the last processed sample.
+func (c *Compressor) GainReduction() float32 {
+	c.Lock()
+	defer c.Unlock()
+	return c.reduction
+}
+
+func dbToLinear(db float32) float32 {
+	return math32.Pow(10, db/20)
+}
+
+func linearToDb(v float32) float32 {
+	if v < 1e-9 {
+		v = 1e-9
+	}
+	return 20 * math32.Log10(v)
+}
+
+// clip limits a sample to the range -ceiling...ceiling
+func clip(s, ceiling float32) float32 {
+	if s > ceiling {
+		return ceiling
+	} else if s < -ceiling {
+		return -ceiling
+	}
+	return s
+}
+
+func limit(v, min, max float32) float32 {
+	if v < min {
+		return min
+	} else if v > max {
+		return max
+	}
+	return v
+}
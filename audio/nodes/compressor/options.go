@@ -0,0 +1,63 @@
+package compressor
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*Compressor)
+
+// Enabled is a functional option to initialize the Compressor object
+// with an enabled or disabled compressor / limiter.
+func Enabled(enabled bool) Option {
+	return func(c *Compressor) {
+		c.enabled = enabled
+	}
+}
+
+// Threshold is a functional option to set the level (in dBFS) above
+// which the audio is compressed.
+func Threshold(db float32) Option {
+	return func(c *Compressor) {
+		c.threshold = db
+	}
+}
+
+// Ratio is a functional option to set the compression ratio (e.g. 4
+// for 4:1) applied to the audio above the threshold.
+func Ratio(ratio float32) Option {
+	return func(c *Compressor) {
+		c.ratio = ratio
+	}
+}
+
+// MakeupGain is a functional option to set the gain (in dB) applied
+// after the compression.
+func MakeupGain(db float32) Option {
+	return func(c *Compressor) {
+		c.makeupGain = db
+	}
+}
+
+// Limit is a functional option to set the ceiling (in dBFS) of the
+// brickwall limiter. The output never exceeds this level.
+func Limit(db float32) Option {
+	return func(c *Compressor) {
+		c.limit = db
+	}
+}
+
+// Attack is a functional option to set the time constant with which
+// the compressor reacts to a raising audio level.
+func Attack(t time.Duration) Option {
+	return func(c *Compressor) {
+		c.attack = t
+	}
+}
+
+// Release is a functional option to set the time constant with which
+// the compressor and the limiter recover after the audio level has
+// fallen.
+func Release(t time.Duration) Option {
+	return func(c *Compressor) {
+		c.release = t
+	}
+}
@@ -0,0 +1,105 @@
+package eq
+
+import "math"
+
+// Biquad holds the normalized coefficients of a second order IIR filter
+// (see Robert Bristow-Johnson's Audio EQ Cookbook). Besides the EQ it is
+// used by other nodes which need a single filter section (e.g. the manual
+// notch).
+type Biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+}
+
+// State holds the delay elements of a Biquad for a single channel
+// (transposed direct form II)
+type State struct {
+	z1, z2 float64
+}
+
+// NewBiquad calculates the coefficients of a filter section for
+// the given samplerate.
+func NewBiquad(s Section, samplerate float64) Biquad {
+
+	// frequencies above nyquist would make the filter unstable
+	freq := math.Min(s.Frequency, samplerate*0.49)
+
+	w0 := 2 * math.Pi * freq / samplerate
+	cosW0 := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * s.Q)
+	A := math.Pow(10, s.Gain/40)
+	sqA := math.Sqrt(A)
+
+	var b0, b1, b2, a0, a1, a2 float64
+
+	switch s.Type {
+	case LowPass:
+		b0 = (1 - cosW0) / 2
+		b1 = 1 - cosW0
+		b2 = (1 - cosW0) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cosW0
+		a2 = 1 - alpha
+	case HighPass:
+		b0 = (1 + cosW0) / 2
+		b1 = -(1 + cosW0)
+		b2 = (1 + cosW0) / 2
+		a0 = 1 + alpha
+		a1 = -2 * cosW0
+		a2 = 1 - alpha
+	case BandPass:
+		b0 = alpha
+		b1 = 0
+		b2 = -alpha
+		a0 = 1 + alpha
+		a1 = -2 * cosW0
+		a2 = 1 - alpha
+	case Peaking:
+		b0 = 1 + alpha*A
+		b1 = -2 * cosW0
+		b2 = 1 - alpha*A
+		a0 = 1 + alpha/A
+		a1 = -2 * cosW0
+		a2 = 1 - alpha/A
+	case LowShelf:
+		b0 = A * ((A + 1) - (A-1)*cosW0 + 2*sqA*alpha)
+		b1 = 2 * A * ((A - 1) - (A+1)*cosW0)
+		b2 = A * ((A + 1) - (A-1)*cosW0 - 2*sqA*alpha)
+		a0 = (A + 1) + (A-1)*cosW0 + 2*sqA*alpha
+		a1 = -2 * ((A - 1) + (A+1)*cosW0)
+		a2 = (A + 1) + (A-1)*cosW0 - 2*sqA*alpha
+	case Notch:
+		b0 = 1
+		b1 = -2 * cosW0
+		b2 = 1
+		a0 = 1 + alpha
+		a1 = -2 * cosW0
+		a2 = 1 - alpha
+	case HighShelf:
+		b0 = A * ((A + 1) + (A-1)*cosW0 + 2*sqA*alpha)
+		b1 = -2 * A * ((A - 1) + (A+1)*cosW0)
+		b2 = A * ((A + 1) + (A-1)*cosW0 - 2*sqA*alpha)
+		a0 = (A + 1) - (A-1)*cosW0 + 2*sqA*alpha
+		a1 = 2 * ((A - 1) - (A+1)*cosW0)
+		a2 = (A + 1) - (A-1)*cosW0 - 2*sqA*alpha
+	default:
+		// pass through
+		return Biquad{b0: 1}
+	}
+
+	return Biquad{
+		b0: b0 / a0,
+		b1: b1 / a0,
+		b2: b2 / a0,
+		a1: a1 / a0,
+		a2: a2 / a0,
+	}
+}
+
+// Process filters a single sample
+func (b *Biquad) Process(st *State, x float64) float64 {
+	y := b.b0*x + st.z1
+	st.z1 = b.b1*x - b.a1*y + st.z2
+	st.z2 = b.b2*x - b.a2*y
+	return y
+}
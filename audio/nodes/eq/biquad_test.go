@@ -0,0 +1,133 @@
+package eq
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+)
+
+// gain returns the magnitude response (in dB) of the biquad at the
+// given frequency.
+func (b Biquad) gain(freq, samplerate float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/samplerate)) // z^-1
+	num := complex(b.b0, 0) + complex(b.b1, 0)*z + complex(b.b2, 0)*z*z
+	den := 1 + complex(b.a1, 0)*z + complex(b.a2, 0)*z*z
+	return 20 * math.Log10(cmplx.Abs(num/den))
+}
+
+func TestBiquadCoefficients(t *testing.T) {
+
+	// reference values calculated with the formulas of the Audio EQ Cookbook
+	b := NewBiquad(Section{Type: LowPass, Frequency: 1000, Q: 1 / math.Sqrt2}, 48000)
+	expected := Biquad{
+		b0: 0.003916126660547383,
+		b1: 0.007832253321094766,
+		b2: 0.003916126660547383,
+		a1: -1.815341082704568,
+		a2: 0.8310055893467576,
+	}
+
+	coeffs := [][2]float64{
+		{b.b0, expected.b0}, {b.b1, expected.b1}, {b.b2, expected.b2},
+		{b.a1, expected.a1}, {b.a2, expected.a2},
+	}
+	for i, c := range coeffs {
+		if math.Abs(c[0]-c[1]) > 1e-12 {
+			t.Errorf("coefficient %d: got %v, expected %v", i, c[0], c[1])
+		}
+	}
+}
+
+func TestBiquadResponse(t *testing.T) {
+
+	const samplerate = 48000
+	const nyquist = samplerate / 2
+	const minusInf = -100.0 // everything below is considered as -inf dB
+
+	type point struct {
+		freq float64
+		gain float64 // dB
+	}
+
+	tests := []struct {
+		name    string
+		section Section
+		points  []point
+	}{
+		{
+			name:    "lowpass",
+			section: Section{Type: LowPass, Frequency: 1000, Q: 1 / math.Sqrt2},
+			points:  []point{{0, 0}, {1000, -3.01}, {nyquist, minusInf}},
+		},
+		{
+			name:    "highpass",
+			section: Section{Type: HighPass, Frequency: 300, Q: 1 / math.Sqrt2},
+			points:  []point{{0, minusInf}, {300, -3.01}, {nyquist, 0}},
+		},
+		{
+			name:    "bandpass",
+			section: Section{Type: BandPass, Frequency: 1500, Q: 2},
+			points:  []point{{0, minusInf}, {1500, 0}, {nyquist, minusInf}},
+		},
+		{
+			name:    "peaking",
+			section: Section{Type: Peaking, Frequency: 2000, Q: 1, Gain: 6},
+			points:  []point{{0, 0}, {2000, 6}, {nyquist, 0}},
+		},
+		{
+			name:    "peaking cut",
+			section: Section{Type: Peaking, Frequency: 2000, Q: 1, Gain: -12},
+			points:  []point{{0, 0}, {2000, -12}, {nyquist, 0}},
+		},
+		{
+			name:    "low shelf",
+			section: Section{Type: LowShelf, Frequency: 200, Q: 1 / math.Sqrt2, Gain: 6},
+			points:  []point{{0, 6}, {200, 3}, {nyquist, 0}},
+		},
+		{
+			name:    "high shelf",
+			section: Section{Type: HighShelf, Frequency: 5000, Q: 1 / math.Sqrt2, Gain: -6},
+			points:  []point{{0, 0}, {5000, -3}, {nyquist, -6}},
+		},
+		{
+			name:    "notch",
+			section: Section{Type: Notch, Frequency: 1000, Q: 10},
+			points:  []point{{0, 0}, {1000, minusInf}, {nyquist, 0}},
+		},
+		{
+			name:    "unknown type passes through",
+			section: Section{Type: "unknown", Frequency: 1000, Q: 1},
+			points:  []point{{0, 0}, {1000, 0}, {nyquist, 0}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := NewBiquad(tc.section, samplerate)
+			for _, p := range tc.points {
+				g := b.gain(p.freq, samplerate)
+				if p.gain == minusInf {
+					if g > minusInf {
+						t.Errorf("%vHz: got %.2fdB, expected -inf", p.freq, g)
+					}
+					continue
+				}
+				if math.Abs(g-p.gain) > 0.05 {
+					t.Errorf("%vHz: got %.2fdB, expected %.2fdB", p.freq, g, p.gain)
+				}
+			}
+		})
+	}
+}
+
+func TestBiquadStability(t *testing.T) {
+
+	// frequencies above nyquist are clipped; the poles must stay
+	// within the unit circle
+	for _, typ := range []Type{LowPass, HighPass, BandPass, Peaking, LowShelf, HighShelf, Notch} {
+		b := NewBiquad(Section{Type: typ, Frequency: 30000, Q: 0.7, Gain: 6}, 48000)
+		if math.Abs(b.a2) >= 1 || math.Abs(b.a1) >= 1+b.a2 {
+			t.Errorf("%s: unstable filter (a1=%v, a2=%v)", typ, b.a1, b.a2)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package eq
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// Type is the type of a filter section
+type Type string
+
+// Supported types of filter sections
+const (
+	LowPass   Type = "lowpass"
+	HighPass  Type = "highpass"
+	BandPass  Type = "bandpass"
+	Peaking   Type = "peaking"
+	LowShelf  Type = "lowshelf"
+	HighShelf Type = "highshelf"
+	Notch     Type = "notch"
+)
+
+// Section describes a single (biquad) filter section of the EQ. The gain
+// is only used by the peaking and shelving sections.
+type Section struct {
+	Type      Type    `json:"type" mapstructure:"type"`
+	Frequency float64 `json:"frequency" mapstructure:"frequency"` // cutoff / center frequency in Hz
+	Q         float64 `json:"q" mapstructure:"q"`                 // quality factor (e.g. 0.707)
+	Gain      float64 `json:"gain" mapstructure:"gain"`           // gain in dB
+}
+
+// Validate returns an error if the section can not be turned into a filter.
+func (s Section) Validate() error {
+	switch s.Type {
+	case LowPass, HighPass, BandPass, Peaking, LowShelf, HighShelf, Notch:
+	default:
+		return fmt.Errorf("unknown filter type '%s'", s.Type)
+	}
+	if s.Frequency <= 0 {
+		return fmt.Errorf("frequency of %s filter must be > 0", s.Type)
+	}
+	if s.Q <= 0 {
+		return fmt.Errorf("q of %s filter must be > 0", s.Type)
+	}
+	if s.Gain < -40 || s.Gain > 40 {
+		return fmt.Errorf("gain of %s filter must be within [-40...40] dB", s.Type)
+	}
+	return nil
+}
+
+// EQ is an Audio Node which filters the audio through a chain of biquad
+// sections (low-pass, high-pass, band-pass, peaking, shelving and notch). It can
+// be used as a bandpass filter (e.g. 300-2700Hz for SSB) or as a
+// multi-band equalizer. Mono and interleaved stereo audio is supported;
+// each channel is filtered independently.
+type EQ struct {
+	sync.Mutex
+	enabled    bool
+	cb         audio.OnDataCb
+	sections   []Section
+	filters    []Biquad
+	states     [][]State // [channel][section]
+	samplerate float64   // samplerate for which the filters are calculated
+}
+
+// New is the constructor method for an EQ Object. EQ implements an
+// audio.Node. Without sections, the audio is passed on unmodified.
+func New(opts ...Option) (*EQ, error) {
+	e := &EQ{}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	for _, s := range e.sections {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return e, nil
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (e *EQ) Write(msg audio.Msg) error {
+	e.Lock()
+	defer e.Unlock()
+
+	if e.cb == nil {
+		return nil
+	}
+
+	if !e.enabled || len(e.sections) == 0 || len(msg.Data) == 0 ||
+		msg.Samplerate <= 0 {
+		go e.cb(msg)
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if msg.Samplerate != e.samplerate || len(e.states) != chs {
+		e.setup(msg.Samplerate, chs)
+	}
+
+	data := audio.NewBuffer(len(msg.Data))
+
+	for i, s := range msg.Data {
+		ch := i % chs
+		x := float64(s)
+		for j := range e.filters {
+			x = e.filters[j].Process(&e.states[ch][j], x)
+		}
+		data[i] = float32(x)
+	}
+
+	msg.Data = data
+
+	go e.cb(msg)
+
+	return nil
+}
+
+// setup calculates the filters for the samplerate and resets the
+// filter states. This method is not safe for concurrent access.
+func (e *EQ) setup(samplerate float64, channels int) {
+	e.samplerate = samplerate
+	e.filters = make([]Biquad, 0, len(e.sections))
+	for _, s := range e.sections {
+		e.filters = append(e.filters, NewBiquad(s, samplerate))
+	}
+	e.states = make([][]State, channels)
+	for ch := range e.states {
+		e.states[ch] = make([]State, len(e.sections))
+	}
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (e *EQ) SetCb(cb audio.OnDataCb) {
+	e.Lock()
+	defer e.Unlock()
+	e.cb = cb
+}
+
+// Enable or disable the EQ. If the EQ is disabled, the audio data will
+// be passed on unmodified to the next audio node in the chain.
+func (e *EQ) Enable(state bool) {
+	e.Lock()
+	defer e.Unlock()
+	e.enabled = state
+}
+
+// Enabled returns a boolean value indicating if the EQ is enabled.
+func (e *EQ) Enabled() bool {
+	e.Lock()
+	defer e.Unlock()
+	return e.enabled
+}
+
+// SetSections replaces the filter sections of the EQ. The sections are
+// applied in the given order. An error is returned if one of the sections
+// is invalid; in this case the current sections are kept.
+func (e *EQ) SetSections(sections []Section) error {
+	for _, s := range sections {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	e.sections = append([]Section(nil), sections...)
+	e.samplerate = 0 // recalculate the filters with the next msg
+
+	return nil
+}
+
+// Sections returns a copy of the filter sections.
+func (e *EQ) Sections() []Section {
+	e.Lock()
+	defer e.Unlock()
+	return append([]Section{}, e.sections...)
+}
@@ -0,0 +1,20 @@
+package eq
+
+// Option is the type for a function option
+type Option func(*EQ)
+
+// Enabled is a functional option to initialize the EQ object
+// with an enabled or disabled filter.
+func Enabled(enabled bool) Option {
+	return func(e *EQ) {
+		e.enabled = enabled
+	}
+}
+
+// Sections is a functional option to set the filter sections. The
+// sections are applied in the given order.
+func Sections(sections ...Section) Option {
+	return func(e *EQ) {
+		e.sections = append(e.sections, sections...)
+	}
+}
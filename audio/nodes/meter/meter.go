@@ -0,0 +1,102 @@
+package meter
+
+import (
+	"sync"
+
+	"github.com/chewxy/math32"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// Level contains the peak and RMS (root mean square) levels (0...1) of
+// each channel.
+type Level struct {
+	Peak []float32 `json:"peak"`
+	RMS  []float32 `json:"rms"`
+}
+
+// Meter is an Audio Node which measures the audio levels. The audio is
+// passed on unmodified. The levels are accumulated until they are read
+// with Level, so that short peaks are not missed by a slow reader.
+type Meter struct {
+	sync.Mutex
+	cb    audio.OnDataCb
+	peak  []float32
+	sum   []float64 // sum of the squared samples of each channel
+	count int       // frames accumulated in sum
+}
+
+// New is the constructor method for a Meter Object. Meter implements
+// an audio.Node.
+func New() *Meter {
+	return &Meter{}
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (m *Meter) Write(msg audio.Msg) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.cb == nil {
+		return nil
+	}
+
+	// forward the msg asap to the next node
+	go m.cb(msg)
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if len(m.peak) != chs {
+		m.peak = make([]float32, chs)
+		m.sum = make([]float64, chs)
+		m.count = 0
+	}
+
+	for i, s := range msg.Data {
+		ch := i % chs
+		if abs := math32.Abs(s); abs > m.peak[ch] {
+			m.peak[ch] = abs
+		}
+		m.sum[ch] += float64(s * s)
+	}
+	m.count += len(msg.Data) / chs
+
+	return nil
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (m *Meter) SetCb(cb audio.OnDataCb) {
+	m.Lock()
+	defer m.Unlock()
+	m.cb = cb
+}
+
+// Level returns the levels of the audio which has been written since the
+// last call and resets the meter. If no audio has been written, the
+// levels are zero.
+func (m *Meter) Level() Level {
+	m.Lock()
+	defer m.Unlock()
+
+	l := Level{
+		Peak: make([]float32, len(m.peak)),
+		RMS:  make([]float32, len(m.peak)),
+	}
+
+	for ch := range m.peak {
+		l.Peak[ch] = m.peak[ch]
+		if m.count > 0 {
+			l.RMS[ch] = math32.Sqrt(float32(m.sum[ch] / float64(m.count)))
+		}
+		m.peak[ch] = 0
+		m.sum[ch] = 0
+	}
+	m.count = 0
+
+	return l
+}
@@ -0,0 +1,221 @@
+package notch
+
+import (
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+)
+
+const (
+	// lmsTaps is the number of coefficients of the adaptive filter
+	lmsTaps = 64
+	// lmsDelay (in samples) decorrelates the reference from the input, so
+	// that only periodic signals (tones) can be predicted, but not speech
+	lmsDelay = 64
+	// lmsMu is the (normalized) step size of the adaptive filter
+	lmsMu = 0.005
+	// lmsLeakage lets the coefficients slowly decay, so that the filter
+	// doesn't keep a notch for a tone which has disappeared
+	lmsLeakage = 1e-5
+)
+
+// Notch is an Audio Node which removes tones (e.g. carriers, heterodynes or
+// tuning tones) from the audio. The automatic notch is an adaptive line
+// enhancer: a normalized LMS filter predicts the input from its delayed
+// past. Only steady tones are predictable, so subtracting the prediction
+// removes them. The manual notch is a biquad notch filter at a settable
+// frequency. Both can be enabled at the same time.
+type Notch struct {
+	sync.Mutex
+	cb         audio.OnDataCb
+	auto       bool
+	manual     bool
+	frequency  float64
+	q          float64
+	samplerate float64
+	filter     eq.Biquad
+	channels   []*channel
+}
+
+// channel holds the filter states of a single channel
+type channel struct {
+	history []float64 // ring buffer with the past input samples
+	pos     int       // position of the latest sample in history
+	weights []float64
+	notch   eq.State // state of the manual notch filter
+}
+
+// New is the constructor method for a Notch Object. Notch implements an
+// audio.Node. By default both notch filters are disabled and the manual
+// notch is set to 1000Hz with a Q of 10.
+func New(opts ...Option) *Notch {
+	n := &Notch{
+		frequency: 1000,
+		q:         10,
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	if n.q <= 0 {
+		n.q = 10
+	}
+
+	return n
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (n *Notch) Write(msg audio.Msg) error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.cb == nil {
+		return nil
+	}
+
+	if (!n.auto && !n.manual) || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		go n.cb(msg)
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if msg.Samplerate != n.samplerate || len(n.channels) != chs {
+		n.reset(msg.Samplerate, chs)
+	}
+
+	data := audio.NewBuffer(len(msg.Data))
+
+	for i, s := range msg.Data {
+		ch := n.channels[i%chs]
+		x := float64(s)
+		if n.auto {
+			x = ch.lms(x)
+		}
+		if n.manual {
+			x = n.filter.Process(&ch.notch, x)
+		}
+		data[i] = float32(x)
+	}
+
+	msg.Data = data
+
+	go n.cb(msg)
+
+	return nil
+}
+
+// reset calculates the manual notch filter for the samplerate and
+// clears the filter states. This method is not safe for concurrent access.
+func (n *Notch) reset(samplerate float64, channels int) {
+	n.samplerate = samplerate
+	n.filter = newNotchFilter(n.frequency, n.q, samplerate)
+	n.channels = make([]*channel, channels)
+	for i := range n.channels {
+		n.channels[i] = &channel{
+			history: make([]float64, lmsDelay+lmsTaps),
+			weights: make([]float64, lmsTaps),
+		}
+	}
+}
+
+// lms removes the predictable (periodic) components from the sample
+func (ch *channel) lms(x float64) float64 {
+
+	size := len(ch.history)
+
+	// predict the sample from the delayed history
+	var y, power float64
+	for j := range ch.weights {
+		ref := ch.history[(ch.pos-lmsDelay-j+1+2*size)%size]
+		y += ch.weights[j] * ref
+		power += ref * ref
+	}
+
+	e := x - y
+
+	// update the coefficients (normalized LMS with leakage)
+	step := lmsMu * e / (power + 1e-6)
+	for j := range ch.weights {
+		ref := ch.history[(ch.pos-lmsDelay-j+1+2*size)%size]
+		ch.weights[j] = ch.weights[j]*(1-lmsLeakage) + step*ref
+	}
+
+	ch.pos = (ch.pos + 1) % size
+	ch.history[ch.pos] = x
+
+	return e
+}
+
+// newNotchFilter returns the biquad notch filter at the frequency
+func newNotchFilter(freq, q, samplerate float64) eq.Biquad {
+	return eq.NewBiquad(eq.Section{Type: eq.Notch, Frequency: freq, Q: q}, samplerate)
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (n *Notch) SetCb(cb audio.OnDataCb) {
+	n.Lock()
+	defer n.Unlock()
+	n.cb = cb
+}
+
+// SetAuto enables or disables the automatic notch filter.
+func (n *Notch) SetAuto(enabled bool) {
+	n.Lock()
+	defer n.Unlock()
+	n.auto = enabled
+}
+
+// Auto returns a boolean value indicating if the automatic notch
+// filter is enabled.
+func (n *Notch) Auto() bool {
+	n.Lock()
+	defer n.Unlock()
+	return n.auto
+}
+
+// SetManual enables or disables the manual notch filter.
+func (n *Notch) SetManual(enabled bool) {
+	n.Lock()
+	defer n.Unlock()
+	n.manual = enabled
+}
+
+// Manual returns a boolean value indicating if the manual notch
+// filter is enabled.
+func (n *Notch) Manual() bool {
+	n.Lock()
+	defer n.Unlock()
+	return n.manual
+}
+
+// SetFrequency sets the frequency (Hz) of the manual notch filter. Only
+// values between 50...10000Hz are allowed. Values below or above will be
+// clipped to the minimum or maximum.
+func (n *Notch) SetFrequency(f float64) {
+	n.Lock()
+	defer n.Unlock()
+	if f < 50 {
+		f = 50
+	} else if f > 10000 {
+		f = 10000
+	}
+	n.frequency = f
+	if n.samplerate > 0 {
+		n.filter = newNotchFilter(n.frequency, n.q, n.samplerate)
+	}
+}
+
+// Frequency returns the frequency (Hz) of the manual notch filter.
+func (n *Notch) Frequency() float64 {
+	n.Lock()
+	defer n.Unlock()
+	return n.frequency
+}
@@ -0,0 +1,36 @@
+package notch
+
+// Option is the type for a function option
+type Option func(*Notch)
+
+// Auto is a functional option to enable or disable the automatic notch
+// filter which removes steady tones (e.g. carriers).
+func Auto(enabled bool) Option {
+	return func(n *Notch) {
+		n.auto = enabled
+	}
+}
+
+// Manual is a functional option to enable or disable the manual notch
+// filter at the set frequency.
+func Manual(enabled bool) Option {
+	return func(n *Notch) {
+		n.manual = enabled
+	}
+}
+
+// Frequency is a functional option to set the frequency (Hz) of the
+// manual notch filter.
+func Frequency(f float64) Option {
+	return func(n *Notch) {
+		n.frequency = f
+	}
+}
+
+// Q is a functional option to set the quality factor of the manual
+// notch filter. Higher values result in a narrower notch.
+func Q(q float64) Option {
+	return func(n *Notch) {
+		n.q = q
+	}
+}
@@ -0,0 +1,247 @@
+package nr
+
+import (
+	"math"
+	"math/cmplx"
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/fft"
+)
+
+// fftSize is the length of the analysis frames (~10ms @ 48kHz)
+const fftSize = 512
+
+// hopSize is the distance between two analysis frames (50% overlap)
+const hopSize = fftSize / 2
+
+// NR is an Audio Node which reduces stationary noise (e.g. the band noise
+// on HF) through spectral subtraction. The audio is transformed with a
+// short time fourier transformation into the frequency domain where the
+// noise floor of each frequency bin is tracked (minimum statistics).
+// Each bin is then attenuated by a wiener like gain according to its
+// signal to noise ratio. The node delays the audio by 512 samples.
+type NR struct {
+	sync.Mutex
+	enabled    bool
+	cb         audio.OnDataCb
+	strength   float32
+	window     []float64
+	channels   []*channel
+	samplerate float64
+	buf        []complex128
+}
+
+// channel holds the state of the noise reduction for a single channel
+type channel struct {
+	in     []float64 // input samples which haven't been processed
+	ola    []float64 // overlap-add buffer of the synthesized frames
+	out    []float32 // processed samples which haven't been sent
+	noise  []float64 // estimated noise power of each bin
+	power  []float64 // smoothed power of each bin
+	gain   []float64 // gain applied to each bin in the last frame
+	primed bool      // noise estimate has been initialized
+}
+
+// New is the constructor method for a NR Object. NR implements an
+// audio.Node. By default the strength is set to 0.5.
+func New(opts ...Option) *NR {
+	n := &NR{
+		strength: 0.5,
+		window:   fft.SqrtHann(fftSize),
+		buf:      make([]complex128, fftSize),
+	}
+
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (n *NR) Write(msg audio.Msg) error {
+	n.Lock()
+	defer n.Unlock()
+
+	if n.cb == nil {
+		return nil
+	}
+
+	chs := msg.Channels
+	if chs < 1 {
+		chs = 1
+	}
+
+	if !n.enabled || len(msg.Data) == 0 || msg.Samplerate <= 0 {
+		// forget the state; it would be outdated when the node is
+		// enabled again
+		n.channels = nil
+		go n.cb(msg)
+		return nil
+	}
+
+	if msg.Samplerate != n.samplerate || len(n.channels) != chs {
+		n.reset(msg.Samplerate, chs)
+	}
+
+	frames := len(msg.Data) / chs
+
+	for c, ch := range n.channels {
+		for i := 0; i < frames; i++ {
+			ch.in = append(ch.in, float64(msg.Data[i*chs+c]))
+		}
+		for len(ch.in) >= fftSize {
+			n.process(ch)
+			ch.in = ch.in[:copy(ch.in, ch.in[hopSize:])]
+		}
+	}
+
+	data := audio.NewBuffer(frames * chs)
+	for c, ch := range n.channels {
+		for i := 0; i < frames; i++ {
+			data[i*chs+c] = ch.out[i]
+		}
+		ch.out = ch.out[:copy(ch.out, ch.out[frames:])]
+	}
+
+	msg.Data = data
+
+	go n.cb(msg)
+
+	return nil
+}
+
+// reset initializes the state of all channels. This method is not safe
+// for concurrent access.
+func (n *NR) reset(samplerate float64, channels int) {
+	n.samplerate = samplerate
+	n.channels = make([]*channel, channels)
+	for i := range n.channels {
+		n.channels[i] = &channel{
+			// prefill the buffers so that a frame is processed every
+			// hopSize samples and that there are always enough
+			// processed samples to be sent
+			in:    make([]float64, fftSize-hopSize, fftSize*2),
+			out:   make([]float32, hopSize, fftSize*2),
+			ola:   make([]float64, fftSize),
+			noise: make([]float64, fftSize/2+1),
+			power: make([]float64, fftSize/2+1),
+			gain:  make([]float64, fftSize/2+1),
+		}
+	}
+}
+
+// process applies the noise reduction on the first fftSize samples of the
+// channel's input and adds hopSize processed samples to its output. This
+// method is not safe for concurrent access.
+func (n *NR) process(ch *channel) {
+
+	for i := range n.buf {
+		n.buf[i] = complex(ch.in[i]*n.window[i], 0)
+	}
+	fft.FFT(n.buf)
+
+	strength := float64(n.strength)
+	overSubtraction := 1 + 3*strength
+	floor := 1 - 0.9*strength // -20dB @ strength = 1
+
+	// the noise estimate may rise by 3dB per second; this allows the
+	// estimate to follow an increasing noise floor while speech (being
+	// not stationary) doesn't affect it too much
+	rise := math.Pow(10, 0.3*hopSize/n.samplerate)
+
+	for k := 0; k <= fftSize/2; k++ {
+		p := real(n.buf[k])*real(n.buf[k]) + imag(n.buf[k])*imag(n.buf[k])
+
+		if !ch.primed {
+			ch.power[k] = p
+			ch.noise[k] = p
+			ch.gain[k] = 1
+		}
+
+		ch.power[k] = 0.7*ch.power[k] + 0.3*p
+		if ch.power[k] < ch.noise[k] {
+			ch.noise[k] = ch.power[k]
+		} else {
+			ch.noise[k] *= rise
+		}
+
+		g := floor
+		if p > 0 {
+			g = math.Max(1-overSubtraction*ch.noise[k]/p, floor)
+		}
+		// smoothing the gain over time reduces the musical noise
+		g = 0.5*ch.gain[k] + 0.5*g
+		ch.gain[k] = g
+
+		n.buf[k] *= complex(g, 0)
+		if k > 0 && k < fftSize/2 {
+			n.buf[fftSize-k] = cmplx.Conj(n.buf[k])
+		}
+	}
+	ch.primed = true
+
+	fft.IFFT(n.buf)
+
+	for i := range ch.ola {
+		ch.ola[i] += real(n.buf[i]) * n.window[i]
+	}
+
+	for _, s := range ch.ola[:hopSize] {
+		ch.out = append(ch.out, float32(s))
+	}
+
+	copy(ch.ola, ch.ola[hopSize:])
+	for i := fftSize - hopSize; i < fftSize; i++ {
+		ch.ola[i] = 0
+	}
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (n *NR) SetCb(cb audio.OnDataCb) {
+	n.Lock()
+	defer n.Unlock()
+	n.cb = cb
+}
+
+// Enable or disable the noise reduction. If the noise reduction is
+// disabled, the audio data will be passed on unmodified to the next
+// audio node in the chain.
+func (n *NR) Enable(state bool) {
+	n.Lock()
+	defer n.Unlock()
+	n.enabled = state
+}
+
+// Enabled returns a boolean value indicating if the noise reduction
+// is enabled.
+func (n *NR) Enabled() bool {
+	n.Lock()
+	defer n.Unlock()
+	return n.enabled
+}
+
+// SetStrength sets the strength of the noise reduction. Only values
+// between 0...1 are allowed. Values below or above will be clipped
+// to the minimum or maximum.
+func (n *NR) SetStrength(s float32) {
+	n.Lock()
+	defer n.Unlock()
+	if s > 1.0 {
+		n.strength = 1.0
+	} else if s < 0.0 {
+		n.strength = 0.0
+	} else {
+		n.strength = s
+	}
+}
+
+// Strength returns the strength of the noise reduction.
+func (n *NR) Strength() float32 {
+	n.Lock()
+	defer n.Unlock()
+	return n.strength
+}
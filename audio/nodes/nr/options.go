@@ -0,0 +1,21 @@
+package nr
+
+// Option is the type for a function option
+type Option func(*NR)
+
+// Enabled is a functional option to initialize the NR object
+// with an enabled or disabled noise reduction.
+func Enabled(enabled bool) Option {
+	return func(n *NR) {
+		n.enabled = enabled
+	}
+}
+
+// Strength is a functional option to set the strength (0...1) of the
+// noise reduction. Higher values remove more noise, but also add
+// more artifacts.
+func Strength(s float32) Option {
+	return func(n *NR) {
+		n.strength = s
+	}
+}
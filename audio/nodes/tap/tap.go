@@ -0,0 +1,56 @@
+package tap
+
+import (
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// Tap is an Audio Node which passes the audio on unmodified and also
+// provides it through a reference callback. It is placed at the end of
+// the rx chain to provide the speaker audio as a reference (e.g. for the
+// echo canceller or the anti-vox).
+type Tap struct {
+	sync.Mutex
+	cb    audio.OnDataCb
+	refCb audio.OnDataCb
+}
+
+// New is the constructor method for a Tap Object. Tap implements an
+// audio.Node.
+func New() *Tap {
+	return &Tap{}
+}
+
+// Write is the entry point into this audio Node. Writing an audio.Msg
+// will start the processing.
+func (t *Tap) Write(msg audio.Msg) error {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.refCb != nil {
+		t.refCb(msg)
+	}
+
+	if t.cb != nil {
+		go t.cb(msg)
+	}
+
+	return nil
+}
+
+// SetCb sets the callback which will be called when the data has been
+// processed and is ready to be sent to the next audio.Node or audio.Sink.
+func (t *Tap) SetCb(cb audio.OnDataCb) {
+	t.Lock()
+	defer t.Unlock()
+	t.cb = cb
+}
+
+// SetRefCb sets the callback which will be called with each audio.Msg
+// (e.g. AEC.WriteReference). The callback must not modify the msg.
+func (t *Tap) SetRefCb(cb audio.OnDataCb) {
+	t.Lock()
+	defer t.Unlock()
+	t.refCb = cb
+}
@@ -36,3 +36,19 @@ func HoldTime(t time.Duration) Option {
 		v.holdTime = t
 	}
 }
+
+// AntiVoxGain is a function option to set the gain (0...10) of the
+// anti-vox. The level of the rx audio multiplied by the gain raises
+// the threshold. A gain of 0 disables the anti-vox. Values below or
+// above will be clipped to the minimum or maximum.
+func AntiVoxGain(gain float32) Option {
+	return func(v *Vox) {
+		if gain > 10.0 {
+			v.antiVoxGain = 10.0
+		} else if gain < 0.0 {
+			v.antiVoxGain = 0.0
+		} else {
+			v.antiVoxGain = gain
+		}
+	}
+}
@@ -11,8 +11,17 @@ import (
 	"github.com/dh1tw/remoteAudio/audio"
 )
 
+// antiVoxDecay is the time constant with which the anti-vox level
+// decays after the rx audio has become quiet. It bridges the delay until
+// the speaker audio arrives at the microphone.
+const antiVoxDecay = time.Millisecond * 300
+
 // Vox is an Audio Node which detects if the audio level raises above or falls
-// below a defined threshold level.
+// below a defined threshold level. With the anti-vox, the level of the rx
+// audio (see WriteAntiVox) multiplied by the anti-vox gain raises the
+// threshold, so that the speaker audio doesn't trigger the vox. While the
+// sidechain is enabled, the level of the audio provided through
+// WriteSidechain is evaluated instead of the audio passing through.
 type Vox struct {
 	sync.Mutex
 	enabled        bool
@@ -22,13 +31,18 @@ type Vox struct {
 	onStateChange  func(voxOn bool)
 	threshold      float32
 	holdTime       time.Duration
+	antiVoxGain    float32
+	antiVoxLevel   float32   // RMS of the rx audio
+	antiVoxUpdate  time.Time // time when antiVoxLevel has been set
+	sidechain      bool
 	chWarning      sync.Once
 }
 
 // New is the constructor method for a Vox Object. Vox implements
 // an audio.Node and emits a StateChanged callback when the RMS
 // (root mean square) has fallen above or below the set threshold. By
-// default the threshold is set to 0.1 and the hold time to 500ms.
+// default the threshold is set to 0.1, the hold time to 500ms and the
+// anti-vox is disabled (gain = 0).
 func New(opts ...Option) *Vox {
 	v := &Vox{
 		cb:             nil,
@@ -57,10 +71,40 @@ func (v *Vox) Write(msg audio.Msg) error {
 	// forward the msg asap to the next node
 	go v.cb(msg)
 
-	if !v.enabled {
+	if !v.enabled || v.sidechain {
 		return nil
 	}
 
+	return v.detect(msg)
+}
+
+// WriteSidechain provides audio which is evaluated instead of the audio
+// passing through the node (e.g. the microphone while the voice keyer is
+// transmitting). The audio is ignored unless the sidechain is enabled.
+func (v *Vox) WriteSidechain(msg audio.Msg) {
+	v.Lock()
+	defer v.Unlock()
+
+	if !v.enabled || !v.sidechain {
+		return
+	}
+
+	if err := v.detect(msg); err != nil {
+		log.Println(err)
+	}
+}
+
+// SetSidechain enables or disables the sidechain (see WriteSidechain).
+func (v *Vox) SetSidechain(enabled bool) {
+	v.Lock()
+	defer v.Unlock()
+	v.sidechain = enabled
+}
+
+// detect compares the level of the audio with the threshold and emits
+// the state changes. This method is not safe for concurrent access.
+func (v *Vox) detect(msg audio.Msg) error {
+
 	if msg.Channels > 1 {
 		v.multiChannelWarning()
 	}
@@ -75,7 +119,7 @@ func (v *Vox) Write(msg audio.Msg) error {
 		return err
 	}
 
-	if rmsValue >= v.threshold {
+	if rmsValue >= v.threshold+v.antiVoxGain*v.antiVox() {
 		v.lastActivation = time.Now()
 		if !v.active {
 			v.active = true
@@ -93,6 +137,39 @@ func (v *Vox) Write(msg audio.Msg) error {
 	return nil
 }
 
+// WriteAntiVox provides the rx audio (typically the audio played on the
+// speaker) to the anti-vox.
+func (v *Vox) WriteAntiVox(msg audio.Msg) {
+	if len(msg.Data) == 0 {
+		return
+	}
+
+	rmsValue, err := rms(msg.Data)
+	if err != nil {
+		return
+	}
+
+	v.Lock()
+	defer v.Unlock()
+
+	// the level rises immediately, but decays slowly
+	if level := v.antiVox(); rmsValue < level {
+		rmsValue = level
+	}
+	v.antiVoxLevel = rmsValue
+	v.antiVoxUpdate = time.Now()
+}
+
+// antiVox returns the decayed anti-vox level. This method is not safe
+// for concurrent access.
+func (v *Vox) antiVox() float32 {
+	if v.antiVoxLevel == 0 {
+		return 0
+	}
+	elapsed := time.Since(v.antiVoxUpdate)
+	return v.antiVoxLevel * math32.Exp(-float32(elapsed)/float32(antiVoxDecay))
+}
+
 // SetCb sets the callback which will be called when the data has been
 // processed and is ready to be sent to the next audio.Node or audio.Sink.
 func (v *Vox) SetCb(cb audio.OnDataCb) {
@@ -155,6 +232,29 @@ func (v *Vox) Holdtime() time.Duration {
 	return v.holdTime
 }
 
+// SetAntiVoxGain sets the gain of the anti-vox. The rx level multiplied
+// by the gain is added to the threshold. Only values between 0...10 are
+// allowed. Values below or above will be clipped to the minimum or
+// maximum. A gain of 0 disables the anti-vox.
+func (v *Vox) SetAntiVoxGain(gain float32) {
+	v.Lock()
+	defer v.Unlock()
+	if gain > 10.0 {
+		v.antiVoxGain = 10.0
+	} else if gain < 0.0 {
+		v.antiVoxGain = 0.0
+	} else {
+		v.antiVoxGain = gain
+	}
+}
+
+// AntiVoxGain returns the gain of the anti-vox.
+func (v *Vox) AntiVoxGain() float32 {
+	v.Lock()
+	defer v.Unlock()
+	return v.antiVoxGain
+}
+
 // calculate the root mean square for a non-interlaced audio
 // frame
 func rms(data []float32) (float32, error) {
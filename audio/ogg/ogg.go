@@ -0,0 +1,367 @@
+// Package ogg implements the reading and writing of Ogg containers
+// (RFC 3533) with a single logical bitstream, as used for Ogg Opus
+// files (RFC 7845).
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// capturePattern marks the beginning of each page
+var capturePattern = []byte("OggS")
+
+// headerSize is the size of the page header without the segment table
+const headerSize = 27
+
+// maxSegments is the maximum amount of segments (lacing values) of a page
+const maxSegments = 255
+
+// pageDataSize is the amount of data after which a page is written out
+const pageDataSize = 4096
+
+// header types
+const (
+	continued = 0x01
+	bos       = 0x02 // beginning of stream
+	eos       = 0x04 // end of stream
+)
+
+// crcTable is the lookup table for the CRC32 of the pages (polynomial
+// 0x04c11db7, not reflected)
+var crcTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func crc(data []byte) uint32 {
+	var c uint32
+	for _, b := range data {
+		c = c<<8 ^ crcTable[byte(c>>24)^b]
+	}
+	return c
+}
+
+// Writer writes packets into pages of a logical Ogg bitstream. The
+// packets are collected until a page is full; Flush writes the pending
+// packets immediately.
+type Writer struct {
+	w           io.Writer
+	serial      uint32
+	seq         uint32
+	granule     int64 // granule position of the last complete packet
+	pageGranule int64 // granule position of the pending page; -1 if no packet ends on it
+	cont        bool  // the pending page continues a packet of the previous page
+	segments    []byte
+	data        []byte
+}
+
+// NewWriter returns a Writer for the logical bitstream with the serial
+// number.
+func NewWriter(w io.Writer, serial uint32) *Writer {
+	return &Writer{
+		w:           w,
+		serial:      serial,
+		pageGranule: -1,
+	}
+}
+
+// WritePacket adds a packet which ends at the granule position to the
+// stream.
+func (ow *Writer) WritePacket(packet []byte, granule int64) error {
+	for {
+		// the lacing values of a packet: 255 for each full segment,
+		// terminated by a value < 255
+		for len(packet) >= 255 && len(ow.segments) < maxSegments {
+			ow.segments = append(ow.segments, 255)
+			ow.data = append(ow.data, packet[:255]...)
+			packet = packet[255:]
+		}
+		if len(ow.segments) < maxSegments {
+			ow.segments = append(ow.segments, byte(len(packet)))
+			ow.data = append(ow.data, packet...)
+			ow.granule = granule
+			ow.pageGranule = granule
+			break
+		}
+		// the packet continues on the next page
+		if err := ow.writePage(false); err != nil {
+			return err
+		}
+		ow.cont = true
+	}
+
+	if len(ow.data) >= pageDataSize || len(ow.segments) == maxSegments {
+		return ow.Flush()
+	}
+	return nil
+}
+
+// WriteHeader writes a packet (e.g. a codec header) on a page of its own.
+func (ow *Writer) WriteHeader(packet []byte) error {
+	if err := ow.Flush(); err != nil {
+		return err
+	}
+	if err := ow.WritePacket(packet, 0); err != nil {
+		return err
+	}
+	return ow.Flush()
+}
+
+// Flush writes the pending packets onto a page.
+func (ow *Writer) Flush() error {
+	if len(ow.segments) == 0 {
+		return nil
+	}
+	return ow.writePage(false)
+}
+
+// Close writes the pending packets and marks the end of the stream. The
+// underlying io.Writer is not closed.
+func (ow *Writer) Close() error {
+	if len(ow.segments) == 0 {
+		ow.pageGranule = ow.granule
+	}
+	return ow.writePage(true)
+}
+
+// writePage writes the pending segments as a page.
+func (ow *Writer) writePage(last bool) error {
+
+	var flags byte
+	if ow.seq == 0 {
+		flags |= bos
+	}
+	if last {
+		flags |= eos
+	}
+	if ow.cont {
+		flags |= continued
+	}
+
+	page := make([]byte, headerSize, headerSize+len(ow.segments)+len(ow.data))
+	copy(page, capturePattern)
+	page[4] = 0 // version
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(ow.pageGranule))
+	binary.LittleEndian.PutUint32(page[14:], ow.serial)
+	binary.LittleEndian.PutUint32(page[18:], ow.seq)
+	page[26] = byte(len(ow.segments))
+	page = append(page, ow.segments...)
+	page = append(page, ow.data...)
+	binary.LittleEndian.PutUint32(page[22:], crc(page))
+
+	if _, err := ow.w.Write(page); err != nil {
+		return err
+	}
+
+	ow.seq++
+	ow.cont = false
+	ow.pageGranule = -1
+	ow.segments = ow.segments[:0]
+	ow.data = ow.data[:0]
+
+	return nil
+}
+
+// Reader reads the packets of a logical Ogg bitstream. Pages of other
+// logical bitstreams (multiplexed streams) are skipped.
+type Reader struct {
+	r       io.Reader
+	serial  uint32
+	started bool
+	header  [headerSize]byte
+	packets [][]byte // complete packets of the current page
+	granule int64    // granule position of the current page
+	partial []byte   // packet which continues on the next page
+	eos     bool
+}
+
+// ErrInvalidPage is returned if the data is not a valid Ogg page.
+var ErrInvalidPage = errors.New("invalid ogg page")
+
+// NewReader returns a Reader for the first logical bitstream in r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+// ReadPacket returns the next packet of the stream and the granule
+// position of the page on which the packet ends. At the end of the stream
+// io.EOF is returned.
+func (or *Reader) ReadPacket() ([]byte, int64, error) {
+	for len(or.packets) == 0 {
+		if or.eos {
+			return nil, 0, io.EOF
+		}
+		if err := or.readPage(); err != nil {
+			return nil, 0, err
+		}
+	}
+	p := or.packets[0]
+	or.packets = or.packets[1:]
+	return p, or.granule, nil
+}
+
+// Serial returns the serial number of the logical bitstream. It is
+// valid after the first packet has been read.
+func (or *Reader) Serial() uint32 {
+	return or.serial
+}
+
+// Reset discards the buffered packets. The Reader continues with the
+// page at the current position of the underlying io.Reader, which must
+// begin with a new packet (see Index). The Reader stays on the same
+// logical bitstream.
+func (or *Reader) Reset() {
+	or.packets = nil
+	or.partial = nil
+	or.eos = false
+}
+
+// readPage reads the next page of the logical bitstream.
+func (or *Reader) readPage() error {
+	for {
+		if _, err := io.ReadFull(or.r, or.header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF || (err == io.EOF && or.started) {
+				// truncated file (e.g. recording not properly closed)
+				or.eos = true
+				return nil
+			}
+			return err
+		}
+		h := or.header[:]
+		if !bytes.Equal(h[:4], capturePattern) || h[4] != 0 {
+			return ErrInvalidPage
+		}
+
+		segments := make([]byte, h[26])
+		if _, err := io.ReadFull(or.r, segments); err != nil {
+			or.eos = true
+			return nil
+		}
+		size := 0
+		for _, s := range segments {
+			size += int(s)
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(or.r, data); err != nil {
+			or.eos = true
+			return nil
+		}
+
+		page := make([]byte, 0, headerSize+len(segments)+len(data))
+		page = append(page, h...)
+		binary.LittleEndian.PutUint32(page[22:], 0)
+		page = append(page, segments...)
+		page = append(page, data...)
+		if crc(page) != binary.LittleEndian.Uint32(h[22:]) {
+			return fmt.Errorf("%v: checksum mismatch", ErrInvalidPage)
+		}
+
+		serial := binary.LittleEndian.Uint32(h[14:])
+		if !or.started {
+			or.serial = serial
+			or.started = true
+		}
+		if serial != or.serial {
+			continue
+		}
+
+		flags := h[5]
+		if flags&continued == 0 {
+			or.partial = nil
+		}
+		or.granule = int64(binary.LittleEndian.Uint64(h[6:]))
+		or.eos = flags&eos != 0
+
+		pos := 0
+		for _, s := range segments {
+			or.partial = append(or.partial, data[pos:pos+int(s)]...)
+			pos += int(s)
+			if s < 255 {
+				or.packets = append(or.packets, or.partial)
+				or.partial = nil
+			}
+		}
+		return nil
+	}
+}
+
+// Page describes a page of a logical bitstream on which a new packet
+// begins.
+type Page struct {
+	Offset  int64 // offset of the page in the file
+	Granule int64 // granule position at the beginning of the page
+}
+
+// Index scans the pages of the logical bitstream with the serial number
+// from the current position of r until the end of the file. It returns
+// the pages on which a new packet begins, which can be used for seeking,
+// and the granule position of the end of the stream. Only the page
+// headers are read; an incomplete page at the end of the file (e.g. of a
+// recording which hasn't been properly closed) is ignored.
+func Index(r io.ReadSeeker, serial uint32) ([]Page, int64, error) {
+
+	offset, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pages := []Page{}
+	var granule int64 // granule position of the last complete packet
+	hdr := make([]byte, headerSize+maxSegments)
+
+	for {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		if _, err := io.ReadFull(r, hdr[:headerSize]); err != nil {
+			break
+		}
+		if !bytes.Equal(hdr[:4], capturePattern) || hdr[4] != 0 {
+			return nil, 0, ErrInvalidPage
+		}
+		segments := hdr[headerSize : headerSize+int(hdr[26])]
+		if _, err := io.ReadFull(r, segments); err != nil {
+			break
+		}
+		length := int64(headerSize + len(segments))
+		for _, s := range segments {
+			length += int64(s)
+		}
+		if offset+length > size {
+			break
+		}
+
+		if binary.LittleEndian.Uint32(hdr[14:]) == serial {
+			if hdr[5]&continued == 0 && len(segments) > 0 {
+				pages = append(pages, Page{Offset: offset, Granule: granule})
+			}
+			// -1 indicates that no packet ends on the page
+			if gp := int64(binary.LittleEndian.Uint64(hdr[6:])); gp != -1 {
+				granule = gp
+			}
+		}
+		offset += length
+	}
+
+	return pages, granule, nil
+}
@@ -0,0 +1,310 @@
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestCRC(t *testing.T) {
+
+	tests := []struct {
+		name string
+		data []byte
+		crc  uint32
+	}{
+		{name: "empty", data: []byte{}, crc: 0},
+		// CRC-32/CKSUM check value 0x765e7680 without the final xor
+		{name: "check value", data: []byte("123456789"), crc: 0x89a1897f},
+		{name: "single byte", data: []byte{0x01}, crc: 0x04c11db7},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if c := crc(tc.data); c != tc.crc {
+				t.Errorf("got 0x%08x, expected 0x%08x", c, tc.crc)
+			}
+		})
+	}
+}
+
+// page is the parsed header of an Ogg page
+type page struct {
+	flags   byte
+	granule int64
+	seq     uint32
+}
+
+// pages splits the written stream into pages and verifies their
+// checksums.
+func pages(t *testing.T, data []byte) []page {
+	var ps []page
+	for len(data) > 0 {
+		if len(data) < headerSize || !bytes.Equal(data[:4], capturePattern) {
+			t.Fatal("invalid page header")
+		}
+		n := int(data[26])
+		size := headerSize + n
+		for _, s := range data[headerSize : headerSize+n] {
+			size += int(s)
+		}
+
+		p := append([]byte{}, data[:size]...)
+		binary.LittleEndian.PutUint32(p[22:], 0)
+		if crc(p) != binary.LittleEndian.Uint32(data[22:]) {
+			t.Errorf("page %d: checksum mismatch", len(ps))
+		}
+
+		ps = append(ps, page{
+			flags:   data[5],
+			granule: int64(binary.LittleEndian.Uint64(data[6:])),
+			seq:     binary.LittleEndian.Uint32(data[18:]),
+		})
+		data = data[size:]
+	}
+	return ps
+}
+
+func TestGranulePositions(t *testing.T) {
+
+	type packet struct {
+		size    int
+		granule int64
+		flush   bool  // flush the page after the packet
+		read    int64 // granule position returned by the reader
+	}
+
+	tests := []struct {
+		name    string
+		packets []packet
+		pages   []page
+	}{
+		{
+			name: "one packet per page",
+			packets: []packet{
+				{size: 10, granule: 960, flush: true, read: 960},
+				{size: 10, granule: 1920, flush: true, read: 1920},
+			},
+			pages: []page{
+				{flags: bos, granule: 960, seq: 0},
+				{granule: 1920, seq: 1},
+				{flags: eos, granule: 1920, seq: 2},
+			},
+		},
+		{
+			name: "last packet on the page sets the granule position",
+			packets: []packet{
+				{size: 10, granule: 960, read: 2880},
+				{size: 10, granule: 1920, read: 2880},
+				{size: 10, granule: 2880, read: 2880},
+			},
+			pages: []page{
+				{flags: bos | eos, granule: 2880, seq: 0},
+			},
+		},
+		{
+			name: "no packet ends on the page",
+			packets: []packet{
+				{size: 255 * 300, granule: 960, read: 960},
+			},
+			pages: []page{
+				{flags: bos, granule: -1, seq: 0},
+				{flags: continued, granule: 960, seq: 1},
+				{flags: eos, granule: 960, seq: 2},
+			},
+		},
+		{
+			name: "pages are written when full",
+			packets: []packet{
+				{size: 3000, granule: 960, read: 1920},
+				{size: 3000, granule: 1920, read: 1920},
+				{size: 10, granule: 2880, read: 2880},
+			},
+			pages: []page{
+				{flags: bos, granule: 1920, seq: 0},
+				{flags: eos, granule: 2880, seq: 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewWriter(buf, 1234)
+			for _, p := range tc.packets {
+				if err := w.WritePacket(make([]byte, p.size), p.granule); err != nil {
+					t.Fatal(err)
+				}
+				if p.flush {
+					if err := w.Flush(); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			ps := pages(t, buf.Bytes())
+			if len(ps) != len(tc.pages) {
+				t.Fatalf("got %d pages, expected %d", len(ps), len(tc.pages))
+			}
+			for i := range ps {
+				if ps[i] != tc.pages[i] {
+					t.Errorf("page %d: got %+v, expected %+v", i, ps[i], tc.pages[i])
+				}
+			}
+
+			// the reader returns the packets with the granule position
+			// of the page on which they end
+			r := NewReader(buf)
+			for i, p := range tc.packets {
+				data, granule, err := r.ReadPacket()
+				if err != nil {
+					t.Fatalf("packet %d: %v", i, err)
+				}
+				if len(data) != p.size {
+					t.Errorf("packet %d: got %d bytes, expected %d", i, len(data), p.size)
+				}
+				if granule != p.read {
+					t.Errorf("packet %d: got granule %d, expected %d", i, granule, p.read)
+				}
+			}
+			if _, _, err := r.ReadPacket(); err != io.EOF {
+				t.Errorf("expected io.EOF, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReaderChecksum(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, 1)
+	if err := w.WriteHeader([]byte("OpusHead")); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xff
+
+	if _, _, err := NewReader(bytes.NewReader(data)).ReadPacket(); err == nil {
+		t.Error("expected a checksum error")
+	}
+}
+
+func TestIndex(t *testing.T) {
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf, 42)
+	if err := w.WriteHeader([]byte("OpusHead")); err != nil {
+		t.Fatal(err)
+	}
+	start := buf.Len()
+
+	packets := []struct {
+		size    int
+		granule int64
+	}{
+		{size: 10, granule: 960},
+		{size: 10, granule: 1920},
+		{size: 255 * 300, granule: 2880}, // continues on the next page
+		{size: 10, granule: 3840},
+	}
+	for _, p := range packets {
+		data := make([]byte, p.size)
+		data[0] = byte(p.granule / 960)
+		if err := w.WritePacket(data, p.granule); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(buf.Bytes())
+	or := NewReader(r)
+	if _, _, err := or.ReadPacket(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first page following the header begins with granule 0; the
+	// page continuing the large packet is omitted
+	pages, end, err := Index(r, or.Serial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	granules := []int64{0, 960, 1920, 2880}
+	if len(pages) != len(granules) {
+		t.Fatalf("got %d pages, expected %d", len(pages), len(granules))
+	}
+	if pages[0].Offset != int64(start) {
+		t.Errorf("got offset %d, expected %d", pages[0].Offset, start)
+	}
+	for i, g := range granules {
+		if pages[i].Granule != g {
+			t.Errorf("page %d: got granule %d, expected %d", i, pages[i].Granule, g)
+		}
+	}
+	if end != 3840 {
+		t.Errorf("got end %d, expected 3840", end)
+	}
+
+	// continue reading at the last indexed page
+	if _, err := r.Seek(pages[3].Offset, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	or.Reset()
+	data, granule, err := or.ReadPacket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != 4 || granule != 3840 {
+		t.Errorf("got packet %d with granule %d, expected 4 with 3840", data[0], granule)
+	}
+
+	// an incomplete page at the end of the file is ignored
+	truncated := bytes.NewReader(buf.Bytes()[:buf.Len()-1])
+	if _, err := truncated.Seek(int64(start), io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	pages, end, err = Index(truncated, or.Serial())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 4 || end != 3840 {
+		t.Errorf("truncated: got %d pages and end %d, expected 4 and 3840", len(pages), end)
+	}
+}
+
+func TestOpusPacketSamples(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		packet  []byte
+		samples int
+	}{
+		{name: "empty", packet: nil, samples: 0},
+		{name: "SILK 20ms", packet: []byte{1 << 3}, samples: 960},
+		{name: "SILK 60ms", packet: []byte{3 << 3}, samples: 2880},
+		{name: "Hybrid 10ms", packet: []byte{12 << 3}, samples: 480},
+		{name: "CELT 2.5ms", packet: []byte{16 << 3}, samples: 120},
+		{name: "CELT 20ms", packet: []byte{31 << 3}, samples: 960},
+		{name: "two frames", packet: []byte{31<<3 | 1}, samples: 1920},
+		{name: "arbitrary frames", packet: []byte{31<<3 | 3, 3}, samples: 2880},
+		{name: "arbitrary frames without count", packet: []byte{31<<3 | 3}, samples: 0},
+		{name: "more than 120ms", packet: []byte{3<<3 | 3, 3}, samples: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if s := OpusPacketSamples(tc.packet); s != tc.samples {
+				t.Errorf("got %d, expected %d", s, tc.samples)
+			}
+		})
+	}
+}
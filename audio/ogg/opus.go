@@ -0,0 +1,110 @@
+package ogg
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// OpusSamplerate is the samplerate of the granule positions in Ogg Opus
+// streams, independent of the samplerate of the encoded audio.
+const OpusSamplerate = 48000
+
+// OpusHead is the identification header of an Ogg Opus stream
+// (RFC 7845, section 5.1). Only the channel mapping family 0 (mono /
+// stereo) is supported.
+type OpusHead struct {
+	Channels   int
+	PreSkip    int // samples (@48kHz) to be discarded at the beginning
+	Samplerate int // samplerate of the original audio (informational)
+	OutputGain int // gain (Q7.8 in dB) to be applied when decoding
+}
+
+// Marshal returns the header as a packet.
+func (h OpusHead) Marshal() []byte {
+	p := make([]byte, 19)
+	copy(p, "OpusHead")
+	p[8] = 1 // version
+	p[9] = byte(h.Channels)
+	binary.LittleEndian.PutUint16(p[10:], uint16(h.PreSkip))
+	binary.LittleEndian.PutUint32(p[12:], uint32(h.Samplerate))
+	binary.LittleEndian.PutUint16(p[16:], uint16(int16(h.OutputGain)))
+	p[18] = 0 // channel mapping family
+	return p
+}
+
+// ParseOpusHead parses the identification header packet.
+func ParseOpusHead(p []byte) (OpusHead, error) {
+	if len(p) < 19 || !bytes.HasPrefix(p, []byte("OpusHead")) {
+		return OpusHead{}, errors.New("no Ogg Opus stream")
+	}
+	if p[8]>>4 != 0 {
+		return OpusHead{}, errors.New("unsupported Ogg Opus version")
+	}
+	h := OpusHead{
+		Channels:   int(p[9]),
+		PreSkip:    int(binary.LittleEndian.Uint16(p[10:])),
+		Samplerate: int(binary.LittleEndian.Uint32(p[12:])),
+		OutputGain: int(int16(binary.LittleEndian.Uint16(p[16:]))),
+	}
+	if p[18] != 0 || h.Channels < 1 || h.Channels > 2 {
+		return OpusHead{}, errors.New("unsupported Ogg Opus channel mapping")
+	}
+	return h, nil
+}
+
+// OpusTags returns the comment header packet (RFC 7845, section 5.2)
+// with the vendor string and the comments (e.g. "TITLE=...").
+func OpusTags(vendor string, comments ...string) []byte {
+	p := []byte("OpusTags")
+	p = binary.LittleEndian.AppendUint32(p, uint32(len(vendor)))
+	p = append(p, vendor...)
+	p = binary.LittleEndian.AppendUint32(p, uint32(len(comments)))
+	for _, c := range comments {
+		p = binary.LittleEndian.AppendUint32(p, uint32(len(c)))
+		p = append(p, c...)
+	}
+	return p
+}
+
+// OpusPacketSamples returns the duration of an opus packet in samples
+// @48kHz, determined from its TOC byte (RFC 6716, section 3.1). 0 is
+// returned for invalid packets.
+func OpusPacketSamples(p []byte) int {
+	if len(p) == 0 {
+		return 0
+	}
+
+	toc := p[0]
+	config := toc >> 3
+
+	// frame size in samples @48kHz
+	var size int
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60ms
+		size = []int{480, 960, 1920, 2880}[config%4]
+	case config < 16: // Hybrid: 10, 20ms
+		size = []int{480, 960}[config%2]
+	default: // CELT: 2.5, 5, 10, 20ms
+		size = []int{120, 240, 480, 960}[config%4]
+	}
+
+	var frames int
+	switch toc & 0x03 {
+	case 0:
+		frames = 1
+	case 1, 2:
+		frames = 2
+	default:
+		if len(p) < 2 {
+			return 0
+		}
+		frames = int(p[1] & 0x3f)
+	}
+
+	// a packet may contain at most 120ms of audio
+	if frames*size > 5760 {
+		return 0
+	}
+	return frames * size
+}
@@ -0,0 +1,274 @@
+package flacWriter
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/dh1tw/gosamplerate"
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+)
+
+// FlacWriter implements the audio.Sink interface and is used to write
+// (record) audio frames in the FLAC format.
+type FlacWriter struct {
+	sync.Mutex
+	file    *file
+	encoder *flac.Encoder
+	options Options
+	volume  float32
+	src     src
+	pending [][]int32 // samples of each channel until a block is complete
+}
+
+// src contains a samplerate converter and its needed variables
+type src struct {
+	gosamplerate.Src
+	samplerate float64
+	ratio      float64
+}
+
+// file counts the bytes written into the FLAC file. Seek and Close are
+// needed by the encoder to complete the header of the file.
+type file struct {
+	*os.File
+	size int64
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// NewFlacWriter returns a flacWriter to which audio frames can be written
+// to. The audio data will be saved in the FLAC format.
+func NewFlacWriter(path string, opts ...Option) (*FlacWriter, error) {
+
+	w := &FlacWriter{
+		options: Options{
+			Channels:   DefaultChannels,
+			BitDepth:   DefaultBitDepth,
+			Samplerate: DefaultSamplerate,
+			BlockSize:  DefaultBlockSize,
+		},
+		volume: 1.0,
+	}
+
+	for _, o := range opts {
+		o(&w.options)
+	}
+
+	// make sure we only allow 16 / 24 bit Bitdepth (dynamic range)
+	switch w.options.BitDepth {
+	case 16, 24:
+	default:
+		w.options.BitDepth = 16
+	}
+
+	if w.options.BlockSize < 16 || w.options.BlockSize > 65535 {
+		w.options.BlockSize = DefaultBlockSize
+	}
+
+	if w.options.Channels < 1 || w.options.Channels > 8 {
+		return nil, fmt.Errorf("FlacWriter: unsupported amount of channels (%d)", w.options.Channels)
+	}
+
+	// setup a samplerate converter
+	srConv, err := gosamplerate.New(gosamplerate.SRC_SINC_FASTEST,
+		w.options.Channels, 65536)
+	if err != nil {
+		return nil, fmt.Errorf("FlacWriter samplerate converter: %v", err)
+	}
+	w.src = src{
+		Src:        srConv,
+		samplerate: w.options.Samplerate,
+		ratio:      1,
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w.file = &file{File: f}
+
+	info := &meta.StreamInfo{
+		BlockSizeMin:  uint16(w.options.BlockSize),
+		BlockSizeMax:  uint16(w.options.BlockSize),
+		SampleRate:    uint32(w.options.Samplerate),
+		NChannels:     uint8(w.options.Channels),
+		BitsPerSample: uint8(w.options.BitDepth),
+	}
+
+	w.encoder, err = flac.NewEncoder(w.file, info)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	w.pending = make([][]int32, w.options.Channels)
+
+	return w, nil
+}
+
+// Start writing audio to the flac file.
+func (w *FlacWriter) Start() error {
+	return nil
+}
+
+// Stop writing audio frames to the flac file.
+func (w *FlacWriter) Stop() error {
+	return nil
+}
+
+// Close shuts down properly the flacWriter. The remaining audio is
+// encoded, the header of the flac file is completed and the file
+// is closed.
+func (w *FlacWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.writeFrame(len(w.pending[0]))
+	// closes the file as well
+	if cErr := w.encoder.Close(); err == nil {
+		err = cErr
+	}
+	w.file = nil
+	return err
+}
+
+// Size returns the amount of bytes written to the flac file.
+func (w *FlacWriter) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return 0
+	}
+	return int(w.file.size)
+}
+
+// SetVolume sets the volume for all incoming audio frames.
+func (w *FlacWriter) SetVolume(v float32) {
+	w.Lock()
+	defer w.Unlock()
+	if v < 0 {
+		w.volume = 0
+	} else if v > 1 {
+		w.volume = 1
+	} else {
+		w.volume = v
+	}
+}
+
+// Volume returns the current volume.
+func (w *FlacWriter) Volume() float32 {
+	w.Lock()
+	defer w.Unlock()
+	return w.volume
+}
+
+// Write enqueues audio buffers to be written into the flac file. Channels
+// and Samplerate will be adjusted, if necessary.
+func (w *FlacWriter) Write(msg audio.Msg) error {
+
+	var aData []float32
+	var err error
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("flac file already closed")
+	}
+
+	// if necessary adjust the amount of audio channels. The msg's data
+	// might be shared with other sinks, therefore it is copied before
+	// the volume is adjusted.
+	if msg.Channels != w.options.Channels {
+		aData = audio.AdjustChannels(msg.Channels, w.options.Channels, msg.Data)
+	} else {
+		aData = make([]float32, len(msg.Data))
+		copy(aData, msg.Data)
+	}
+
+	audio.AdjustVolume(w.volume, aData)
+
+	if msg.Samplerate != w.options.Samplerate {
+		if w.src.samplerate != msg.Samplerate {
+			w.src.Reset()
+			w.src.samplerate = msg.Samplerate
+			w.src.ratio = w.options.Samplerate / msg.Samplerate
+		}
+		aData, err = w.src.Process(aData, w.src.ratio, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	max := int32(1) << (w.options.BitDepth - 1)
+
+	chs := w.options.Channels
+	for i := 0; i+chs <= len(aData); i += chs {
+		for ch := 0; ch < chs; ch++ {
+			s := int32(aData[i+ch] * float32(max))
+			if s > max-1 {
+				s = max - 1
+			} else if s < -max {
+				s = -max
+			}
+			w.pending[ch] = append(w.pending[ch], s)
+		}
+	}
+
+	for len(w.pending[0]) >= w.options.BlockSize {
+		if err := w.writeFrame(w.options.BlockSize); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeFrame encodes the first n pending samples of each channel into a
+// FLAC frame. This method is not safe for concurrent access.
+func (w *FlacWriter) writeFrame(n int) error {
+	if n == 0 {
+		return nil
+	}
+
+	subframes := make([]*frame.Subframe, w.options.Channels)
+	for ch := range subframes {
+		samples := make([]int32, n)
+		copy(samples, w.pending[ch])
+		w.pending[ch] = w.pending[ch][:copy(w.pending[ch], w.pending[ch][n:])]
+		subframes[ch] = &frame.Subframe{
+			// the encoder chooses a suitable prediction method
+			SubHeader: frame.SubHeader{Pred: frame.PredVerbatim},
+			Samples:   samples,
+			NSamples:  n,
+		}
+	}
+
+	f := &frame.Frame{
+		Header: frame.Header{
+			HasFixedBlockSize: true,
+			BlockSize:         uint16(n),
+			SampleRate:        uint32(w.options.Samplerate),
+			Channels:          frame.Channels(w.options.Channels - 1),
+			BitsPerSample:     uint8(w.options.BitDepth),
+		},
+		Subframes: subframes,
+	}
+
+	return w.encoder.WriteFrame(f)
+}
+
+// Flush is not implemented
+func (w *FlacWriter) Flush() {}
@@ -0,0 +1,56 @@
+package flacWriter
+
+// Option is the type for a function option
+type Option func(*Options)
+
+const (
+	// DefaultChannels recorded into a flac file
+	DefaultChannels int = 1
+	// DefaultSamplerate for writing flac files to disk
+	DefaultSamplerate float64 = 48000
+	// DefaultBitDepth for writing audio samples to disk
+	DefaultBitDepth int = 16
+	// DefaultBlockSize is the amount of frames encoded in each FLAC frame
+	DefaultBlockSize int = 4096
+)
+
+// Options contains the parameters for initializing a flac writer.
+type Options struct {
+	Channels   int
+	Samplerate float64
+	BitDepth   int
+	BlockSize  int
+}
+
+// Channels is a functional option to set the amount of channels of the
+// recording. Typically this is either Mono (1) or Stereo (2).
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// Samplerate is a functional option to set the sampling rate with which the
+// audio will be recorded.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// BitDepth is a functional option to set the bit depth (16 or 24 bit) with
+// which the audio will be written to file. For most usecases 16 bit
+// (default) is the way to go.
+func BitDepth(b int) Option {
+	return func(args *Options) {
+		args.BitDepth = b
+	}
+}
+
+// BlockSize is a functional option to set the amount of audio frames
+// which are encoded in each FLAC frame (16...65535).
+func BlockSize(s int) Option {
+	return func(args *Options) {
+		args.BlockSize = s
+	}
+}
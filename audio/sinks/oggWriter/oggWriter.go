@@ -0,0 +1,187 @@
+package oggWriter
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio/ogg"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"google.golang.org/protobuf/proto"
+)
+
+// OggWriter writes opus encoded audio frames, as they are received from
+// the network, into an Ogg Opus file. The frames are not decoded and
+// re-encoded, which keeps the files small and cheap to write (e.g. for
+// archiving the audio of a whole day). Frames of other codecs are
+// skipped.
+type OggWriter struct {
+	sync.Mutex
+	options   Options
+	file      *file
+	ogg       *ogg.Writer
+	channels  int   // channels of the stream; 0 until the headers are written
+	granule   int64 // position (in samples @48kHz) of the end of the last packet
+	user      string
+	lastHeard time.Time
+	codecWarn sync.Once
+}
+
+// NewOggWriter creates the Ogg Opus file. The headers are written when
+// the first frame arrives.
+func NewOggWriter(path string, opts ...Option) (*OggWriter, error) {
+
+	w := &OggWriter{
+		options: Options{
+			PreSkip:  DefaultPreSkip,
+			HoldTime: DefaultHoldTime,
+		},
+	}
+
+	for _, o := range opts {
+		o(&w.options)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w.file = &file{File: f}
+	w.ogg = ogg.NewWriter(w.file, rand.Uint32())
+
+	return w, nil
+}
+
+// file counts the bytes written into the file
+type file struct {
+	*os.File
+	size int64
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	n, err := f.File.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Enqueue writes an audio frame (serialized sbAudio.Frame) into the file.
+// Frames are only accepted from one user at a time; frames of other users
+// are dropped until the current user has been silent for the hold time.
+func (w *OggWriter) Enqueue(data []byte) error {
+
+	msg := sbAudio.Frame{}
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	if msg.GetCodec() != sbAudio.Codec_opus {
+		w.codecWarn.Do(func() {
+			log.Printf("ogg recording: skipping %s audio frames\n", msg.GetCodec())
+		})
+		return nil
+	}
+
+	chs := 1
+	if msg.GetChannels() == sbAudio.Channels_stereo {
+		chs = 2
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	user := msg.GetUserId()
+	if user != w.user && time.Since(w.lastHeard) < w.options.HoldTime {
+		return nil
+	}
+	w.user = user
+	w.lastHeard = time.Now()
+
+	return w.writePacket(msg.GetData(), chs, int(msg.GetSamplingRate()))
+}
+
+// WritePacket writes an opus packet into the file. All packets must have
+// the same amount of channels.
+func (w *OggWriter) WritePacket(packet []byte, channels int) error {
+	w.Lock()
+	defer w.Unlock()
+	return w.writePacket(packet, channels, ogg.OpusSamplerate)
+}
+
+// writePacket writes the packet and the headers, if necessary. This
+// method is not safe for concurrent access.
+func (w *OggWriter) writePacket(packet []byte, channels, samplerate int) error {
+
+	if w.file == nil {
+		return fmt.Errorf("ogg file already closed")
+	}
+
+	samples := ogg.OpusPacketSamples(packet)
+	if samples == 0 {
+		return fmt.Errorf("invalid opus packet")
+	}
+
+	if w.channels == 0 {
+		head := ogg.OpusHead{
+			Channels:   channels,
+			PreSkip:    w.options.PreSkip,
+			Samplerate: samplerate,
+		}
+		if err := w.ogg.WriteHeader(head.Marshal()); err != nil {
+			return err
+		}
+		tags := ogg.OpusTags("remoteAudio", w.options.Comments...)
+		if err := w.ogg.WriteHeader(tags); err != nil {
+			return err
+		}
+		w.channels = channels
+	}
+
+	if channels != w.channels {
+		return fmt.Errorf("ogg recording: %d channel packet in %d channel stream",
+			channels, w.channels)
+	}
+
+	w.granule += int64(samples)
+	return w.ogg.WritePacket(packet, w.granule)
+}
+
+// Size returns the amount of bytes written into the file.
+func (w *OggWriter) Size() int64 {
+	w.Lock()
+	defer w.Unlock()
+	if w.file == nil {
+		return 0
+	}
+	return w.file.size
+}
+
+// Duration returns the duration of the recorded audio.
+func (w *OggWriter) Duration() time.Duration {
+	w.Lock()
+	defer w.Unlock()
+	return time.Duration(w.granule) * time.Second / ogg.OpusSamplerate
+}
+
+// Close completes the Ogg stream and closes the file.
+func (w *OggWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	var err error
+	// an empty file (no frames received) is no valid ogg opus file
+	if w.channels > 0 {
+		err = w.ogg.Close()
+	}
+	if cErr := w.file.Close(); err == nil {
+		err = cErr
+	}
+	w.file = nil
+	return err
+}
@@ -0,0 +1,48 @@
+package oggWriter
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*Options)
+
+const (
+	// DefaultPreSkip is the amount of samples (@48kHz) discarded by the
+	// decoder at the beginning of the file. Since the recording starts
+	// in the middle of a stream, the decoder needs 80ms to converge
+	// (RFC 7845, section 4.3).
+	DefaultPreSkip int = 3840
+	// DefaultHoldTime after which frames of another user are accepted
+	DefaultHoldTime time.Duration = time.Millisecond * 500
+)
+
+// Options contains the parameters for initializing an ogg writer.
+type Options struct {
+	PreSkip  int
+	HoldTime time.Duration
+	Comments []string
+}
+
+// PreSkip is a functional option to set the amount of samples (@48kHz)
+// which the decoder discards at the beginning of the file.
+func PreSkip(s int) Option {
+	return func(args *Options) {
+		args.PreSkip = s
+	}
+}
+
+// HoldTime is a functional option to set the time after which the audio
+// frames of another user are written into the file, once the current
+// user has stopped transmitting.
+func HoldTime(t time.Duration) Option {
+	return func(args *Options) {
+		args.HoldTime = t
+	}
+}
+
+// Comments is a functional option to add comments (e.g. "TITLE=40m net")
+// to the header of the file.
+func Comments(c ...string) Option {
+	return func(args *Options) {
+		args.Comments = append(args.Comments, c...)
+	}
+}
@@ -30,7 +30,9 @@ func Channels(chs int) Option {
 // FramesPerBuffer is a functional option which sets the amount of sample frames
 // our audio device will request / provide when executing the callback.
 // Example: A buffer with 960 frames at 48000kHz / stereo contains
-// 1920 samples and results in 20ms Audio.
+// 1920 samples and results in 20ms Audio. The value refers to 48kHz; for
+// encoders with a different samplerate the amount of frames is adjusted
+// so that the duration of the frames remains the same.
 func FramesPerBuffer(s int) Option {
 	return func(args *Options) {
 		args.FramesPerBuffer = s
@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/dh1tw/gosamplerate"
 	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audiocodec"
 	"github.com/dh1tw/remoteAudio/audiocodec/opus"
 	"github.com/dh1tw/remoteAudio/utils"
 	"github.com/golang/protobuf/proto"
@@ -26,6 +28,16 @@ type PbWriter struct {
 	stash   []float32
 	src     src
 	volume  float32
+	seq     uint32 // sequence number of the next frame
+	format  frameFormat
+}
+
+// frameFormat describes the format of the encoded frames
+type frameFormat struct {
+	codec           sbAudio.Codec
+	bitDepth        int
+	samplerate      float64
+	framesPerBuffer int
 }
 
 // src contains a samplerate converter and its needed variables
@@ -67,6 +79,12 @@ func NewPbWriter(opts ...Option) (*PbWriter, error) {
 		pbw.options.Encoder = enc
 	}
 
+	format, err := pbw.getFrameFormat(pbw.options.Encoder)
+	if err != nil {
+		return nil, err
+	}
+	pbw.format = format
+
 	// setup a samplerate converter
 	srConv, err := gosamplerate.New(gosamplerate.SRC_SINC_FASTEST,
 		pbw.options.Channels, 65536)
@@ -75,7 +93,7 @@ func NewPbWriter(opts ...Option) (*PbWriter, error) {
 	}
 	pbw.src = src{
 		Src:        srConv,
-		samplerate: 48000,
+		samplerate: pbw.format.samplerate,
 		ratio:      1,
 	}
 
@@ -143,6 +161,9 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 		return errors.New("no encoder set")
 	}
 
+	// capture time of the last sample in the audio buffer
+	captured := time.Now()
+
 	// The resampling and encoding can be quite expensive (e.g. with opus). Therefore it is
 	// launched in a separate go routine.
 	go func() {
@@ -161,11 +182,11 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 			aData = audioMsg.Data
 		}
 
-		if audioMsg.Samplerate != 48000 {
+		if audioMsg.Samplerate != pbw.format.samplerate {
 			if pbw.src.samplerate != audioMsg.Samplerate {
 				pbw.src.Reset()
 				pbw.src.samplerate = audioMsg.Samplerate
-				pbw.src.ratio = 48000 / audioMsg.Samplerate
+				pbw.src.ratio = pbw.format.samplerate / audioMsg.Samplerate
 			}
 			aData, err = pbw.src.Process(aData, pbw.src.ratio, false)
 			if err != nil {
@@ -174,9 +195,9 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 			}
 		}
 
-		// audio buffer size we want to push into the opus encuder
+		// audio buffer size we want to push into the encoder
 		// opus only allows certain buffer sizes (2,5ms, 5ms, 10ms...etc)
-		expBufferSize := pbw.options.Channels * pbw.options.FramesPerBuffer
+		expBufferSize := pbw.options.Channels * pbw.format.framesPerBuffer
 
 		// if there is data stashed from previous calles, get it and prepend it
 		// to the data received
@@ -198,6 +219,10 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 			return
 		}
 
+		// amount of samples available, used to calculate the capture time
+		// of each frame
+		samples := len(aData)
+
 		// slice of audio buffers which will be send
 		var bData [][]float32
 
@@ -229,21 +254,29 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 			channels = sbAudio.Channels_stereo
 		}
 
-		for _, frame := range bData {
+		for i, frame := range bData {
 			num, err := pbw.options.Encoder.Encode(frame, pbw.buffer)
 			if err != nil {
 				log.Println(err)
 			}
 
+			// capture time of the first sample in this frame
+			offset := (samples - i*expBufferSize) / pbw.options.Channels
+			ts := captured.Add(-time.Duration(offset) * time.Second /
+				time.Duration(pbw.format.samplerate))
+
 			msg := sbAudio.Frame{
-				Data:         pbw.buffer[:num],
-				Channels:     channels,
-				BitDepth:     16,
-				Codec:        sbAudio.Codec_opus,
-				FrameLength:  int32(pbw.options.FramesPerBuffer),
-				SamplingRate: 48000,
-				UserId:       pbw.options.UserID,
+				Data:           pbw.buffer[:num],
+				Channels:       channels,
+				BitDepth:       int32(pbw.format.bitDepth),
+				Codec:          pbw.format.codec,
+				FrameLength:    int32(pbw.format.framesPerBuffer),
+				SamplingRate:   int32(pbw.format.samplerate),
+				UserId:         pbw.options.UserID,
+				SequenceNumber: pbw.seq,
+				Timestamp:      ts.UnixMicro(),
 			}
+			pbw.seq++
 
 			data, err := proto.Marshal(&msg)
 			if err != nil {
@@ -258,6 +291,104 @@ func (pbw *PbWriter) Write(audioMsg audio.Msg) error {
 	return nil
 }
 
+// getFrameFormat returns the format of the frames which will be produced
+// by the encoder. The FramesPerBuffer option refers to 48kHz, so the
+// amount of frames is adjusted to the samplerate of the encoder.
+func (pbw *PbWriter) getFrameFormat(enc audiocodec.Encoder) (frameFormat, error) {
+
+	codec, ok := sbAudio.Codec_value[enc.Name()]
+	if !ok {
+		return frameFormat{}, fmt.Errorf("codec %s can not be sent in protobuf frames", enc.Name())
+	}
+
+	// opus works internally with 48kHz / 16 bit
+	format := frameFormat{
+		codec:      sbAudio.Codec(codec),
+		bitDepth:   16,
+		samplerate: 48000,
+	}
+
+	if fd, ok := enc.(audiocodec.FormatDescriber); ok {
+		f := fd.Format()
+		if f.Samplerate > 0 {
+			format.samplerate = float64(f.Samplerate)
+		}
+		if f.Bitdepth > 0 {
+			format.bitDepth = f.Bitdepth
+		}
+	}
+
+	format.framesPerBuffer = int(float64(pbw.options.FramesPerBuffer) * format.samplerate / 48000)
+
+	// make sure that the largest possible frame (32bit PCM) fits
+	// into the buffer
+	if size := format.framesPerBuffer * pbw.options.Channels * 4; size > len(pbw.buffer) {
+		pbw.buffer = make([]byte, size)
+	}
+
+	return format, nil
+}
+
+// SetEncoder replaces the encoder with which the audio frames are
+// encoded. Stashed audio samples will be discarded.
+func (pbw *PbWriter) SetEncoder(enc audiocodec.Encoder) error {
+	pbw.Lock()
+	defer pbw.Unlock()
+
+	if enc == nil {
+		return errors.New("no encoder set")
+	}
+
+	format, err := pbw.getFrameFormat(enc)
+	if err != nil {
+		return err
+	}
+
+	pbw.options.Encoder = enc
+	pbw.format = format
+	pbw.stash = []float32{}
+	pbw.src.Reset()
+	pbw.src.samplerate = format.samplerate
+	pbw.src.ratio = 1
+
+	return nil
+}
+
+// Feedback passes the reception statistics reported by the receivers on
+// to the encoder. If the encoder is adaptive, it will adjust its output
+// (e.g. the bitrate) to the network conditions.
+func (pbw *PbWriter) Feedback(fb audiocodec.Feedback) error {
+	pbw.Lock()
+	defer pbw.Unlock()
+
+	adapter, ok := pbw.options.Encoder.(audiocodec.Adapter)
+	if !ok {
+		return nil
+	}
+	return adapter.Adapt(fb)
+}
+
+// UserID returns the UserID with which the frames are marked.
+func (pbw *PbWriter) UserID() string {
+	pbw.RLock()
+	defer pbw.RUnlock()
+	return pbw.options.UserID
+}
+
+// Channels returns the amount of channels which are encoded.
+func (pbw *PbWriter) Channels() int {
+	pbw.RLock()
+	defer pbw.RUnlock()
+	return pbw.options.Channels
+}
+
+// Encoder returns the encoder which is currently used.
+func (pbw *PbWriter) Encoder() audiocodec.Encoder {
+	pbw.RLock()
+	defer pbw.RUnlock()
+	return pbw.options.Encoder
+}
+
 // Flush clears all internal buffers
 func (pbw *PbWriter) Flush() {
 	pbw.Lock()
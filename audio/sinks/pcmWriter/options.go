@@ -0,0 +1,48 @@
+package pcmWriter
+
+// Option is the type for a function option
+type Option func(*Options)
+
+const (
+	// DefaultChannels recorded into a raw PCM file
+	DefaultChannels int = 1
+	// DefaultSamplerate for writing raw PCM files to disk
+	DefaultSamplerate float64 = 48000
+)
+
+// sample encodings of raw PCM files
+const (
+	S16LE = "s16le" // signed 16 bit integer, little endian
+	F32LE = "f32le" // 32 bit float, little endian
+)
+
+// Options contains the parameters for initializing a pcm writer.
+type Options struct {
+	Channels   int
+	Samplerate float64
+	Encoding   string
+}
+
+// Channels is a functional option to set the amount of (interleaved)
+// channels of the recording.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// Samplerate is a functional option to set the sampling rate with which the
+// audio will be recorded.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// Encoding is a functional option to set the encoding of the samples
+// (S16LE or F32LE). By default the samples are written as S16LE.
+func Encoding(e string) Option {
+	return func(args *Options) {
+		args.Encoding = e
+	}
+}
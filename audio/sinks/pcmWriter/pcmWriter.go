@@ -0,0 +1,195 @@
+package pcmWriter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/dh1tw/gosamplerate"
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// PcmWriter implements the audio.Sink interface and is used to write
+// (record) audio frames into a headerless (raw) PCM file.
+type PcmWriter struct {
+	sync.Mutex
+	file           *os.File
+	options        Options
+	bytesPerSample int
+	size           int64
+	volume         float32
+	src            src
+}
+
+// src contains a samplerate converter and its needed variables
+type src struct {
+	gosamplerate.Src
+	samplerate float64
+	ratio      float64
+}
+
+// NewPcmWriter returns a pcmWriter to which audio frames can be written
+// to. The samples are written without a header in the selected encoding.
+func NewPcmWriter(path string, opts ...Option) (*PcmWriter, error) {
+
+	w := &PcmWriter{
+		options: Options{
+			Channels:   DefaultChannels,
+			Samplerate: DefaultSamplerate,
+			Encoding:   S16LE,
+		},
+		volume: 1.0,
+	}
+
+	for _, o := range opts {
+		o(&w.options)
+	}
+
+	w.options.Encoding = strings.ToLower(w.options.Encoding)
+	switch w.options.Encoding {
+	case S16LE:
+		w.bytesPerSample = 2
+	case F32LE:
+		w.bytesPerSample = 4
+	default:
+		return nil, fmt.Errorf("unsupported PCM encoding %s", w.options.Encoding)
+	}
+
+	// setup a samplerate converter
+	srConv, err := gosamplerate.New(gosamplerate.SRC_SINC_FASTEST,
+		w.options.Channels, 65536)
+	if err != nil {
+		return nil, fmt.Errorf("PcmWriter samplerate converter: %v", err)
+	}
+	w.src = src{
+		Src:        srConv,
+		samplerate: w.options.Samplerate,
+		ratio:      1,
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w.file = f
+
+	return w, nil
+}
+
+// Start writing audio to the pcm file.
+func (w *PcmWriter) Start() error {
+	return nil
+}
+
+// Stop writing audio frames to the pcm file.
+func (w *PcmWriter) Stop() error {
+	return nil
+}
+
+// Close closes the pcm file.
+func (w *PcmWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// Size returns the amount of bytes written to the pcm file.
+func (w *PcmWriter) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	return int(w.size)
+}
+
+// SetVolume sets the volume for all incoming audio frames.
+func (w *PcmWriter) SetVolume(v float32) {
+	w.Lock()
+	defer w.Unlock()
+	if v < 0 {
+		w.volume = 0
+	} else if v > 1 {
+		w.volume = 1
+	} else {
+		w.volume = v
+	}
+}
+
+// Volume returns the current volume.
+func (w *PcmWriter) Volume() float32 {
+	w.Lock()
+	defer w.Unlock()
+	return w.volume
+}
+
+// Write writes audio buffers into the pcm file. Channels and Samplerate
+// will be adjusted, if necessary.
+func (w *PcmWriter) Write(msg audio.Msg) error {
+
+	var aData []float32
+	var err error
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("pcm file already closed")
+	}
+
+	// if necessary adjust the amount of audio channels. The msg's data
+	// might be shared with other sinks, therefore it is copied before
+	// the volume is adjusted.
+	if msg.Channels != w.options.Channels {
+		aData = audio.AdjustChannels(msg.Channels, w.options.Channels, msg.Data)
+	} else {
+		aData = make([]float32, len(msg.Data))
+		copy(aData, msg.Data)
+	}
+
+	audio.AdjustVolume(w.volume, aData)
+
+	if msg.Samplerate != w.options.Samplerate {
+		if w.src.samplerate != msg.Samplerate {
+			w.src.Reset()
+			w.src.samplerate = msg.Samplerate
+			w.src.ratio = w.options.Samplerate / msg.Samplerate
+		}
+		aData, err = w.src.Process(aData, w.src.ratio, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, len(aData)*w.bytesPerSample)
+
+	for i, s := range aData {
+		switch w.options.Encoding {
+		case F32LE:
+			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(s))
+		default:
+			v := s * 32768
+			if v > 32767 {
+				v = 32767
+			} else if v < -32768 {
+				v = -32768
+			}
+			binary.LittleEndian.PutUint16(buf[i*2:], uint16(int16(v)))
+		}
+	}
+
+	n, err := w.file.Write(buf)
+	w.size += int64(n)
+
+	return err
+}
+
+// Flush is not implemented
+func (w *PcmWriter) Flush() {}
@@ -2,7 +2,6 @@ package wavWriter
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"sync"
 
@@ -88,13 +87,31 @@ func (w *WavWriter) Stop() error {
 	return nil
 }
 
-// Close shuts down properly the wavWriter.
+// Close shuts down properly the wavWriter. The header of the wav file
+// is completed and the file is closed.
 func (w *WavWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
 	err := w.encoder.Close()
-	w.file.Close()
+	if cErr := w.file.Close(); err == nil {
+		err = cErr
+	}
+	w.file = nil
 	return err
 }
 
+// Size returns the amount of bytes written to the wav file.
+func (w *WavWriter) Size() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.encoder.WrittenBytes
+}
+
 // SetVolume sets the volume for all incoming audio frames.
 func (w *WavWriter) SetVolume(v float32) {
 	w.Lock()
@@ -129,16 +146,24 @@ func (w *WavWriter) Write(msg audio.Msg) error {
 		// b32 int = 2147483648 //to large on 32bit ARM
 	)
 
-	// if necessary adjust the amount of audio channels
+	w.Lock()
+	defer w.Unlock()
+
+	if w.file == nil {
+		return fmt.Errorf("wav file already closed")
+	}
+
+	// if necessary adjust the amount of audio channels. The msg's data
+	// might be shared with other sinks, therefore it is copied before
+	// the volume is adjusted.
 	if msg.Channels != w.options.Channels {
 		aData = audio.AdjustChannels(msg.Channels, w.options.Channels, msg.Data)
 	} else {
-		aData = msg.Data
+		aData = make([]float32, len(msg.Data))
+		copy(aData, msg.Data)
 	}
 
-	w.Lock()
 	audio.AdjustVolume(w.volume, aData)
-	w.Unlock()
 
 	if msg.Samplerate != w.options.Samplerate {
 		if w.src.samplerate != msg.Samplerate {
@@ -181,13 +206,7 @@ func (w *WavWriter) Write(msg audio.Msg) error {
 		}
 	}
 
-	if err := w.encoder.Write(&buf); err != nil {
-		log.Println(err)
-	}
-
-	fmt.Printf("writing %v samples\n", w.encoder.WrittenBytes)
-
-	return nil
+	return w.encoder.Write(&buf)
 }
 
 // Flush is not implemented
@@ -0,0 +1,53 @@
+package wsWriter
+
+import (
+	"github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options is the data structure holding the optional values. The
+// values are typically set by calling the functional options.
+type Options struct {
+	Encoder         audiocodec.Encoder
+	Channels        int
+	FramesPerBuffer int
+	QueueLength     int
+}
+
+// Encoder is a functional option to set the encoder with which the audio
+// will be sent to the websocket clients. By default 16 bit PCM (48kHz)
+// is used, since it can be played back by browsers without a decoder.
+func Encoder(enc audiocodec.Encoder) Option {
+	return func(args *Options) {
+		args.Encoder = enc
+	}
+}
+
+// Channels is a functional option to set the amount of channels which
+// will be sent to the websocket clients. By default 1 channel is used.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// FramesPerBuffer is a functional option which sets the amount of sample
+// frames (at 48kHz) which will be encoded and sent in one websocket message.
+// By default 960 frames (20ms) are used.
+func FramesPerBuffer(s int) Option {
+	return func(args *Options) {
+		args.FramesPerBuffer = s
+	}
+}
+
+// QueueLength is a functional option to set the amount of audio frames
+// which are queued for each websocket client. If a client is too slow to
+// receive the audio, the frames exceeding the queue will be dropped.
+// By default 25 frames are queued.
+func QueueLength(l int) Option {
+	return func(args *Options) {
+		args.QueueLength = l
+	}
+}
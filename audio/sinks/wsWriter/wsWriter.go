@@ -0,0 +1,296 @@
+package wsWriter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/dh1tw/gosamplerate"
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	"github.com/dh1tw/remoteAudio/audiocodec/pcm"
+	"github.com/gorilla/websocket"
+)
+
+// WsWriter implements the audio.Sink interface. It is used to encode audio.Msg
+// and stream them as binary messages to websocket clients (e.g. browsers).
+// When a client is added, a JSON text message describing the audio Format
+// is sent first.
+type WsWriter struct {
+	sync.RWMutex
+	options Options
+	enabled bool
+	format  Format
+	conns   map[*websocket.Conn]chan []byte
+	stash   []float32
+	buffer  []byte
+	src     src
+	volume  float32
+}
+
+// Format describes the format of the binary audio messages. It is sent to
+// each websocket client as the first (text) message.
+type Format struct {
+	Codec           string `json:"codec"`
+	Samplerate      int    `json:"samplerate"`
+	Channels        int    `json:"channels"`
+	BitDepth        int    `json:"bitdepth"`
+	FramesPerBuffer int    `json:"frames_per_buffer"`
+}
+
+// src contains a samplerate converter and its needed variables
+type src struct {
+	gosamplerate.Src
+	samplerate float64
+	ratio      float64
+}
+
+// NewWsWriter is the constructor for a WsWriter. Additional functional
+// options can be passed in (e.g. the audio codec to be used).
+func NewWsWriter(opts ...Option) (*WsWriter, error) {
+
+	w := &WsWriter{
+		options: Options{
+			Channels:        1,
+			FramesPerBuffer: 960,
+			QueueLength:     25,
+		},
+		conns:  make(map[*websocket.Conn]chan []byte),
+		volume: 0.7,
+	}
+
+	for _, option := range opts {
+		option(&w.options)
+	}
+
+	if w.options.Encoder == nil {
+		enc, err := pcm.NewEncoder(pcm.Channels(w.options.Channels))
+		if err != nil {
+			return nil, err
+		}
+		w.options.Encoder = enc
+	}
+
+	w.format = Format{
+		Codec:      w.options.Encoder.Name(),
+		Samplerate: 48000,
+		Channels:   w.options.Channels,
+		BitDepth:   16,
+	}
+	if fd, ok := w.options.Encoder.(audiocodec.FormatDescriber); ok {
+		f := fd.Format()
+		if f.Samplerate > 0 {
+			w.format.Samplerate = f.Samplerate
+		}
+		if f.Bitdepth > 0 {
+			w.format.BitDepth = f.Bitdepth
+		}
+	}
+	w.format.FramesPerBuffer = w.options.FramesPerBuffer * w.format.Samplerate / 48000
+
+	// large enough for 32bit PCM
+	w.buffer = make([]byte, w.format.FramesPerBuffer*w.options.Channels*4)
+
+	srConv, err := gosamplerate.New(gosamplerate.SRC_SINC_FASTEST,
+		w.options.Channels, 65536)
+	if err != nil {
+		return nil, fmt.Errorf("WsWriter samplerate converter: %v", err)
+	}
+	w.src = src{
+		Src:        srConv,
+		samplerate: float64(w.format.Samplerate),
+		ratio:      1,
+	}
+
+	return w, nil
+}
+
+// Format returns the format of the binary audio messages.
+func (w *WsWriter) Format() Format {
+	w.RLock()
+	defer w.RUnlock()
+	return w.format
+}
+
+// AddConn adds a websocket client to which the audio will be streamed. The
+// WsWriter is the only one writing to this connection until it is removed.
+func (w *WsWriter) AddConn(conn *websocket.Conn) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if _, ok := w.conns[conn]; ok {
+		return nil
+	}
+
+	header, err := json.Marshal(w.format)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, header); err != nil {
+		return err
+	}
+
+	queue := make(chan []byte, w.options.QueueLength)
+	w.conns[conn] = queue
+
+	go func() {
+		for data := range queue {
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				log.Println("WsWriter:", err)
+				go w.RemoveConn(conn)
+				// drain the queue until it is closed
+				for range queue {
+				}
+				return
+			}
+		}
+	}()
+
+	return nil
+}
+
+// RemoveConn removes a websocket client. The connection is not closed.
+func (w *WsWriter) RemoveConn(conn *websocket.Conn) {
+	w.Lock()
+	defer w.Unlock()
+
+	queue, ok := w.conns[conn]
+	if !ok {
+		return
+	}
+	close(queue)
+	delete(w.conns, conn)
+}
+
+// Start starts this audio sink.
+func (w *WsWriter) Start() error {
+	w.Lock()
+	defer w.Unlock()
+	w.enabled = true
+	return nil
+}
+
+// Stop disables this audio sink.
+func (w *WsWriter) Stop() error {
+	w.Lock()
+	defer w.Unlock()
+	w.enabled = false
+	return nil
+}
+
+// Close removes all websocket clients.
+func (w *WsWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	for conn, queue := range w.conns {
+		close(queue)
+		delete(w.conns, conn)
+	}
+	return nil
+}
+
+// SetVolume sets the volume for this sink
+func (w *WsWriter) SetVolume(v float32) {
+	w.Lock()
+	defer w.Unlock()
+	if v < 0 {
+		w.volume = 0
+	} else if v > 1 {
+		w.volume = 1
+	} else {
+		w.volume = v
+	}
+}
+
+// Volume returns the volume for this sink
+func (w *WsWriter) Volume() float32 {
+	w.RLock()
+	defer w.RUnlock()
+	return w.volume
+}
+
+// Write encodes the audio.Msg and queues the encoded frames for all
+// connected websocket clients. Nothing is done if no client is connected.
+func (w *WsWriter) Write(msg audio.Msg) error {
+	w.Lock()
+	defer w.Unlock()
+
+	if !w.enabled || len(w.conns) == 0 {
+		return nil
+	}
+
+	if w.options.Encoder == nil {
+		return errors.New("no encoder set")
+	}
+
+	var aData []float32
+	var err error
+
+	// if necessary adjust the amount of audio channels
+	if msg.Channels != w.options.Channels {
+		aData = audio.AdjustChannels(msg.Channels, w.options.Channels, msg.Data)
+	} else {
+		aData = append([]float32{}, msg.Data...)
+	}
+
+	samplerate := float64(w.format.Samplerate)
+	if msg.Samplerate != samplerate {
+		if w.src.samplerate != msg.Samplerate {
+			w.src.Reset()
+			w.src.samplerate = msg.Samplerate
+			w.src.ratio = samplerate / msg.Samplerate
+		}
+		aData, err = w.src.Process(aData, w.src.ratio, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(w.stash) > 0 {
+		aData = append(w.stash, aData...)
+		w.stash = nil
+	}
+
+	expBufferSize := w.format.FramesPerBuffer * w.options.Channels
+
+	for len(aData) >= expBufferSize {
+		frame := aData[:expBufferSize]
+		aData = aData[expBufferSize:]
+
+		if w.volume != 1 {
+			audio.AdjustVolume(w.volume, frame)
+		}
+
+		num, err := w.options.Encoder.Encode(frame, w.buffer)
+		if err != nil {
+			return err
+		}
+
+		for _, queue := range w.conns {
+			data := make([]byte, num)
+			copy(data, w.buffer[:num])
+			select {
+			case queue <- data:
+			default:
+				// client too slow; drop the frame
+			}
+		}
+	}
+
+	// stash the left over
+	if len(aData) > 0 {
+		w.stash = aData
+	}
+
+	return nil
+}
+
+// Flush clears all internal buffers
+func (w *WsWriter) Flush() {
+	w.Lock()
+	defer w.Unlock()
+	w.stash = nil
+}
@@ -0,0 +1,174 @@
+package flacReader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dh1tw/remoteAudio/audio/sources/player"
+	"github.com/mewkiz/flac"
+)
+
+// FlacReader implements the audio.Source interface and is used to read
+// (play) audio frames from a FLAC file. The audio is streamed from disk,
+// so that long files don't have to be loaded into memory. The playback
+// can be paused, resumed, positioned (Seek) and looped.
+type FlacReader struct {
+	*player.Player
+}
+
+// decoder implements the player.FrameReader interface for FLAC files.
+type decoder struct {
+	file     *os.File
+	stream   *flac.Stream
+	channels int
+	scale    float32   // converts the samples into the range of -1...1
+	pending  []float32 // decoded samples which haven't been read yet
+	skip     int       // samples to be dropped after a seek
+}
+
+// NewFlacReader opens a FLAC file and returns a FlacReader object which
+// implements the audio.Source interface. The file stays open until
+// the FlacReader is closed.
+func NewFlacReader(file string, opts ...Option) (*FlacReader, error) {
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := flac.NewSeek(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid FLAC file: %v", err)
+	}
+
+	r := &decoder{
+		file:     f,
+		stream:   stream,
+		channels: int(stream.Info.NChannels),
+		scale:    float32(int64(1) << (stream.Info.BitsPerSample - 1)),
+	}
+
+	options := Options{
+		FramesPerBuffer: DefaultFramesPerBuffer,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if r.channels < 1 || stream.Info.SampleRate < 1 {
+		f.Close()
+		return nil, errors.New("invalid FLAC header")
+	}
+
+	// make sure that the file contains audio
+	if err := r.decode(); err != nil {
+		f.Close()
+		if err == io.EOF {
+			return nil, errors.New("flac file contains no audio")
+		}
+		return nil, err
+	}
+
+	// the amount of frames is 0 if it is not contained in the header
+	p := player.New("flacReader", r, player.Format{
+		Samplerate: float64(stream.Info.SampleRate),
+		Channels:   r.channels,
+		Frames:     int64(stream.Info.NSamples),
+	},
+		player.FramesPerBuffer(options.FramesPerBuffer),
+		player.Loop(options.Loop),
+	)
+
+	return &FlacReader{p}, nil
+}
+
+// SeekFrame positions the stream at the frame.
+func (r *decoder) SeekFrame(frame int64) error {
+	r.pending = r.pending[:0]
+	r.skip = 0
+
+	// seeking within the stream requires a seek table, which has to be
+	// generated by decoding the whole file if the file doesn't contain
+	// one. Therefore the stream is simply re-opened when rewinding.
+	if frame == 0 {
+		if _, err := r.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		stream, err := flac.NewSeek(r.file)
+		if err != nil {
+			return err
+		}
+		r.stream = stream
+		return nil
+	}
+
+	start, err := r.stream.Seek(uint64(frame))
+	if err != nil {
+		return err
+	}
+	r.skip = int(frame-int64(start)) * r.channels
+	return nil
+}
+
+// ReadFrames returns the next frames of the stream.
+func (r *decoder) ReadFrames(frames int) ([]float32, error) {
+
+	size := frames * r.channels
+
+	// decode ahead, so that the end of the file is known when the
+	// last frames are returned
+	var err error
+	for len(r.pending) <= size && err == nil {
+		err = r.decode()
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	n := min(size, len(r.pending))
+	data := make([]float32, n)
+	copy(data, r.pending)
+	r.pending = r.pending[:copy(r.pending, r.pending[n:])]
+
+	if err == io.EOF && len(r.pending) == 0 {
+		return data, io.EOF
+	}
+
+	return data, nil
+}
+
+// decode decodes the next FLAC frame and appends the interleaved samples
+// to the pending samples.
+func (r *decoder) decode() error {
+
+	frame, err := r.stream.ParseNext()
+	if err != nil {
+		return err
+	}
+
+	n := int(frame.BlockSize)
+	if len(frame.Subframes) != r.channels {
+		return fmt.Errorf("invalid amount of channels in frame %d", frame.Num)
+	}
+
+	for i := 0; i < n; i++ {
+		if r.skip > 0 {
+			r.skip -= r.channels
+			continue
+		}
+		for _, sub := range frame.Subframes {
+			r.pending = append(r.pending, float32(sub.Samples[i])/r.scale)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (r *decoder) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,33 @@
+package flacReader
+
+const (
+	// DefaultFramesPerBuffer default amount of audio frames provided per buffer
+	DefaultFramesPerBuffer int = 4096
+)
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options contains the parameters for initializing a flac Reader.
+type Options struct {
+	FramesPerBuffer int
+	Loop            bool
+}
+
+// FramesPerBuffer is a functional option which sets the amount of audio frames
+// the flacReader will provide when executing the callback.
+// Example: A buffer with 960 frames at 48kHz results in 20ms Audio.
+func FramesPerBuffer(s int) Option {
+	return func(args *Options) {
+		args.FramesPerBuffer = s
+	}
+}
+
+// Loop is a functional option which enables the looping of the file. When
+// the end of the file has been reached, the playback continues at the
+// beginning until the flacReader is stopped.
+func Loop(enabled bool) Option {
+	return func(args *Options) {
+		args.Loop = enabled
+	}
+}
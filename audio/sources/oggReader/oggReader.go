@@ -0,0 +1,219 @@
+package oggReader
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/dh1tw/remoteAudio/audio/ogg"
+	"github.com/dh1tw/remoteAudio/audio/sources/player"
+	"github.com/dh1tw/remoteAudio/audiocodec/opus"
+)
+
+// seekPreRoll is the amount of samples (@48kHz) which are decoded and
+// discarded before the position of a seek, so that the decoder has
+// converged (RFC 7845, section 4.6).
+const seekPreRoll = 3840
+
+// OggReader implements the audio.Source interface and is used to play
+// Ogg Opus files (e.g. recorded with the oggWriter). The packets are
+// read from disk and decoded during the playback, so that long
+// recordings don't have to be loaded into memory. The playback can be
+// paused, resumed, positioned (Seek) and looped.
+type OggReader struct {
+	*player.Player
+}
+
+// decoder implements the player.FrameReader interface for Ogg Opus files.
+type decoder struct {
+	file     *os.File
+	reader   *ogg.Reader
+	dec      *opus.OpusDecoder
+	channels int
+	preSkip  int64
+	pages    []ogg.Page // pages on which a new packet begins
+	end      int64      // granule position of the end of the stream
+	granule  int64      // granule position of the next decoded sample
+	start    int64      // samples before this granule position are dropped
+	pcm      []float32
+	pending  []float32 // decoded samples which haven't been read yet
+}
+
+// NewOggReader opens an Ogg Opus file and returns an OggReader object
+// which implements the audio.Source interface. The file stays open until
+// the OggReader is closed.
+func NewOggReader(file string, opts ...Option) (*OggReader, error) {
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := newDecoder(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	options := Options{
+		FramesPerBuffer: DefaultFramesPerBuffer,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	p := player.New("oggReader", r, player.Format{
+		Samplerate: ogg.OpusSamplerate,
+		Channels:   r.channels,
+		Frames:     r.end - r.preSkip,
+	},
+		player.FramesPerBuffer(options.FramesPerBuffer),
+		player.Loop(options.Loop),
+	)
+
+	return &OggReader{p}, nil
+}
+
+func newDecoder(f *os.File) (*decoder, error) {
+
+	or := ogg.NewReader(f)
+
+	pkt, _, err := or.ReadPacket()
+	if err != nil {
+		return nil, err
+	}
+	head, err := ogg.ParseOpusHead(pkt)
+	if err != nil {
+		return nil, err
+	}
+	// comment header; the audio data begins on the following page
+	if _, _, err := or.ReadPacket(); err != nil {
+		return nil, err
+	}
+
+	pages, end, err := ogg.Index(f, or.Serial())
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 || end <= int64(head.PreSkip) {
+		return nil, errors.New("ogg file contains no audio")
+	}
+
+	r := &decoder{
+		file:     f,
+		reader:   or,
+		channels: head.Channels,
+		preSkip:  int64(head.PreSkip),
+		pages:    pages,
+		end:      end,
+		pcm:      make([]float32, 5760*head.Channels), // max 120ms per packet
+	}
+
+	if err := r.SeekFrame(0); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SeekFrame positions the stream at the frame. The decoding starts on
+// the last page which begins at least the pre-roll before the frame.
+func (r *decoder) SeekFrame(frame int64) error {
+
+	target := frame + r.preSkip
+
+	i := sort.Search(len(r.pages), func(i int) bool {
+		return r.pages[i].Granule > target-seekPreRoll
+	})
+	page := r.pages[max(i-1, 0)]
+
+	if _, err := r.file.Seek(page.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	r.reader.Reset()
+
+	// a new decoder doesn't carry over the state of the previous position
+	dec, err := opus.NewOpusDecoder(
+		opus.Channels(r.channels),
+		opus.Samplerate(ogg.OpusSamplerate))
+	if err != nil {
+		return err
+	}
+
+	r.dec = dec
+	r.granule = page.Granule
+	r.start = target
+	r.pending = r.pending[:0]
+
+	return nil
+}
+
+// ReadFrames returns the next frames of the stream.
+func (r *decoder) ReadFrames(frames int) ([]float32, error) {
+
+	size := frames * r.channels
+
+	// decode ahead, so that the end of the file is known when the
+	// last frames are returned
+	var err error
+	for len(r.pending) <= size && err == nil {
+		err = r.decode()
+	}
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	n := min(size, len(r.pending))
+	data := make([]float32, n)
+	copy(data, r.pending)
+	r.pending = r.pending[:copy(r.pending, r.pending[n:])]
+
+	if err == io.EOF && len(r.pending) == 0 {
+		return data, io.EOF
+	}
+
+	return data, nil
+}
+
+// decode decodes the next packet and appends the interleaved samples to
+// the pending samples. Samples before the start position (pre-skip and
+// seek pre-roll) and after the end of the stream (padding of the last
+// packet) are dropped.
+func (r *decoder) decode() error {
+
+	if r.granule >= r.end {
+		return io.EOF
+	}
+
+	pkt, _, err := r.reader.ReadPacket()
+	if err != nil {
+		return err
+	}
+
+	n, err := r.dec.Decode(pkt, r.pcm)
+	if err != nil {
+		// skip the packet, but keep track of the position
+		log.Println("oggReader:", err)
+		r.granule += int64(ogg.OpusPacketSamples(pkt))
+		return nil
+	}
+
+	pos := r.granule
+	r.granule += int64(n)
+
+	from := max(r.start-pos, 0)
+	to := min(int64(n), r.end-pos)
+	if from < to {
+		r.pending = append(r.pending, r.pcm[from*int64(r.channels):to*int64(r.channels)]...)
+	}
+
+	return nil
+}
+
+// Close closes the file.
+func (r *decoder) Close() error {
+	return r.file.Close()
+}
@@ -1,6 +1,8 @@
 package pbReader
 
 import (
+	"time"
+
 	"github.com/dh1tw/remoteAudio/audio"
 	"github.com/dh1tw/remoteAudio/audiocodec"
 )
@@ -11,11 +13,14 @@ type Option func(*Options)
 // Options is the data structure which holds the option values. These
 // values are typically set through functional options.
 type Options struct {
-	DeviceName string
-	Decoder    audiocodec.Decoder
-	Channels   int
-	Samplerate float64
-	Callback   audio.OnDataCb
+	DeviceName  string
+	Decoder     audiocodec.Decoder
+	Channels    int
+	Samplerate  float64
+	Callback    audio.OnDataCb
+	MinDelay    time.Duration
+	MaxDelay    time.Duration
+	IdleTimeout time.Duration
 }
 
 // Channels is a functional option to set the amount of channels to be used
@@ -43,3 +48,30 @@ func Decoder(dec audiocodec.Decoder) Option {
 		args.Decoder = dec
 	}
 }
+
+// MinDelay is a functional option to set the minimum delay of the jitter
+// buffer. The playout of an audio stream only starts once this amount
+// of audio has been buffered.
+func MinDelay(d time.Duration) Option {
+	return func(args *Options) {
+		args.MinDelay = d
+	}
+}
+
+// MaxDelay is a functional option to set the maximum delay of the jitter
+// buffer. The delay adapts to the measured jitter, but will never exceed
+// this value.
+func MaxDelay(d time.Duration) Option {
+	return func(args *Options) {
+		args.MaxDelay = d
+	}
+}
+
+// IdleTimeout is a functional option to set the duration after which
+// the jitter buffer and the decoder of a user will be disposed if no more
+// audio frames are received. By default, the timeout is set to 2 seconds.
+func IdleTimeout(d time.Duration) Option {
+	return func(args *Options) {
+		args.IdleTimeout = d
+	}
+}
@@ -4,35 +4,72 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"time"
 
+	"github.com/dh1tw/gosamplerate"
 	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/jitterbuffer"
 	"github.com/dh1tw/remoteAudio/audiocodec"
-	"github.com/dh1tw/remoteAudio/audiocodec/opus"
 	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
 	"github.com/golang/protobuf/proto"
 )
 
 // PbReader implements the audio.Source interface and is used to read
 // audio frames encapsulated in Protocol Buffer messages, typically
-// received from the network.
+// received from the network. The frames pass through a jitter buffer
+// before they are decoded, so that reordered, duplicated or late frames
+// don't end up as audible clicks.
 type PbReader struct {
 	sync.RWMutex
+	options            Options
 	enabled            bool
 	name               string
-	decoders           map[string]audiocodec.Decoder
-	decoder            audiocodec.Decoder
+	streams            map[string]*stream
 	callback           audio.OnDataCb
-	lastUser           string
 	emptyUserIDWarning sync.Once
 }
 
+// stream contains the jitter buffer and the decoder for the audio frames
+// of a particular user (sender).
+type stream struct {
+	sync.Mutex
+	userID    string
+	jb        *jitterbuffer.JitterBuffer
+	decoder   audiocodec.Decoder
+	codec     sbAudio.Codec
+	channels  int
+	decRate   int     // samplerate for which the decoder has been created
+	frames    int     // frame length (per channel) of the last decoded frame
+	rate      float64 // samplerate of the last decoded frame
+	lastHeard time.Time
+	localSeq  uint32             // used for senders which don't provide sequence numbers
+	src       *src               // samplerate converter; only needed for PCM frames != 48kHz
+	reported  jitterbuffer.Stats // statistics at the time of the last feedback
+	closeCh   chan struct{}
+}
+
+// src contains a samplerate converter and its needed variables
+type src struct {
+	gosamplerate.Src
+	channels   int
+	samplerate float64
+	ratio      float64
+}
+
 // NewPbReader is the constructor for a PbReader object.
-func NewPbReader() (*PbReader, error) {
+func NewPbReader(opts ...Option) (*PbReader, error) {
 
 	pbr := &PbReader{
-		name:     "ProtoBufReader",
-		decoders: make(map[string]audiocodec.Decoder),
-		lastUser: "",
+		options: Options{
+			DeviceName:  "ProtoBufReader",
+			IdleTimeout: time.Second * 2,
+		},
+		name:    "ProtoBufReader",
+		streams: make(map[string]*stream),
+	}
+
+	for _, option := range opts {
+		option(&pbr.options)
 	}
 
 	return pbr, nil
@@ -51,9 +88,29 @@ func (pbr *PbReader) Stop() error {
 	pbr.Lock()
 	defer pbr.Unlock()
 	pbr.enabled = false
+	pbr.reset()
 	return nil
 }
 
+// Reset discards the jitter buffers and decoders of all streams. The
+// next frames received start new streams. This is necessary when
+// switching to another audio stream of the same sender, since its
+// sequence numbers are unrelated.
+func (pbr *PbReader) Reset() {
+	pbr.Lock()
+	defer pbr.Unlock()
+	pbr.reset()
+}
+
+// reset removes all streams. This method is not safe for concurrent
+// access.
+func (pbr *PbReader) reset() {
+	for userID, s := range pbr.streams {
+		close(s.closeCh)
+		delete(pbr.streams, userID)
+	}
+}
+
 // Close shuts down the PbReader
 func (pbr *PbReader) Close() error {
 	return nil
@@ -67,8 +124,53 @@ func (pbr *PbReader) SetCb(cb audio.OnDataCb) {
 	pbr.callback = cb
 }
 
+// Stats returns the jitter buffer statistics of the audio stream
+// received from a particular user.
+func (pbr *PbReader) Stats(userID string) (jitterbuffer.Stats, error) {
+	pbr.RLock()
+	defer pbr.RUnlock()
+
+	s, ok := pbr.streams[userID]
+	if !ok {
+		return jitterbuffer.Stats{}, fmt.Errorf("no audio stream from user %s", userID)
+	}
+	return s.jb.Stats(), nil
+}
+
+// Feedback returns the reception statistics of the audio stream received
+// from a particular user since the last call of this method. The feedback
+// is typically sent back to the sender, so that it can adapt its encoder
+// to the network conditions.
+func (pbr *PbReader) Feedback(userID string) (audiocodec.Feedback, error) {
+	pbr.RLock()
+	s, ok := pbr.streams[userID]
+	pbr.RUnlock()
+
+	if !ok {
+		return audiocodec.Feedback{}, fmt.Errorf("no audio stream from user %s", userID)
+	}
+
+	stats := s.jb.Stats()
+
+	s.Lock()
+	received := stats.Received - s.reported.Received
+	lost := stats.Lost - s.reported.Lost
+	s.reported = stats
+	s.Unlock()
+
+	fb := audiocodec.Feedback{
+		Jitter: stats.Jitter,
+	}
+	if received+lost > 0 {
+		fb.Loss = float64(lost) / float64(received+lost)
+	}
+
+	return fb, nil
+}
+
 // Enqueue is the entry point for the PbReader. Incoming Protobufs
-// are enqueded with this function.
+// are enqueded with this function. The frames are put into the jitter
+// buffer of the sending user and decoded once they are due for playout.
 func (pbr *PbReader) Enqueue(data []byte) error {
 	pbr.Lock()
 	defer pbr.Unlock()
@@ -92,14 +194,6 @@ func (pbr *PbReader) Enqueue(data []byte) error {
 		return err
 	}
 
-	channels := 0
-	switch msg.GetChannels() {
-	case sbAudio.Channels_mono:
-		channels = 1
-	case sbAudio.Channels_stereo:
-		channels = 2
-	}
-
 	if len(msg.Data) == 0 {
 		log.Println("incoming protobuf audio frame empty")
 		return nil
@@ -113,77 +207,295 @@ func (pbr *PbReader) Enqueue(data []byte) error {
 		})
 	}
 
-	codecName := msg.GetCodec().String()
-
-	switch codecName {
-	case "opus":
-	// case "pcm":
-	default:
+	if _, ok := audiocodec.Lookup(msg.GetCodec().String()); !ok {
 		return fmt.Errorf("unknown codec %v", msg.Codec.String())
 	}
 
-	buf := make([]float32, int(msg.GetFrameLength())*channels)
+	if msg.GetSamplingRate() <= 0 || msg.GetFrameLength() <= 0 {
+		return fmt.Errorf("invalid audio frame from user %s", msg.GetUserId())
+	}
 
 	txUser := msg.GetUserId()
 
 	// we can not use the same opus decoder when packets of multiple
 	// users arrive at the same time. This ends up in a very distorted
-	// audio. Therefore we create a new decoder on demand for each txUser
-	if pbr.lastUser != txUser {
-		codec, ok := pbr.decoders[txUser]
-		if !ok {
-			switch codecName {
-			case "opus":
-				newCodec, err := newOpusDecoder(channels)
-				if err != nil {
-					return (err)
-				}
-				pbr.decoders[txUser] = newCodec
-				pbr.decoder = newCodec
-			case "pcm":
-				// in case of PCM we might have to resample the audio
-				// to match the internally prefered 48Khz
+	// audio. Therefore we create a new stream (jitter buffer + decoder)
+	// on demand for each txUser
+	s, ok := pbr.streams[txUser]
+	if !ok {
+		s = pbr.newStream(txUser)
+		pbr.streams[txUser] = s
+		go pbr.playout(s, frameDuration(&msg))
+	}
+
+	s.Lock()
+	s.lastHeard = time.Now()
+	seq := msg.GetSequenceNumber()
+	var ts time.Time
+	if msg.GetTimestamp() == 0 {
+		// the sender doesn't provide sequence numbers and timestamps, so
+		// we have to assume that the frames arrive in order
+		seq = s.localSeq
+		s.localSeq++
+	} else {
+		ts = time.UnixMicro(msg.GetTimestamp())
+	}
+	s.Unlock()
+
+	s.jb.Put(jitterbuffer.Packet{
+		Seq:       seq,
+		Timestamp: ts,
+		Duration:  frameDuration(&msg),
+		Data:      &msg,
+	})
+
+	return nil
+}
+
+// newStream creates the jitter buffer for a particular user. This method
+// is not safe for concurrent access.
+func (pbr *PbReader) newStream(userID string) *stream {
+
+	jbOpts := []jitterbuffer.Option{}
+	if pbr.options.MinDelay > 0 {
+		jbOpts = append(jbOpts, jitterbuffer.MinDelay(pbr.options.MinDelay))
+	}
+	if pbr.options.MaxDelay > 0 {
+		jbOpts = append(jbOpts, jitterbuffer.MaxDelay(pbr.options.MaxDelay))
+	}
+
+	return &stream{
+		userID:    userID,
+		jb:        jitterbuffer.New(jbOpts...),
+		lastHeard: time.Now(),
+		closeCh:   make(chan struct{}),
+	}
+}
+
+// playout is a blocking function which dequeues the audio frames of a
+// stream from its jitter buffer in the pace of the frame duration. The
+// pace follows the duration of each dequeued frame, since the sender
+// might change the frame length or the samplerate at any time. If no
+// frames have been received for the duration of the idle timeout, the
+// stream will be removed.
+func (pbr *PbReader) playout(s *stream, interval time.Duration) {
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	next := time.Now().Add(interval)
+
+	// the samplerate converter is only accessed from this go routine
+	defer func() {
+		if s.src != nil {
+			gosamplerate.Delete(s.src.Src)
+		}
+	}()
+
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case <-timer.C:
+		}
+
+		s.Lock()
+		idle := time.Since(s.lastHeard) > pbr.options.IdleTimeout
+		s.Unlock()
+
+		if idle {
+			pbr.Lock()
+			if pbr.streams[s.userID] == s {
+				delete(pbr.streams, s.userID)
+			}
+			pbr.Unlock()
+			return
+		}
+
+		p, status := s.jb.Get()
+
+		if p.Duration > 0 {
+			interval = p.Duration
+		}
+		next = next.Add(interval)
+		// don't try to catch up after the process has been stalled
+		if time.Until(next) < -interval {
+			next = time.Now().Add(interval)
+		}
+		timer.Reset(time.Until(next))
+
+		switch status {
+		case jitterbuffer.Ready:
+			frame, ok := p.Data.(*sbAudio.Frame)
+			if !ok {
+				continue
+			}
+			if err := pbr.decode(s, frame); err != nil {
+				log.Println(err)
+			}
+		case jitterbuffer.Missing:
+			if err := pbr.conceal(s); err != nil {
+				log.Println(err)
 			}
-		} else {
-			pbr.decoder = codec // codec already exists for txUser
 		}
-		pbr.lastUser = txUser
+	}
+}
+
+// decode decodes an audio frame and passes the resulting audio.Msg on
+// to the callback.
+func (pbr *PbReader) decode(s *stream, frame *sbAudio.Frame) error {
+
+	channels := 0
+	switch frame.GetChannels() {
+	case sbAudio.Channels_mono:
+		channels = 1
+	case sbAudio.Channels_stereo:
+		channels = 2
+	}
+
+	// in case the txUser has switched from stereo to mono
+	// the samples won't fit into the buffer anymore. Therefore
+	// we have to create a new decoder. The same applies if the
+	// txUser has switched the codec or the samplerate.
+	if s.decoder == nil || s.channels != channels || s.codec != frame.GetCodec() ||
+		s.decRate != int(frame.GetSamplingRate()) {
+		dec, err := audiocodec.NewDecoder(frame.GetCodec().String(),
+			audiocodec.Options{
+				Samplerate: int(frame.GetSamplingRate()),
+				Channels:   channels,
+				Bitdepth:   int(frame.GetBitDepth()),
+			})
+		if err != nil {
+			return err
+		}
+		s.decoder = dec
+		s.codec = frame.GetCodec()
+		s.channels = channels
+		s.decRate = int(frame.GetSamplingRate())
 	}
 
-	if pbr.decoder == nil {
-		return fmt.Errorf("no decoder set for audio frames from user: '%s'", txUser)
+	if s.decoder == nil {
+		return fmt.Errorf("no decoder set for audio frames from user: '%s'", s.userID)
 	}
 
-	num, err := pbr.decoder.Decode(msg.Data, buf)
+	buf := make([]float32, int(frame.GetFrameLength())*channels)
+
+	num, err := s.decoder.Decode(frame.Data, buf,
+		audiocodec.Options{Bitdepth: int(frame.GetBitDepth())})
 	if err != nil {
-		// in case the txUser has switched from stereo to mono
-		// the samples won't fit into buf anymore. Therefore we
-		// simple ignore the sample and delete the decoder for that user
-		delete(pbr.decoders, txUser)
-		pbr.lastUser = ""
+		s.decoder = nil
 		return err
 	}
 
+	s.frames = int(frame.GetFrameLength())
+	s.rate = float64(frame.GetSamplingRate())
+
+	// in case of PCM we might have to resample the audio
+	// to match the internally prefered 48kHz
+	if s.rate != 48000 {
+		buf, err = pbr.resample(s, buf[:num*channels])
+		if err != nil {
+			return err
+		}
+		num = len(buf) / channels
+		s.rate = 48000
+	}
+
+	pbr.emit(s, buf, num)
+
+	return nil
+}
+
+// resample converts the audio samples of a stream to 48kHz. The
+// samplerate converter is created on demand.
+func (pbr *PbReader) resample(s *stream, data []float32) ([]float32, error) {
+
+	if s.src == nil || s.src.channels != s.channels {
+		if s.src != nil {
+			gosamplerate.Delete(s.src.Src)
+			s.src = nil
+		}
+		srConv, err := gosamplerate.New(gosamplerate.SRC_SINC_FASTEST,
+			s.channels, 65536)
+		if err != nil {
+			return nil, fmt.Errorf("PbReader samplerate converter: %v", err)
+		}
+		s.src = &src{
+			Src:      srConv,
+			channels: s.channels,
+		}
+	}
+
+	if s.src.samplerate != s.rate {
+		s.src.Reset()
+		s.src.samplerate = s.rate
+		s.src.ratio = 48000 / s.rate
+	}
+
+	return s.src.Process(data, s.src.ratio, false)
+}
+
+// conceal fills the gap of a lost frame. If the subsequent frame is
+// already in the jitter buffer, the lost frame is reconstructed from its
+// forward error correction data. Otherwise a packet loss concealment is
+// performed. Nothing is done if the decoder doesn't support concealment.
+func (pbr *PbReader) conceal(s *stream) error {
+
+	c, ok := s.decoder.(audiocodec.Concealer)
+	if !ok || s.frames == 0 {
+		return nil
+	}
+
+	buf := make([]float32, s.frames*s.channels)
+
+	var num int
+	var err error
+
+	next, ok := s.jb.Peek()
+	frame, isFrame := next.Data.(*sbAudio.Frame)
+
+	if ok && isFrame && frame.GetCodec() == s.codec {
+		num, err = c.DecodeFEC(frame.Data, buf)
+	} else {
+		num, err = c.DecodePLC(buf)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to conceal lost audio frame from user '%s': %v", s.userID, err)
+	}
+
+	pbr.emit(s, buf, num)
+
+	return nil
+}
+
+// emit packs the decoded audio into an audio.Msg and passes it on
+// to the callback.
+func (pbr *PbReader) emit(s *stream, buf []float32, num int) {
+
 	// pack the data into an audio.Msg which is used for further internal
 	// processing
 	audioMsg := audio.Msg{
-		Channels:   channels,
+		Channels:   s.channels,
 		Data:       buf,
 		EOF:        false,
 		Frames:     num,
-		Samplerate: float64(msg.GetSamplingRate()), // we want 48kHz for internal processing
-		Metadata:   map[string]interface{}{"userID": msg.GetUserId()},
+		Samplerate: s.rate,
+		Metadata:   map[string]interface{}{"userID": s.userID},
 	}
 
-	pbr.callback(audioMsg)
+	pbr.RLock()
+	cb := pbr.callback
+	enabled := pbr.enabled
+	pbr.RUnlock()
 
-	return nil
-}
+	if !enabled || cb == nil {
+		return
+	}
 
-func newOpusDecoder(channels int) (*opus.OpusDecoder, error) {
-	decChannels := opus.Channels(channels)
-	decSR := opus.Samplerate(48000) // opus only likes 48kHz
-	dec, err := opus.NewOpusDecoder(decChannels, decSR)
+	cb(audioMsg)
+}
 
-	return dec, err
+// frameDuration returns the duration of the audio contained in the frame.
+func frameDuration(frame *sbAudio.Frame) time.Duration {
+	return time.Duration(frame.GetFrameLength()) * time.Second /
+		time.Duration(frame.GetSamplingRate())
 }
@@ -0,0 +1,70 @@
+package pcmReader
+
+const (
+	// DefaultFramesPerBuffer default amount of audio frames read per buffer
+	DefaultFramesPerBuffer int = 4096
+	// DefaultSamplerate of raw PCM files
+	DefaultSamplerate float64 = 48000
+	// DefaultChannels of raw PCM files
+	DefaultChannels int = 1
+)
+
+// sample encodings of raw PCM files
+const (
+	S16LE = "s16le" // signed 16 bit integer, little endian
+	F32LE = "f32le" // 32 bit float, little endian
+)
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options contains the parameters for initializing a pcm Reader.
+type Options struct {
+	FramesPerBuffer int
+	Samplerate      float64
+	Channels        int
+	Encoding        string
+	Loop            bool
+}
+
+// FramesPerBuffer is a functional option which sets the amount of audio frames
+// the pcmReader will provide when executing the callback.
+// Example: A buffer with 960 frames at 48kHz results in 20ms Audio.
+func FramesPerBuffer(s int) Option {
+	return func(args *Options) {
+		args.FramesPerBuffer = s
+	}
+}
+
+// Samplerate is a functional option to set the samplerate of the file.
+// Raw PCM files don't contain a header, so the samplerate has to be known.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// Channels is a functional option to set the amount of (interleaved)
+// channels of the file.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// Encoding is a functional option to set the encoding of the samples
+// (S16LE or F32LE). By default the samples are expected as S16LE.
+func Encoding(e string) Option {
+	return func(args *Options) {
+		args.Encoding = e
+	}
+}
+
+// Loop is a functional option which enables the looping of the file. When
+// the end of the file has been reached, the playback continues at the
+// beginning until the pcmReader is stopped.
+func Loop(enabled bool) Option {
+	return func(args *Options) {
+		args.Loop = enabled
+	}
+}
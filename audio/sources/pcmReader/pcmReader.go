@@ -0,0 +1,110 @@
+package pcmReader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/dh1tw/remoteAudio/audio/sources/player"
+)
+
+// PcmReader implements the audio.Source interface and is used to read
+// (play) audio frames from a headerless (raw) PCM file. The samplerate,
+// the amount of channels and the encoding of the samples have to be set
+// through the options. The audio is streamed from disk; the playback can
+// be paused, resumed, positioned (Seek) and looped.
+type PcmReader struct {
+	*player.Player
+}
+
+// decoder converts the samples of a raw PCM file.
+type decoder struct {
+	encoding       string
+	bytesPerSample int
+}
+
+// NewPcmReader opens a raw PCM file and returns a PcmReader object which
+// implements the audio.Source interface. The file stays open until
+// the PcmReader is closed.
+func NewPcmReader(file string, opts ...Option) (*PcmReader, error) {
+
+	options := Options{
+		FramesPerBuffer: DefaultFramesPerBuffer,
+		Samplerate:      DefaultSamplerate,
+		Channels:        DefaultChannels,
+		Encoding:        S16LE,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	r := &decoder{
+		encoding: strings.ToLower(options.Encoding),
+	}
+
+	switch r.encoding {
+	case S16LE:
+		r.bytesPerSample = 2
+	case F32LE:
+		r.bytesPerSample = 4
+	default:
+		return nil, fmt.Errorf("unsupported PCM encoding %s", options.Encoding)
+	}
+
+	if options.Channels < 1 || options.Samplerate < 1 {
+		return nil, errors.New("invalid amount of channels or samplerate")
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	bytesPerFrame := options.Channels * r.bytesPerSample
+	frames := info.Size() / int64(bytesPerFrame)
+	if frames == 0 {
+		f.Close()
+		return nil, errors.New("pcm file contains no audio")
+	}
+
+	pr := player.NewPCMReader(f, 0, frames, bytesPerFrame, r.decode)
+
+	p := player.New("pcmReader", pr, player.Format{
+		Samplerate: options.Samplerate,
+		Channels:   options.Channels,
+		Frames:     frames,
+	},
+		player.FramesPerBuffer(options.FramesPerBuffer),
+		player.Loop(options.Loop),
+	)
+
+	return &PcmReader{p}, nil
+}
+
+// decode converts the raw samples into float32 samples.
+func (r *decoder) decode(raw []byte) []float32 {
+
+	data := make([]float32, len(raw)/r.bytesPerSample)
+
+	for i := range data {
+		s := raw[i*r.bytesPerSample:]
+		switch r.encoding {
+		case F32LE:
+			data[i] = math.Float32frombits(binary.LittleEndian.Uint32(s))
+		default:
+			data[i] = float32(int16(binary.LittleEndian.Uint16(s))) / (1 << 15)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,38 @@
+package player
+
+import "time"
+
+const (
+	// DefaultFramesPerBuffer default amount of audio frames read per buffer
+	DefaultFramesPerBuffer int = 4096
+)
+
+// leadTime is the time by which the audio buffers are provided ahead of
+// their playout time
+const leadTime = time.Millisecond * 20
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options contains the parameters for initializing a Player.
+type Options struct {
+	FramesPerBuffer int
+	Loop            bool
+}
+
+// FramesPerBuffer is a functional option which sets the amount of audio
+// frames the Player will provide when executing the callback.
+func FramesPerBuffer(s int) Option {
+	return func(args *Options) {
+		args.FramesPerBuffer = s
+	}
+}
+
+// Loop is a functional option which enables the looping of the file. When
+// the end of the file has been reached, the playback continues at the
+// beginning until the Player is stopped.
+func Loop(enabled bool) Option {
+	return func(args *Options) {
+		args.Loop = enabled
+	}
+}
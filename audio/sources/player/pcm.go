@@ -0,0 +1,68 @@
+package player
+
+import "io"
+
+// File is the file from which a PCMReader reads the samples.
+type File interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// PCMReader implements the FrameReader interface for interleaved raw PCM
+// samples stored in one block of a file (e.g. the data chunk of a wav
+// file or a headerless PCM file). The conversion of the samples is left
+// to a decode function.
+type PCMReader struct {
+	file          File
+	data          *io.SectionReader // PCM data of the file
+	bytesPerFrame int
+	frames        int64 // total amount of frames in the file
+	pos           int64 // frame which will be read next
+	decode        func(raw []byte) []float32
+}
+
+// NewPCMReader returns a PCMReader for the given amount of frames which
+// are located at offset in f. decode converts the raw samples of complete
+// frames into float32 samples. f is closed together with the PCMReader.
+func NewPCMReader(f File, offset, frames int64, bytesPerFrame int,
+	decode func(raw []byte) []float32) *PCMReader {
+
+	return &PCMReader{
+		file:          f,
+		data:          io.NewSectionReader(f, offset, frames*int64(bytesPerFrame)),
+		bytesPerFrame: bytesPerFrame,
+		frames:        frames,
+		decode:        decode,
+	}
+}
+
+// ReadFrames reads the next frames from the file.
+func (r *PCMReader) ReadFrames(frames int) ([]float32, error) {
+
+	n := min(int64(frames), r.frames-r.pos)
+
+	raw := make([]byte, int(n)*r.bytesPerFrame)
+	read, err := r.data.ReadAt(raw, r.pos*int64(r.bytesPerFrame))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	read /= r.bytesPerFrame
+
+	r.pos += int64(read)
+	if r.pos >= r.frames || read < int(n) {
+		err = io.EOF
+	}
+
+	return r.decode(raw[:read*r.bytesPerFrame]), err
+}
+
+// SeekFrame positions the reader at the frame.
+func (r *PCMReader) SeekFrame(frame int64) error {
+	r.pos = frame
+	return nil
+}
+
+// Close closes the file.
+func (r *PCMReader) Close() error {
+	return r.file.Close()
+}
@@ -0,0 +1,70 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// pcmFile is an in-memory File.
+type pcmFile struct {
+	*bytes.Reader
+}
+
+func (f pcmFile) Close() error { return nil }
+
+func TestPCMReader(t *testing.T) {
+
+	// a 3 byte header, 5 stereo frames of 1 byte samples and a trailer
+	// which doesn't belong to the PCM data
+	raw := []byte{'h', 'd', 'r', 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 'x', 'y'}
+	decode := func(raw []byte) []float32 {
+		data := make([]float32, len(raw))
+		for i, b := range raw {
+			data[i] = float32(b)
+		}
+		return data
+	}
+
+	r := NewPCMReader(pcmFile{bytes.NewReader(raw)}, 3, 5, 2, decode)
+
+	tests := []struct {
+		name   string
+		seek   int64 // frame to seek to before reading; -1 to continue
+		frames int
+		data   []float32
+		eof    bool
+	}{
+		{name: "first frames", seek: -1, frames: 2, data: []float32{0, 1, 2, 3}},
+		{name: "continue", seek: -1, frames: 2, data: []float32{4, 5, 6, 7}},
+		{name: "end of data", seek: -1, frames: 2, data: []float32{8, 9}, eof: true},
+		{name: "seek", seek: 1, frames: 1, data: []float32{2, 3}},
+		{name: "seek to last frame", seek: 4, frames: 3, data: []float32{8, 9}, eof: true},
+		{name: "seek to start", seek: 0, frames: 5, data: []float32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, eof: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.seek >= 0 {
+				if err := r.SeekFrame(tc.seek); err != nil {
+					t.Fatal(err)
+				}
+			}
+			data, err := r.ReadFrames(tc.frames)
+			if tc.eof != (err == io.EOF) {
+				t.Fatalf("got error %v, expected EOF: %v", err, tc.eof)
+			}
+			if err != nil && err != io.EOF {
+				t.Fatal(err)
+			}
+			if len(data) != len(tc.data) {
+				t.Fatalf("got %v, expected %v", data, tc.data)
+			}
+			for i := range data {
+				if data[i] != tc.data[i] {
+					t.Fatalf("got %v, expected %v", data, tc.data)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,339 @@
+// Package player plays audio files in real time. The decoding of the
+// file formats is left to a FrameReader, so that the file sources
+// (e.g. wavReader or flacReader) only have to implement the decoding.
+// Raw PCM samples are read with a PCMReader.
+package player
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// FrameReader reads the audio frames of a file. The methods are only
+// called by the Player and never concurrently.
+type FrameReader interface {
+	// ReadFrames decodes up to the given amount of frames at the current
+	// position and returns the interleaved float32 samples. io.EOF is
+	// returned together with the last frames of the file.
+	ReadFrames(frames int) ([]float32, error)
+	// SeekFrame positions the reader at the given frame.
+	SeekFrame(frame int64) error
+	// Close closes the file.
+	Close() error
+}
+
+// Format describes the audio contained in a file.
+type Format struct {
+	Samplerate float64
+	Channels   int
+	Frames     int64 // total amount of frames; 0 if unknown
+}
+
+// Player implements the audio.Source interface and provides the audio
+// frames read by a FrameReader in real time. The playback can be
+// paused, resumed, positioned (Seek) and looped.
+type Player struct {
+	mu         sync.RWMutex
+	name       string
+	options    Options
+	reader     FrameReader
+	format     Format
+	pos        int64 // frame which will be played next
+	seeked     bool  // the position changed while playing
+	closed     bool
+	cb         audio.OnDataCb
+	isPlaying  bool
+	stopPlayCh chan struct{}
+}
+
+// New returns a Player which plays the frames read by r. The name is
+// used in log and error messages.
+func New(name string, r FrameReader, f Format, opts ...Option) *Player {
+
+	p := &Player{
+		name:   name,
+		reader: r,
+		format: f,
+		options: Options{
+			FramesPerBuffer: DefaultFramesPerBuffer,
+		},
+	}
+
+	for _, o := range opts {
+		o(&p.options)
+	}
+
+	return p
+}
+
+// Samplerate returns the samplerate of the file.
+func (p *Player) Samplerate() float64 {
+	return p.format.Samplerate
+}
+
+// Channels returns the amount of channels of the file.
+func (p *Player) Channels() int {
+	return p.format.Channels
+}
+
+// Duration returns the duration of the audio in the file. If the
+// duration is not known before the file has been played completely,
+// 0 is returned.
+func (p *Player) Duration() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.toDuration(p.format.Frames)
+}
+
+// Position returns the position of the playback.
+func (p *Player) Position() time.Duration {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.toDuration(p.pos)
+}
+
+// Seek sets the position of the playback. It can be called while the
+// file is being played.
+func (p *Player) Seek(pos time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return fmt.Errorf("%s is closed", p.name)
+	}
+
+	frames := p.format.Frames
+	if pos < 0 || (frames > 0 && pos > p.toDuration(frames)) {
+		return fmt.Errorf("position %v out of range", pos)
+	}
+
+	frame := int64(float64(pos) * p.format.Samplerate / float64(time.Second))
+	if frames > 0 && frame >= frames {
+		frame = 0
+	}
+
+	if err := p.reader.SeekFrame(frame); err != nil {
+		return err
+	}
+	p.pos = frame
+	p.seeked = true
+
+	return nil
+}
+
+// SetLoop enables or disables the looping of the file.
+func (p *Player) SetLoop(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.options.Loop = enabled
+}
+
+// Loop returns if the file is played in a loop.
+func (p *Player) Loop() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.options.Loop
+}
+
+// IsPlaying returns if the file is currently being played.
+func (p *Player) IsPlaying() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.isPlaying
+}
+
+// toDuration converts an amount of frames into a duration.
+func (p *Player) toDuration(frames int64) time.Duration {
+	return time.Duration(float64(frames) * float64(time.Second) / p.format.Samplerate)
+}
+
+// SetCb sets the callback which will be executed to provide audio buffers.
+func (p *Player) SetCb(cb audio.OnDataCb) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cb = cb
+}
+
+// Start will "play" the audio by providing audio buffers through the
+// set callback function. The playback starts at the current position;
+// after Pause it resumes where it has been paused.
+func (p *Player) Start() error {
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return fmt.Errorf("%s is closed", p.name)
+	}
+
+	if p.isPlaying {
+		return nil
+	}
+
+	p.stopPlayCh = make(chan struct{})
+	p.seeked = false
+
+	go p.play(p.stopPlayCh, p.cb)
+	p.isPlaying = true
+
+	return nil
+}
+
+// play provides the audio buffers in real time. The buffers are
+// scheduled relative to the start of the playback (or the last Seek) on
+// the monotonic clock, so that the timing doesn't drift. Each buffer is
+// provided shortly ahead of time to avoid underruns in the sink.
+func (p *Player) play(stopCh chan struct{}, cb audio.OnDataCb) {
+
+	if cb == nil {
+		log.Println(p.name, "callback not set")
+		p.mu.Lock()
+		p.stopped(stopCh)
+		p.mu.Unlock()
+		return
+	}
+
+	start := time.Now()
+	var elapsed time.Duration
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-timer.C:
+		}
+
+		p.mu.Lock()
+		if p.stopPlayCh != stopCh {
+			p.mu.Unlock()
+			return
+		}
+		if p.seeked {
+			start = time.Now()
+			elapsed = 0
+			p.seeked = false
+		}
+		msg, err := p.next()
+		if err != nil {
+			log.Printf("%s: %v", p.name, err)
+			p.stopped(stopCh)
+			p.mu.Unlock()
+			return
+		}
+		if msg.EOF {
+			p.stopped(stopCh)
+		}
+		p.mu.Unlock()
+
+		cb(msg)
+
+		if msg.EOF {
+			return
+		}
+
+		elapsed += time.Duration(float64(msg.Frames) * float64(time.Second) / msg.Samplerate)
+		timer.Reset(time.Until(start.Add(elapsed - leadTime)))
+	}
+}
+
+// stopped marks the end of the playback of stopCh. This method is not
+// safe for concurrent access.
+func (p *Player) stopped(stopCh chan struct{}) {
+	if p.stopPlayCh == stopCh {
+		p.isPlaying = false
+	}
+}
+
+// next reads the next buffer from the file. At the end of the file the
+// position returns to the beginning; without looping the buffer is
+// marked as EOF. This method is not safe for concurrent access.
+func (p *Player) next() (audio.Msg, error) {
+
+	data, err := p.reader.ReadFrames(p.options.FramesPerBuffer)
+	if err != nil && err != io.EOF {
+		return audio.Msg{}, err
+	}
+
+	msg := audio.Msg{
+		Data:       data,
+		Channels:   p.format.Channels,
+		Samplerate: p.format.Samplerate,
+		Frames:     len(data) / p.format.Channels,
+	}
+
+	p.pos += int64(msg.Frames)
+
+	if err == io.EOF {
+		if p.format.Frames == 0 {
+			p.format.Frames = p.pos
+		}
+		if err := p.reader.SeekFrame(0); err != nil {
+			return audio.Msg{}, err
+		}
+		p.pos = 0
+		msg.EOF = !p.options.Loop
+	}
+
+	return msg, nil
+}
+
+// Stop cancels sending audio through the callback. The next playback
+// starts at the beginning of the file.
+func (p *Player) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pause()
+
+	if p.closed || p.pos == 0 {
+		return nil
+	}
+	if err := p.reader.SeekFrame(0); err != nil {
+		return err
+	}
+	p.pos = 0
+
+	return nil
+}
+
+// Pause cancels sending audio through the callback. The playback can be
+// resumed at the same position with Start.
+func (p *Player) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pause()
+
+	return nil
+}
+
+// pause stops the playback. This method is not safe for concurrent access.
+func (p *Player) pause() {
+	if p.isPlaying {
+		close(p.stopPlayCh)
+	}
+	p.isPlaying = false
+}
+
+// Close stops the playback and closes the file.
+func (p *Player) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pause()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+
+	return p.reader.Close()
+}
@@ -0,0 +1,303 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+)
+
+// testReader is a mono FrameReader whose samples contain the index of
+// their frame.
+type testReader struct {
+	frames int64
+	pos    int64
+}
+
+func (r *testReader) ReadFrames(frames int) ([]float32, error) {
+	n := min(int64(frames), r.frames-r.pos)
+	data := make([]float32, n)
+	for i := range data {
+		data[i] = float32(r.pos + int64(i))
+	}
+	r.pos += n
+	if r.pos >= r.frames {
+		return data, io.EOF
+	}
+	return data, nil
+}
+
+func (r *testReader) SeekFrame(frame int64) error {
+	r.pos = frame
+	return nil
+}
+
+func (r *testReader) Close() error { return nil }
+
+// recording collects the buffers provided by a Player.
+type recording struct {
+	sync.Mutex
+	msgs []audio.Msg
+}
+
+func (r *recording) cb(msg audio.Msg) {
+	r.Lock()
+	defer r.Unlock()
+	r.msgs = append(r.msgs, msg)
+}
+
+// buffers returns a copy of the buffers received so far.
+func (r *recording) buffers() []audio.Msg {
+	r.Lock()
+	defer r.Unlock()
+	return append([]audio.Msg{}, r.msgs...)
+}
+
+// wait waits until at least n buffers have been received.
+func (r *recording) wait(t *testing.T, n int) []audio.Msg {
+	t.Helper()
+	timeout := time.After(time.Second * 2)
+	for {
+		if msgs := r.buffers(); len(msgs) >= n {
+			return msgs
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timeout while waiting for %d buffers", n)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+// samples returns the concatenated samples of the buffers.
+func samples(msgs []audio.Msg) []float32 {
+	data := []float32{}
+	for _, msg := range msgs {
+		data = append(data, msg.Data...)
+	}
+	return data
+}
+
+// newTestPlayer returns a Player for a mono file with the given amount
+// of frames @48kHz, which provides buffers of 10ms.
+func newTestPlayer(frames int64, opts ...Option) (*Player, *recording) {
+	opts = append([]Option{FramesPerBuffer(480)}, opts...)
+	p := New("test", &testReader{frames: frames}, Format{
+		Samplerate: 48000,
+		Channels:   1,
+		Frames:     frames,
+	}, opts...)
+	rec := &recording{}
+	p.SetCb(rec.cb)
+	return p, rec
+}
+
+// quiesce waits until the buffer which might be in flight after
+// Pause / Stop has been delivered.
+func quiesce() {
+	time.Sleep(time.Millisecond * 20)
+}
+
+func TestSeek(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		pos   time.Duration
+		frame float32 // first frame played after the seek
+		err   bool
+	}{
+		{name: "start", pos: 0, frame: 0},
+		{name: "middle", pos: time.Millisecond * 500, frame: 24000},
+		{name: "end wraps to start", pos: time.Second, frame: 0},
+		{name: "negative", pos: -time.Millisecond, err: true},
+		{name: "beyond end", pos: time.Second + time.Millisecond, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, rec := newTestPlayer(48000)
+			defer p.Close()
+
+			err := p.Seek(tc.pos)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if p.Position() != 0 {
+					t.Errorf("position changed to %v", p.Position())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := p.Start(); err != nil {
+				t.Fatal(err)
+			}
+			msgs := rec.wait(t, 1)
+			if msgs[0].Data[0] != tc.frame {
+				t.Errorf("playback started at frame %v, expected %v", msgs[0].Data[0], tc.frame)
+			}
+		})
+	}
+}
+
+func TestSeekWhilePlaying(t *testing.T) {
+
+	p, rec := newTestPlayer(48000)
+	defer p.Close()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	n := len(rec.wait(t, 3))
+
+	if err := p.Seek(time.Millisecond * 750); err != nil {
+		t.Fatal(err)
+	}
+	msgs := rec.wait(t, n+3)
+
+	// a buffer read before the seek may still be in flight
+	for _, msg := range msgs[n:] {
+		if msg.Data[0] == 36000 {
+			return
+		}
+	}
+	t.Errorf("playback didn't continue at frame 36000")
+}
+
+func TestPauseResume(t *testing.T) {
+
+	p, rec := newTestPlayer(48000)
+	defer p.Close()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, 5)
+
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	quiesce()
+	if p.IsPlaying() {
+		t.Error("still playing after pause")
+	}
+
+	msgs := rec.buffers()
+	played := len(samples(msgs))
+	if pos := p.Position(); pos != time.Duration(played)*time.Second/48000 {
+		t.Errorf("position %v after %d frames", pos, played)
+	}
+
+	// no further buffers while paused
+	quiesce()
+	if len(rec.buffers()) != len(msgs) {
+		t.Error("buffers received while paused")
+	}
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	msgs = rec.wait(t, len(msgs)+3)
+
+	// the playback resumes where it has been paused
+	for i, s := range samples(msgs) {
+		if s != float32(i) {
+			t.Fatalf("sample %d is frame %v", i, s)
+		}
+	}
+}
+
+func TestStopRewinds(t *testing.T) {
+
+	p, rec := newTestPlayer(48000)
+	defer p.Close()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, 3)
+
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	quiesce()
+	if p.Position() != 0 {
+		t.Errorf("position %v after stop", p.Position())
+	}
+
+	n := len(rec.buffers())
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	msgs := rec.wait(t, n+1)
+	if msgs[n].Data[0] != 0 {
+		t.Errorf("playback started at frame %v after stop", msgs[n].Data[0])
+	}
+}
+
+func TestLoop(t *testing.T) {
+
+	// the last buffer of each pass contains only 40 frames
+	p, rec := newTestPlayer(1000, Loop(true))
+	defer p.Close()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	msgs := rec.wait(t, 8)
+
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, msg := range msgs {
+		if msg.EOF {
+			t.Fatalf("buffer %d marked as EOF while looping", i)
+		}
+		if msg.Frames != len(msg.Data) {
+			t.Fatalf("buffer %d contains %d frames, but %d samples", i, msg.Frames, len(msg.Data))
+		}
+	}
+	if msgs[2].Frames != 40 {
+		t.Errorf("last buffer of the first pass contains %d frames", msgs[2].Frames)
+	}
+
+	// the playback wraps around to the beginning
+	for i, s := range samples(msgs) {
+		if s != float32(i%1000) {
+			t.Fatalf("sample %d is frame %v", i, s)
+		}
+	}
+}
+
+func TestEOF(t *testing.T) {
+
+	p, rec := newTestPlayer(1000)
+	defer p.Close()
+
+	if err := p.Start(); err != nil {
+		t.Fatal(err)
+	}
+	msgs := rec.wait(t, 3)
+	quiesce()
+
+	if n := len(rec.buffers()); n != 3 {
+		t.Fatalf("received %d buffers, expected 3", n)
+	}
+	for i, msg := range msgs {
+		if msg.EOF != (i == 2) {
+			t.Errorf("buffer %d: EOF %v", i, msg.EOF)
+		}
+	}
+	if p.IsPlaying() {
+		t.Error("still playing after the end of the file")
+	}
+	if p.Position() != 0 {
+		t.Errorf("position %v after the end of the file", p.Position())
+	}
+}
@@ -11,6 +11,7 @@ type Option func(*Options)
 // Options contains the parameters for initializing a wav Reader.
 type Options struct {
 	FramesPerBuffer int
+	Loop            bool
 }
 
 // FramesPerBuffer is a functional option which sets the amount of audio frames
@@ -22,3 +23,12 @@ func FramesPerBuffer(s int) Option {
 		args.FramesPerBuffer = s
 	}
 }
+
+// Loop is a functional option which enables the looping of the file. When
+// the end of the file has been reached, the playback continues at the
+// beginning until the wavReader is stopped.
+func Loop(enabled bool) Option {
+	return func(args *Options) {
+		args.Loop = enabled
+	}
+}
@@ -1,37 +1,65 @@
 package wavReader
 
 import (
+	"encoding/binary"
 	"errors"
-	"log"
+	"fmt"
+	"io"
+	"math"
 	"os"
-	"sync"
-	"time"
 
-	"github.com/dh1tw/remoteAudio/audio"
-	ga "github.com/go-audio/audio"
+	"github.com/dh1tw/remoteAudio/audio/sources/player"
 	wav "github.com/go-audio/wav"
 )
 
+// wav formats (see the fmt chunk)
+const (
+	formatPCM        = 1
+	formatFloat      = 3
+	formatExtensible = 0xFFFE
+)
+
+// the subformat GUID of WAVE_FORMAT_EXTENSIBLE starts with the format
+// code, followed by this fixed suffix
+var guidSuffix = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00,
+	0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
 // WavReader implements the audio.Source interface and is used to read (play)
-// audio frames from a wav source (e.g. file).
+// audio frames from a wav file. The audio is streamed from disk, so that
+// long files don't have to be loaded into memory. The playback can be
+// paused, resumed, positioned (Seek) and looped.
 type WavReader struct {
-	sync.RWMutex
-	options    Options
-	buffer     []audio.Msg
-	cb         audio.OnDataCb
-	isPlaying  bool
-	stopPlayCh chan struct{}
+	*player.Player
 }
 
-// NewWavReader reads a wav file from disk into memory and returns a
-// WavReader object which implements the audio.Source interface.
+// decoder converts the samples of a wav file.
+type decoder struct {
+	format         uint16
+	channels       int
+	samplerate     int
+	bytesPerSample int
+}
+
+// NewWavReader opens a wav file and returns a WavReader object which
+// implements the audio.Source interface. The file stays open until
+// the WavReader is closed.
 func NewWavReader(file string, opts ...Option) (*WavReader, error) {
 
 	f, err := os.Open(file)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+
+	w, err := newWavReader(f, opts...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func newWavReader(f *os.File, opts ...Option) (*WavReader, error) {
 
 	dec := wav.NewDecoder(f)
 
@@ -39,119 +67,163 @@ func NewWavReader(file string, opts ...Option) (*WavReader, error) {
 		return nil, errors.New("invalid WAV file")
 	}
 
-	w := WavReader{
-		buffer: []audio.Msg{},
-		options: Options{
-			FramesPerBuffer: DefaultFramesPerBuffer,
-		},
+	// IsValidFile has consumed the headers; start over to locate the
+	// PCM data
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	dec = wav.NewDecoder(f)
+	if err := dec.FwdToPCM(); err != nil {
+		return nil, err
+	}
+	if err := dec.Err(); err != nil {
+		return nil, err
 	}
 
-	for _, o := range opts {
-		o(&w.options)
+	start, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
 	}
 
-	buf := &ga.IntBuffer{
-		Data:   make([]int, w.options.FramesPerBuffer),
-		Format: dec.Format(),
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &decoder{
+		format:         dec.WavAudioFormat,
+		channels:       int(dec.NumChans),
+		samplerate:     int(dec.SampleRate),
+		bytesPerSample: int(dec.BitDepth) / 8,
 	}
 
-	for err == nil {
-		n, err := dec.PCMBuffer(buf)
+	options := Options{
+		FramesPerBuffer: DefaultFramesPerBuffer,
+	}
+
+	for _, o := range opts {
+		o(&options)
+	}
+
+	// the decoder doesn't expose the subformat of extensible files
+	if w.format == formatExtensible {
+		w.format, err = subFormat(f)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		if n == 0 {
-			break
-		}
+	if err := w.checkFormat(int(dec.BitDepth)); err != nil {
+		return nil, err
+	}
 
-		if n != len(buf.Data) {
-			buf.Data = buf.Data[:n]
-		}
+	// the size in the header is not reliable if the file hasn't been
+	// completed (e.g. a recording which is still running)
+	size := int64(dec.PCMSize)
+	if size <= 0 || start+size > info.Size() {
+		size = info.Size() - start
+	}
 
-		msg := audio.Msg{
-			Data:       buf.AsFloat32Buffer().Data,
-			Channels:   buf.Format.NumChannels,
-			Samplerate: float64(buf.Format.SampleRate),
-			Frames:     n,
-		}
-		w.buffer = append(w.buffer, msg)
+	bytesPerFrame := w.channels * w.bytesPerSample
+	frames := size / int64(bytesPerFrame)
+	if frames == 0 {
+		return nil, errors.New("wav file contains no audio")
 	}
 
-	w.buffer[len(w.buffer)-1].EOF = true
+	r := player.NewPCMReader(f, start, frames, bytesPerFrame, w.decode)
 
-	return &w, nil
-}
+	p := player.New("wavReader", r, player.Format{
+		Samplerate: float64(w.samplerate),
+		Channels:   w.channels,
+		Frames:     frames,
+	},
+		player.FramesPerBuffer(options.FramesPerBuffer),
+		player.Loop(options.Loop),
+	)
 
-// SetCb sets the callback which will be executed to provide audio buffers.
-func (w *WavReader) SetCb(cb audio.OnDataCb) {
-	w.Lock()
-	defer w.Unlock()
-	w.cb = cb
+	return &WavReader{p}, nil
 }
 
-// Start will "play" the audio by providing audio buffers through the
-// set callback function.
-func (w *WavReader) Start() error {
-
-	w.Lock()
-	defer w.Unlock()
-
-	if w.isPlaying {
-		return nil
+// checkFormat verifies that the audio format of the file is supported.
+func (w *decoder) checkFormat(bitDepth int) error {
+	if w.channels < 1 || w.samplerate < 1 {
+		return errors.New("invalid WAV header")
 	}
 
-	w.stopPlayCh = make(chan struct{})
-
-	go w.play(w.buffer, w.stopPlayCh, w.cb)
-	w.isPlaying = true
+	switch w.format {
+	case formatPCM:
+		switch bitDepth {
+		case 8, 16, 24, 32:
+			return nil
+		}
+	case formatFloat:
+		if bitDepth == 32 {
+			return nil
+		}
+	}
 
-	return nil
+	return fmt.Errorf("unsupported WAV format (%d, %d bit)", w.format, bitDepth)
 }
 
-func (w *WavReader) play(audioMsgs []audio.Msg, stopCh chan struct{}, cb audio.OnDataCb) {
+// subFormat returns the format code (e.g. formatPCM or formatFloat) from
+// the subformat GUID in the fmt chunk of a WAVE_FORMAT_EXTENSIBLE file.
+func subFormat(f io.ReaderAt) (uint16, error) {
 
-	if cb == nil {
-		log.Println("wavReader callback not set")
-		return
-	}
+	// skip the RIFF header ("RIFF", size, "WAVE")
+	offset := int64(12)
+	hdr := make([]byte, 8)
 
-	for _, msg := range audioMsgs {
-
-		// calculate duration in milliseconds of one frame. If they are
-		// stereo the channels are interleaved. The duration is shorted
-		// by 5ms to avoid empty buffers.
-		duration := (msg.Frames/msg.Channels)/int(msg.Samplerate/1000) - 5
+	for {
+		if _, err := f.ReadAt(hdr, offset); err != nil {
+			return 0, errors.New("fmt chunk not found")
+		}
+		size := int64(binary.LittleEndian.Uint32(hdr[4:]))
+		offset += 8
 
-		select {
-		case <-stopCh:
-			return
-		case <-time.After(time.Duration(duration) * time.Millisecond):
-			cb(msg)
+		if string(hdr[:4]) != "fmt " {
+			// chunks are padded to an even size
+			offset += size + size%2
+			continue
 		}
 
+		// the fmt chunk of an extensible file is 40 bytes long; the
+		// subformat GUID is located at the end
+		if size < 40 {
+			return 0, errors.New("invalid WAVE_FORMAT_EXTENSIBLE fmt chunk")
+		}
+		guid := make([]byte, 16)
+		if _, err := f.ReadAt(guid, offset+24); err != nil {
+			return 0, err
+		}
+		if string(guid[2:]) != string(guidSuffix) {
+			return 0, errors.New("unsupported WAVE_FORMAT_EXTENSIBLE subformat")
+		}
+		return binary.LittleEndian.Uint16(guid), nil
 	}
-	w.Lock()
-	defer w.Unlock()
-	// close(stopCh)
-	w.isPlaying = false
 }
 
-// Stop cancels sending audio through the callback.
-func (w *WavReader) Stop() error {
-	w.Lock()
-	defer w.Unlock()
-
-	if w.isPlaying {
-		// fmt.Println("is still playing")
-		close(w.stopPlayCh)
+// decode converts the raw samples into float32 samples in the
+// range of -1...1.
+func (w *decoder) decode(raw []byte) []float32 {
+
+	data := make([]float32, len(raw)/w.bytesPerSample)
+
+	for i := range data {
+		s := raw[i*w.bytesPerSample:]
+		switch {
+		case w.format == formatFloat:
+			data[i] = math.Float32frombits(binary.LittleEndian.Uint32(s))
+		case w.bytesPerSample == 1:
+			data[i] = (float32(s[0]) - 128) / 128
+		case w.bytesPerSample == 2:
+			data[i] = float32(int16(binary.LittleEndian.Uint16(s))) / (1 << 15)
+		case w.bytesPerSample == 3:
+			v := int32(s[0]) | int32(s[1])<<8 | int32(int8(s[2]))<<16
+			data[i] = float32(v) / (1 << 23)
+		default:
+			data[i] = float32(int32(binary.LittleEndian.Uint32(s))) / (1 << 31)
+		}
 	}
-	w.isPlaying = false
-
-	return nil
-}
 
-// Close shutsdown the wav player
-func (w *WavReader) Close() error {
-	return nil
+	return data
 }
@@ -0,0 +1,42 @@
+package wsReader
+
+import (
+	"github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options is the data structure holding the optional values. The
+// values are typically set by calling the functional options.
+type Options struct {
+	Decoder    audiocodec.Decoder
+	Samplerate float64
+	Channels   int
+}
+
+// Decoder is a functional option to set the decoder for the binary audio
+// messages received from the websocket clients. By default 16 bit PCM is
+// expected.
+func Decoder(dec audiocodec.Decoder) Option {
+	return func(args *Options) {
+		args.Decoder = dec
+	}
+}
+
+// Samplerate is a functional option to set the sampling rate of the
+// audio received from the websocket clients. By default 48kHz is expected.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// Channels is a functional option to set the amount of channels of the
+// audio received from the websocket clients. By default 1 channel is
+// expected.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
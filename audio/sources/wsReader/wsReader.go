@@ -0,0 +1,102 @@
+package wsReader
+
+import (
+	"sync"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audiocodec/pcm"
+)
+
+// WsReader implements the audio.Source interface. It decodes the binary
+// audio messages received from websocket clients (e.g. the microphone
+// of a browser) into audio.Msg.
+type WsReader struct {
+	sync.RWMutex
+	options Options
+	enabled bool
+	cb      audio.OnDataCb
+}
+
+// NewWsReader is the constructor for a WsReader.
+func NewWsReader(opts ...Option) (*WsReader, error) {
+
+	r := &WsReader{
+		options: Options{
+			Samplerate: 48000,
+			Channels:   1,
+		},
+	}
+
+	for _, option := range opts {
+		option(&r.options)
+	}
+
+	if r.options.Decoder == nil {
+		dec, err := pcm.NewDecoder(pcm.Channels(r.options.Channels))
+		if err != nil {
+			return nil, err
+		}
+		r.options.Decoder = dec
+	}
+
+	return r, nil
+}
+
+// Start processing the audio messages
+func (r *WsReader) Start() error {
+	r.Lock()
+	defer r.Unlock()
+	r.enabled = true
+	return nil
+}
+
+// Stop processing the audio messages
+func (r *WsReader) Stop() error {
+	r.Lock()
+	defer r.Unlock()
+	r.enabled = false
+	return nil
+}
+
+// Close shuts down the WsReader
+func (r *WsReader) Close() error {
+	return nil
+}
+
+// SetCb sets the callback which get's executed once an audio message
+// has been decoded into an audio.Msg.
+func (r *WsReader) SetCb(cb audio.OnDataCb) {
+	r.Lock()
+	defer r.Unlock()
+	r.cb = cb
+}
+
+// Enqueue decodes a binary audio message received from a websocket
+// client and passes it on to the callback.
+func (r *WsReader) Enqueue(data []byte) error {
+	r.RLock()
+	defer r.RUnlock()
+
+	if !r.enabled || r.cb == nil || len(data) == 0 {
+		return nil
+	}
+
+	// worst case: 4 bit samples (ADPCM)
+	buf := make([]float32, len(data)*2)
+
+	num, err := r.options.Decoder.Decode(data, buf)
+	if err != nil {
+		return err
+	}
+
+	msg := audio.Msg{
+		Data:       buf[:num*r.options.Channels],
+		Samplerate: r.options.Samplerate,
+		Channels:   r.options.Channels,
+		Frames:     num,
+	}
+
+	r.cb(msg)
+
+	return nil
+}
@@ -0,0 +1,123 @@
+// Package adpcm implements the IMA ADPCM audio codec. It compresses 16 bit
+// audio samples into 4 bits per sample. Each encoded frame starts with a
+// header containing the predictor state of each channel, so that frames
+// can be decoded independently from each other (e.g. after packet loss).
+//
+// Frame layout:
+//
+//	per channel: predictor (int16, little endian), step index (uint8), reserved (uint8)
+//	followed by the interleaved samples, 2 samples per byte (low nibble first)
+package adpcm
+
+// headerSize is the size of the frame header per channel
+const headerSize = 4
+
+var indexTable = [16]int{
+	-1, -1, -1, -1, 2, 4, 6, 8,
+	-1, -1, -1, -1, 2, 4, 6, 8,
+}
+
+var stepTable = [89]int{
+	7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+	19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+	50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+	130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+	337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+	876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+	2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+	5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+	15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+}
+
+// state is the predictor state of a single channel
+type state struct {
+	predictor int
+	index     int
+}
+
+// encode converts a 16 bit sample into a 4 bit ADPCM code and updates
+// the predictor state.
+func (s *state) encode(sample int16) byte {
+	step := stepTable[s.index]
+	diff := int(sample) - s.predictor
+
+	var code byte
+	if diff < 0 {
+		code = 8
+		diff = -diff
+	}
+
+	// quantize the difference; the result is the same as the
+	// reconstruction done by the decoder
+	delta := step >> 3
+	if diff >= step {
+		code |= 4
+		diff -= step
+		delta += step
+	}
+	step >>= 1
+	if diff >= step {
+		code |= 2
+		diff -= step
+		delta += step
+	}
+	step >>= 1
+	if diff >= step {
+		code |= 1
+		delta += step
+	}
+
+	s.update(code, delta)
+
+	return code
+}
+
+// decode converts a 4 bit ADPCM code into a 16 bit sample and updates
+// the predictor state.
+func (s *state) decode(code byte) int16 {
+	step := stepTable[s.index]
+
+	delta := step >> 3
+	if code&4 != 0 {
+		delta += step
+	}
+	if code&2 != 0 {
+		delta += step >> 1
+	}
+	if code&1 != 0 {
+		delta += step >> 2
+	}
+
+	s.update(code, delta)
+
+	return int16(s.predictor)
+}
+
+// update applies the quantized difference to the predictor and adapts
+// the step size.
+func (s *state) update(code byte, delta int) {
+	if code&8 != 0 {
+		s.predictor -= delta
+	} else {
+		s.predictor += delta
+	}
+
+	if s.predictor > 32767 {
+		s.predictor = 32767
+	} else if s.predictor < -32768 {
+		s.predictor = -32768
+	}
+
+	s.index += indexTable[code&0x0f]
+	if s.index < 0 {
+		s.index = 0
+	} else if s.index > len(stepTable)-1 {
+		s.index = len(stepTable) - 1
+	}
+}
+
+// frameSize returns the size in bytes of an encoded frame containing the
+// given amount of samples (all channels).
+func frameSize(samples, channels int) int {
+	return headerSize*channels + (samples+1)/2
+}
@@ -0,0 +1,105 @@
+package adpcm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		channels int
+		frames   int // samples per channel and frame
+		minSNR   float64
+	}{
+		{name: "mono", channels: 1, frames: 960, minSNR: 20},
+		{name: "stereo", channels: 2, frames: 960, minSNR: 20},
+		{name: "mono odd frame length", channels: 1, frames: 441, minSNR: 20},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := NewEncoder(Channels(tc.channels), Samplerate(48000))
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := NewDecoder(Channels(tc.channels), Samplerate(48000))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var signal, noise float64
+			n := 0
+
+			for frame := 0; frame < 5; frame++ {
+				in := make([]float32, tc.frames*tc.channels)
+				for i := range in {
+					// a different tone on each channel
+					freq := 440.0 * float64(1+i%tc.channels)
+					x := float64(n+i/tc.channels) / 48000
+					in[i] = float32(0.5 * math.Sin(2*math.Pi*freq*x))
+				}
+				n += tc.frames
+
+				data := make([]byte, frameSize(len(in), tc.channels))
+				num, err := enc.Encode(in, data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if num != len(data) {
+					t.Fatalf("encoded %d bytes, expected %d", num, len(data))
+				}
+
+				out := make([]float32, len(in))
+				frames, err := dec.Decode(data[:num], out)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if frames != tc.frames {
+					t.Fatalf("decoded %d frames, expected %d", frames, tc.frames)
+				}
+
+				// the predictor needs some samples to adapt its step
+				// size; skip the first frame
+				if frame == 0 {
+					continue
+				}
+				for i := range in {
+					signal += float64(in[i]) * float64(in[i])
+					d := float64(in[i] - out[i])
+					noise += d * d
+				}
+			}
+
+			snr := 10 * math.Log10(signal/noise)
+			if snr < tc.minSNR {
+				t.Errorf("SNR %.1fdB below %.1fdB", snr, tc.minSNR)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalidFrame(t *testing.T) {
+
+	dec, err := NewDecoder(Channels(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "too short", data: []byte{0, 0, 0, 0}},
+		{name: "invalid step index", data: []byte{0, 0, 89, 0, 0, 0, 0, 0, 0xff}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := dec.Decode(tc.data, make([]float32, 16)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
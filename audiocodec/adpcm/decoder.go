@@ -0,0 +1,88 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Decoder is the data structure which holds internal values
+// for the ADPCM decoder.
+type Decoder struct {
+	name    string
+	options Options
+}
+
+// NewDecoder is the constructor method for an ADPCM decoder.
+func NewDecoder(opts ...Option) (*Decoder, error) {
+
+	dec := &Decoder{
+		name: "adpcm",
+		options: Options{
+			Samplerate: 8000,
+			Channels:   1,
+		},
+	}
+
+	for _, option := range opts {
+		option(&dec.options)
+	}
+
+	if dec.options.Channels < 1 || dec.options.Channels > 2 {
+		return nil, fmt.Errorf("adpcm: unsupported amount of channels %d", dec.options.Channels)
+	}
+
+	return dec, nil
+}
+
+// Name returns the name of the audio codec
+func (dec *Decoder) Name() string {
+	return dec.name
+}
+
+// Options returns a copy of the codec's options
+func (dec *Decoder) Options() Options {
+	return dec.options
+}
+
+// Decode ADPCM data into the supplied float32 buffer. The length of the
+// buffer determines the amount of samples which will be decoded, since
+// the last byte of a frame might contain only one sample. On success,
+// the number of samples (per channel) written into the buffer will be
+// returned.
+func (dec *Decoder) Decode(data []byte, pcm []float32, opts ...ac.Options) (int, error) {
+
+	chs := dec.options.Channels
+
+	if len(data) < headerSize*chs {
+		return 0, fmt.Errorf("adpcm decoder: frame too short (%d bytes)", len(data))
+	}
+
+	states := make([]state, chs)
+	for ch := 0; ch < chs; ch++ {
+		states[ch].predictor = int(int16(binary.LittleEndian.Uint16(data[ch*headerSize:])))
+		states[ch].index = int(data[ch*headerSize+2])
+		if states[ch].index >= len(stepTable) {
+			return 0, fmt.Errorf("adpcm decoder: invalid step index %d", states[ch].index)
+		}
+	}
+
+	body := data[headerSize*chs:]
+
+	samples := len(body) * 2
+	if samples > len(pcm) {
+		samples = len(pcm)
+	}
+	samples -= samples % chs
+
+	for i := 0; i < samples; i++ {
+		code := body[i/2] & 0x0f
+		if i%2 != 0 {
+			code = body[i/2] >> 4
+		}
+		pcm[i] = float32(states[i%chs].decode(code)) / 32768
+	}
+
+	return samples / chs, nil
+}
@@ -0,0 +1,126 @@
+package adpcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Encoder is the data structure for the IMA ADPCM encoder. The predictor
+// state is kept between subsequent frames.
+type Encoder struct {
+	name    string
+	options Options
+	states  []state
+}
+
+// NewEncoder is the constructor method for an ADPCM encoder.
+func NewEncoder(opts ...Option) (*Encoder, error) {
+
+	enc := &Encoder{
+		name: "adpcm",
+		options: Options{
+			Samplerate: 8000,
+			Channels:   1,
+		},
+	}
+
+	for _, option := range opts {
+		option(&enc.options)
+	}
+
+	if enc.options.Channels < 1 || enc.options.Channels > 2 {
+		return nil, fmt.Errorf("adpcm: unsupported amount of channels %d", enc.options.Channels)
+	}
+
+	enc.states = make([]state, enc.options.Channels)
+
+	return enc, nil
+}
+
+// Name returns the name of the audio codec
+func (enc *Encoder) Name() string {
+	return enc.name
+}
+
+// Options returns a copy of the codec's options
+func (enc *Encoder) Options() Options {
+	return enc.options
+}
+
+// Format returns the format of the audio samples which are expected
+// by the encoder.
+func (enc *Encoder) Format() ac.Options {
+	return ac.Options{
+		Samplerate: int(enc.options.Samplerate),
+		Channels:   enc.options.Channels,
+		Bitdepth:   4,
+	}
+}
+
+// Encode either []float32 or []int16 (interleaved) into the supplied
+// buffer. On success the amount of bytes written into the buffer will
+// be returned.
+func (enc *Encoder) Encode(pcm interface{}, data []byte) (int, error) {
+
+	var samples []int16
+
+	switch v := pcm.(type) {
+	case []float32:
+		samples = make([]int16, len(v))
+		for i, sample := range v {
+			samples[i] = floatToInt16(sample)
+		}
+	case []int16:
+		samples = v
+	default:
+		return 0, fmt.Errorf("can not encode type %v with adpcm codec", v)
+	}
+
+	chs := enc.options.Channels
+
+	size := frameSize(len(samples), chs)
+	if len(data) < size {
+		return 0, fmt.Errorf("adpcm encoder: buffer too small (%d < %d bytes)", len(data), size)
+	}
+
+	// the header contains the predictor state at the beginning of
+	// the frame, so that the decoder doesn't depend on previous frames
+	for ch := 0; ch < chs; ch++ {
+		s := enc.states[ch]
+		binary.LittleEndian.PutUint16(data[ch*headerSize:], uint16(int16(s.predictor)))
+		data[ch*headerSize+2] = byte(s.index)
+		data[ch*headerSize+3] = 0
+	}
+
+	body := data[headerSize*chs : size]
+	for i := range body {
+		body[i] = 0
+	}
+
+	for i, sample := range samples {
+		code := enc.states[i%chs].encode(sample)
+		if i%2 == 0 {
+			body[i/2] = code
+		} else {
+			body[i/2] |= code << 4
+		}
+	}
+
+	return size, nil
+}
+
+// floatToInt16 converts a float32 sample (-1...1) into a 16 bit integer.
+// Samples exceeding the range will be clipped.
+func floatToInt16(sample float32) int16 {
+	s := sample * 32768
+	if s > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if s < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(s)
+}
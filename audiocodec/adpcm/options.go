@@ -0,0 +1,31 @@
+package adpcm
+
+// Option is a type used for functional options
+type Option func(*Options)
+
+// Options is a data structure which is passed into an ADPCM encoder or
+// decoder. The struct is filled through functional options.
+type Options struct {
+	Name       string
+	Samplerate float64
+	Channels   int
+}
+
+// Channels is a functional option to set the amount of channels.
+// Typically this is either Mono (1) or Stereo (2). By default 1 channel
+// is used.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// Samplerate is a functional option to set the sampling rate of the
+// audio samples. ADPCM encodes each sample with 4 bits, so the bitrate
+// is determined by the samplerate (8kHz => 32kbit/s). By default
+// 8kHz is used, which is sufficient for voice on HF.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
@@ -0,0 +1,32 @@
+package adpcm
+
+import ac "github.com/dh1tw/remoteAudio/audiocodec"
+
+func init() {
+	ac.Register(ac.Codec{
+		Name:        "adpcm",
+		Priority:    20, // less bandwidth than pcm, but lower quality than opus
+		Samplerates: []int{8000, 11025, 16000, 22050, 24000, 32000, 44100, 48000},
+		BitDepths:   []int{4},
+		Channels:    []int{1, 2},
+		NewEncoder: func(o ac.Options) (ac.Encoder, error) {
+			return NewEncoder(codecOptions(o)...)
+		},
+		NewDecoder: func(o ac.Options) (ac.Decoder, error) {
+			return NewDecoder(codecOptions(o)...)
+		},
+	})
+}
+
+// codecOptions converts the generic audiocodec options into
+// functional options.
+func codecOptions(o ac.Options) []Option {
+	opts := []Option{}
+	if o.Samplerate > 0 {
+		opts = append(opts, Samplerate(float64(o.Samplerate)))
+	}
+	if o.Channels > 0 {
+		opts = append(opts, Channels(o.Channels))
+	}
+	return opts
+}
@@ -1,5 +1,7 @@
 package audiocodec
 
+import "time"
+
 // Encoder is the interface which an audio encoder has to implement.
 type Encoder interface {
 	Name() string
@@ -12,6 +14,18 @@ type Decoder interface {
 	Decode([]byte, []float32, ...Options) (int, error) //float32 output
 }
 
+// Concealer is the interface which can be implemented by a Decoder in
+// case it is able to conceal lost audio frames.
+type Concealer interface {
+	// DecodePLC synthesizes an audio frame to fill the gap of a lost
+	// frame (packet loss concealment). The length of the provided float32
+	// buffer determines the duration of the concealed audio.
+	DecodePLC([]float32) (int, error)
+	// DecodeFEC reconstructs a lost frame from the forward error correction
+	// data embedded in the subsequent frame.
+	DecodeFEC([]byte, []float32) (int, error)
+}
+
 // Options is a struct which can be provided to the Decoder for particular
 // Audio samples. This is useful in case default values shall be overwritten.
 // However the decoder implementation has to support it, of course.
@@ -20,3 +34,17 @@ type Options struct {
 	Channels   int
 	Bitdepth   int
 }
+
+// Feedback contains the reception statistics of an audio stream as
+// reported by the receiver(s).
+type Feedback struct {
+	Loss   float64       // fraction of lost frames (0...1)
+	Jitter time.Duration // inter-arrival jitter
+}
+
+// Adapter is the interface which can be implemented by an Encoder in
+// case it is able to adapt its output (e.g. the bitrate) to the network
+// conditions reported by the receivers.
+type Adapter interface {
+	Adapt(Feedback) error
+}
@@ -0,0 +1,111 @@
+package opus
+
+import (
+	"time"
+
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
+	opus "gopkg.in/hraban/opus.v2"
+)
+
+const (
+	// above these values the bitrate will be decreased
+	highLoss   = 0.05
+	highJitter = time.Millisecond * 60
+	// below these values the bitrate will be increased
+	lowLoss   = 0.01
+	lowJitter = time.Millisecond * 20
+)
+
+// Adapt adjusts the bitrate, complexity and max bandwidth of an adaptive
+// encoder to the network conditions reported by the receivers. On high
+// packet loss or jitter the bitrate is reduced multiplicatively, on a
+// good connection it is slowly increased again (AIMD). This method has
+// no effect if the encoder is not adaptive.
+func (oEnc *OpusEncoder) Adapt(fb ac.Feedback) error {
+
+	if !oEnc.options.Adaptive {
+		return nil
+	}
+
+	o := &oEnc.options
+
+	bitrate := o.Bitrate
+	switch {
+	case fb.Loss > highLoss || fb.Jitter > highJitter:
+		bitrate = bitrate * 3 / 4
+	case fb.Loss < lowLoss && fb.Jitter < lowJitter:
+		bitrate += o.MaxBitrate / 10
+	}
+
+	if bitrate < o.MinBitrate {
+		bitrate = o.MinBitrate
+	}
+	if bitrate > o.MaxBitrate {
+		bitrate = o.MaxBitrate
+	}
+
+	// the lower the bitrate, the more computational effort we spend
+	// to maintain the audio quality
+	complexity := o.MaxComplexity
+	if o.MaxBitrate > o.MinBitrate {
+		complexity = o.MinComplexity + (o.MaxComplexity-o.MinComplexity)*
+			(o.MaxBitrate-bitrate)/(o.MaxBitrate-o.MinBitrate)
+	}
+
+	bandwidth := bandwidthForBitrate(bitrate)
+	if bandwidth > o.MaxBandwidth {
+		bandwidth = o.MaxBandwidth
+	}
+
+	// let opus know how much redundancy (FEC) is needed
+	packetLoss := int(fb.Loss * 100)
+	if packetLoss > 100 {
+		packetLoss = 100
+	}
+
+	if bitrate != o.Bitrate {
+		if err := oEnc.encoder.SetBitrate(bitrate); err != nil {
+			return err
+		}
+		o.Bitrate = bitrate
+	}
+
+	if complexity != o.Complexity {
+		if err := oEnc.encoder.SetComplexity(complexity); err != nil {
+			return err
+		}
+		o.Complexity = complexity
+	}
+
+	if bandwidth != oEnc.bandwidth {
+		if err := oEnc.encoder.SetMaxBandwidth(bandwidth); err != nil {
+			return err
+		}
+		oEnc.bandwidth = bandwidth
+	}
+
+	if o.InBandFEC && packetLoss != o.PacketLoss {
+		if err := oEnc.encoder.SetPacketLossPerc(packetLoss); err != nil {
+			return err
+		}
+		o.PacketLoss = packetLoss
+	}
+
+	return nil
+}
+
+// bandwidthForBitrate returns the bandwidth which is reasonable for
+// a given bitrate (see https://wiki.xiph.org/Opus_Recommended_Settings).
+func bandwidthForBitrate(bitrate int) opus.Bandwidth {
+	switch {
+	case bitrate < 12000:
+		return opus.Narrowband
+	case bitrate < 15000:
+		return opus.Mediumband
+	case bitrate < 20000:
+		return opus.Wideband
+	case bitrate < 28000:
+		return opus.SuperWideband
+	}
+	return opus.Fullband
+}
@@ -54,3 +54,27 @@ func (oc *OpusDecoder) Options() Options {
 func (oc *OpusDecoder) Decode(data []byte, pcm []float32, opts ...ac.Options) (int, error) {
 	return oc.decoder.DecodeFloat32(data, pcm)
 }
+
+// DecodePLC performs a packet loss concealment. The synthesized audio is
+// written into the supplied float32 buffer, whose length must match the
+// duration of the lost frame. On success, the number of samples (per
+// channel) written into the buffer will be returned.
+func (oc *OpusDecoder) DecodePLC(pcm []float32) (int, error) {
+	if err := oc.decoder.DecodePLCFloat32(pcm); err != nil {
+		return 0, err
+	}
+	return len(pcm) / oc.options.Channels, nil
+}
+
+// DecodeFEC reconstructs a lost frame from the in-band forward error
+// correction data contained in the subsequent (encoded) frame. The length
+// of the supplied float32 buffer must match the duration of the lost frame.
+// If the encoder didn't embed FEC data, a packet loss concealment will be
+// performed instead. On success, the number of samples (per channel)
+// written into the buffer will be returned.
+func (oc *OpusDecoder) DecodeFEC(data []byte, pcm []float32) (int, error) {
+	if err := oc.decoder.DecodeFECFloat32(data, pcm); err != nil {
+		return 0, err
+	}
+	return len(pcm) / oc.options.Channels, nil
+}
@@ -3,6 +3,7 @@ package opus
 import (
 	"fmt"
 
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
 	opus "gopkg.in/hraban/opus.v2"
 )
 
@@ -13,6 +14,7 @@ type OpusEncoder struct {
 	options     Options
 	encoder     *opus.Encoder
 	application opus.Application
+	bandwidth   opus.Bandwidth // currently used max bandwidth (adaptive mode)
 }
 
 // NewEncoder is the constructor method for an Opus encoder.
@@ -34,6 +36,22 @@ func NewEncoder(opts ...Option) (*OpusEncoder, error) {
 		option(&oEnc.options)
 	}
 
+	if oEnc.options.MinBitrate == 0 {
+		oEnc.options.MinBitrate = 6000
+	}
+	// leave room for an adaptive encoder to increase the bitrate
+	if oEnc.options.MaxBitrate == 0 {
+		oEnc.options.MaxBitrate = 64000
+		if oEnc.options.Bitrate > oEnc.options.MaxBitrate {
+			oEnc.options.MaxBitrate = oEnc.options.Bitrate
+		}
+	}
+	if oEnc.options.MinComplexity == 0 && oEnc.options.MaxComplexity == 0 {
+		oEnc.options.MinComplexity = oEnc.options.Complexity
+		oEnc.options.MaxComplexity = oEnc.options.Complexity
+	}
+	oEnc.bandwidth = oEnc.options.MaxBandwidth
+
 	encoder, err := opus.NewEncoder(int(oEnc.options.Samplerate),
 		oEnc.options.Channels,
 		oEnc.options.Application)
@@ -54,6 +72,14 @@ func NewEncoder(opts ...Option) (*OpusEncoder, error) {
 		return nil, err
 	}
 
+	if err := encoder.SetInBandFEC(oEnc.options.InBandFEC); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.SetPacketLossPerc(oEnc.options.PacketLoss); err != nil {
+		return nil, err
+	}
+
 	oEnc.encoder = encoder
 	return oEnc, nil
 }
@@ -68,6 +94,16 @@ func (oEnc *OpusEncoder) Options() Options {
 	return oEnc.options
 }
 
+// Format returns the format of the audio samples which are expected
+// by the encoder.
+func (oEnc *OpusEncoder) Format() ac.Options {
+	return ac.Options{
+		Samplerate: int(oEnc.options.Samplerate),
+		Channels:   oEnc.options.Channels,
+		Bitdepth:   16,
+	}
+}
+
 // Encode either []float32 or []int16 with the opus codec into the supplied
 // buffer. On success the amount of bytes written into the buffer will be returned.
 func (oEnc *OpusEncoder) Encode(pcm interface{}, data []byte) (int, error) {
@@ -8,13 +8,20 @@ type Option func(*Options)
 // Options is a data structure which is passed into an opus encoder or decoder.
 // The struct is filled through functional options.
 type Options struct {
-	Name         string
-	Samplerate   float64
-	Channels     int
-	Bitrate      int
-	MaxBandwidth opus.Bandwidth
-	Application  opus.Application
-	Complexity   int
+	Name          string
+	Samplerate    float64
+	Channels      int
+	Bitrate       int
+	MaxBandwidth  opus.Bandwidth
+	Application   opus.Application
+	Complexity    int
+	InBandFEC     bool
+	PacketLoss    int
+	Adaptive      bool
+	MinBitrate    int
+	MaxBitrate    int
+	MinComplexity int
+	MaxComplexity int
 }
 
 // Channels is a functional option to set the amount of channels to be used
@@ -70,3 +77,56 @@ func Complexity(c int) Option {
 		args.Complexity = c
 	}
 }
+
+// InBandFEC is a functional option to enable the in-band forward error
+// correction of the opus encoder. With FEC enabled, each frame contains
+// a low bitrate copy of the previous frame, so that a single lost frame
+// can be reconstructed by the receiver. FEC is only used by the encoder
+// if the expected packet loss is > 0. By default FEC is disabled.
+func InBandFEC(enabled bool) Option {
+	return func(args *Options) {
+		args.InBandFEC = enabled
+	}
+}
+
+// PacketLoss is a functional option to set the expected packet loss
+// (in percent) of the network link. The opus encoder uses this value to
+// determine how much redundancy it adds to the frames. The default value
+// is 0.
+func PacketLoss(perc int) Option {
+	return func(args *Options) {
+		args.PacketLoss = perc
+	}
+}
+
+// Adaptive is a functional option to enable the adaptation of the
+// encoder to the network conditions reported by the receivers. The
+// bitrate, complexity and max bandwidth will then be adjusted within
+// the bounds set by BitrateRange, ComplexityRange and MaxBandwidth.
+// By default the encoder is not adaptive.
+func Adaptive(enabled bool) Option {
+	return func(args *Options) {
+		args.Adaptive = enabled
+	}
+}
+
+// BitrateRange is a functional option to set the bounds within which an
+// adaptive encoder can adjust its bitrate. By default the range is
+// 6kbit/s up to 64kbit/s (or the configured bitrate, if higher).
+func BitrateRange(min, max int) Option {
+	return func(args *Options) {
+		args.MinBitrate = min
+		args.MaxBitrate = max
+	}
+}
+
+// ComplexityRange is a functional option to set the bounds within which
+// an adaptive encoder can adjust its computational complexity. Lower
+// bitrates are compensated with a higher complexity. By default the
+// configured complexity is used.
+func ComplexityRange(min, max int) Option {
+	return func(args *Options) {
+		args.MinComplexity = min
+		args.MaxComplexity = max
+	}
+}
@@ -0,0 +1,32 @@
+package opus
+
+import ac "github.com/dh1tw/remoteAudio/audiocodec"
+
+func init() {
+	ac.Register(ac.Codec{
+		Name:        "opus",
+		Priority:    100,
+		Samplerates: []int{48000}, // opus only likes 48kHz
+		BitDepths:   []int{16},
+		Channels:    []int{1, 2},
+		NewEncoder: func(o ac.Options) (ac.Encoder, error) {
+			return NewEncoder(codecOptions(o)...)
+		},
+		NewDecoder: func(o ac.Options) (ac.Decoder, error) {
+			return NewOpusDecoder(codecOptions(o)...)
+		},
+	})
+}
+
+// codecOptions converts the generic audiocodec options into
+// functional options.
+func codecOptions(o ac.Options) []Option {
+	opts := []Option{}
+	if o.Samplerate > 0 {
+		opts = append(opts, Samplerate(float64(o.Samplerate)))
+	}
+	if o.Channels > 0 {
+		opts = append(opts, Channels(o.Channels))
+	}
+	return opts
+}
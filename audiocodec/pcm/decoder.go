@@ -0,0 +1,89 @@
+package pcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Decoder is the data structure which holds internal values
+// for the PCM decoder.
+type Decoder struct {
+	name    string
+	options Options
+}
+
+// NewDecoder is the constructor method for a PCM decoder.
+func NewDecoder(opts ...Option) (*Decoder, error) {
+
+	dec := &Decoder{
+		name: "pcm",
+		options: Options{
+			Samplerate: 48000,
+			Channels:   1,
+			BitDepth:   16,
+		},
+	}
+
+	for _, option := range opts {
+		option(&dec.options)
+	}
+
+	if err := checkBitDepth(dec.options.BitDepth); err != nil {
+		return nil, err
+	}
+
+	return dec, nil
+}
+
+// Name returns the name of the audio codec
+func (dec *Decoder) Name() string {
+	return dec.name
+}
+
+// Options returns a copy of the codec's options
+func (dec *Decoder) Options() Options {
+	return dec.options
+}
+
+// Decode PCM data into the supplied float32 buffer. The bit depth of
+// the data can be overwritten through the (optional) audiocodec.Options.
+// On success, the number of samples (per channel) written into the
+// buffer will be returned.
+func (dec *Decoder) Decode(data []byte, pcm []float32, opts ...ac.Options) (int, error) {
+
+	bitDepth := dec.options.BitDepth
+	channels := dec.options.Channels
+
+	for _, o := range opts {
+		if o.Bitdepth > 0 {
+			bitDepth = o.Bitdepth
+		}
+		if o.Channels > 0 {
+			channels = o.Channels
+		}
+	}
+
+	if err := checkBitDepth(bitDepth); err != nil {
+		return 0, err
+	}
+
+	bytesPerSample := bitDepth / 8
+	samples := len(data) / bytesPerSample
+
+	if len(pcm) < samples {
+		return 0, fmt.Errorf("pcm decoder: buffer too small (%d < %d samples)", len(pcm), samples)
+	}
+
+	for i := 0; i < samples; i++ {
+		if bitDepth == 32 {
+			pcm[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+			continue
+		}
+		pcm[i] = float32(int16(binary.LittleEndian.Uint16(data[i*2:]))) / 32768
+	}
+
+	return samples / channels, nil
+}
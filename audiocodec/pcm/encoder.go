@@ -0,0 +1,117 @@
+package pcm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	ac "github.com/dh1tw/remoteAudio/audiocodec"
+)
+
+// Encoder is the data structure for the PCM encoder. It converts audio
+// samples into a raw little endian byte representation.
+type Encoder struct {
+	name    string
+	options Options
+}
+
+// NewEncoder is the constructor method for a PCM encoder.
+func NewEncoder(opts ...Option) (*Encoder, error) {
+
+	enc := &Encoder{
+		name: "pcm",
+		options: Options{
+			Samplerate: 48000,
+			Channels:   1,
+			BitDepth:   16,
+		},
+	}
+
+	for _, option := range opts {
+		option(&enc.options)
+	}
+
+	if err := checkBitDepth(enc.options.BitDepth); err != nil {
+		return nil, err
+	}
+
+	return enc, nil
+}
+
+// Name returns the name of the audio codec
+func (enc *Encoder) Name() string {
+	return enc.name
+}
+
+// Options returns a copy of the codec's options
+func (enc *Encoder) Options() Options {
+	return enc.options
+}
+
+// Format returns the format of the encoded audio samples.
+func (enc *Encoder) Format() ac.Options {
+	return ac.Options{
+		Samplerate: int(enc.options.Samplerate),
+		Channels:   enc.options.Channels,
+		Bitdepth:   enc.options.BitDepth,
+	}
+}
+
+// Encode either []float32 or []int16 into the supplied buffer. On success
+// the amount of bytes written into the buffer will be returned.
+func (enc *Encoder) Encode(pcm interface{}, data []byte) (int, error) {
+
+	switch v := pcm.(type) {
+	case []float32:
+		size := len(v) * enc.options.BitDepth / 8
+		if len(data) < size {
+			return 0, fmt.Errorf("pcm encoder: buffer too small (%d < %d bytes)", len(data), size)
+		}
+		for i, sample := range v {
+			if enc.options.BitDepth == 32 {
+				binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(sample))
+				continue
+			}
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(floatToInt16(sample)))
+		}
+		return size, nil
+
+	case []int16:
+		size := len(v) * enc.options.BitDepth / 8
+		if len(data) < size {
+			return 0, fmt.Errorf("pcm encoder: buffer too small (%d < %d bytes)", len(data), size)
+		}
+		for i, sample := range v {
+			if enc.options.BitDepth == 32 {
+				binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(float32(sample)/32768))
+				continue
+			}
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+		}
+		return size, nil
+
+	default:
+		return 0, fmt.Errorf("can not encode type %v with pcm codec", v)
+	}
+}
+
+// floatToInt16 converts a float32 sample (-1...1) into a 16 bit integer.
+// Samples exceeding the range will be clipped.
+func floatToInt16(sample float32) int16 {
+	s := sample * 32768
+	if s > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if s < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(s)
+}
+
+func checkBitDepth(b int) error {
+	switch b {
+	case 16, 32:
+		return nil
+	}
+	return fmt.Errorf("pcm: unsupported bit depth %d (allowed: 16, 32)", b)
+}
@@ -0,0 +1,42 @@
+package pcm
+
+// Option is a type used for functional options
+type Option func(*Options)
+
+// Options is a data structure which is passed into a PCM encoder or decoder.
+// The struct is filled through functional options.
+type Options struct {
+	Name       string
+	Samplerate float64
+	Channels   int
+	BitDepth   int
+}
+
+// Channels is a functional option to set the amount of channels.
+// Typically this is either Mono (1) or Stereo (2). By default 1 channel
+// is used.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// Samplerate is a functional option to set the sampling rate of the
+// audio samples. PCM doesn't care about the samplerate, however it is
+// transmitted along with the audio samples so that the receiver can
+// resample the audio if necessary. By default 48kHz is used.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// BitDepth is a functional option to set the format of the encoded
+// audio samples. 16 bit results in signed integer samples and 32 bit in
+// IEEE-754 float samples. Both are encoded in little endian. By default
+// 16 bit is used.
+func BitDepth(b int) Option {
+	return func(args *Options) {
+		args.BitDepth = b
+	}
+}
@@ -0,0 +1,35 @@
+package pcm
+
+import ac "github.com/dh1tw/remoteAudio/audiocodec"
+
+func init() {
+	ac.Register(ac.Codec{
+		Name:        "pcm",
+		Priority:    10, // lossless, but requires a lot of bandwidth
+		Samplerates: []int{8000, 12000, 16000, 24000, 32000, 44100, 48000},
+		BitDepths:   []int{16, 32},
+		Channels:    []int{1, 2},
+		NewEncoder: func(o ac.Options) (ac.Encoder, error) {
+			return NewEncoder(codecOptions(o)...)
+		},
+		NewDecoder: func(o ac.Options) (ac.Decoder, error) {
+			return NewDecoder(codecOptions(o)...)
+		},
+	})
+}
+
+// codecOptions converts the generic audiocodec options into
+// functional options.
+func codecOptions(o ac.Options) []Option {
+	opts := []Option{}
+	if o.Samplerate > 0 {
+		opts = append(opts, Samplerate(float64(o.Samplerate)))
+	}
+	if o.Channels > 0 {
+		opts = append(opts, Channels(o.Channels))
+	}
+	if o.Bitdepth > 0 {
+		opts = append(opts, BitDepth(o.Bitdepth))
+	}
+	return opts
+}
@@ -0,0 +1,175 @@
+package audiocodec
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EncoderFactory is a function which creates an Encoder with the given
+// parameters. Parameters which are zero should be replaced by the
+// codec's default values.
+type EncoderFactory func(Options) (Encoder, error)
+
+// DecoderFactory is a function which creates a Decoder with the given
+// parameters. Parameters which are zero should be replaced by the
+// codec's default values.
+type DecoderFactory func(Options) (Decoder, error)
+
+// Codec describes an audio codec which has been registered in the
+// codec registry.
+type Codec struct {
+	Name        string
+	Priority    int   // codecs with a higher priority are prefered during negotiation
+	Samplerates []int // supported sampling rates
+	BitDepths   []int // supported bit depths
+	Channels    []int // supported amount of channels
+	NewEncoder  EncoderFactory
+	NewDecoder  DecoderFactory
+}
+
+// FormatDescriber is the interface which can be implemented by an Encoder
+// to describe the format (samplerate, channels and bit depth) of the
+// audio it expects and encodes.
+type FormatDescriber interface {
+	Format() Options
+}
+
+var registry = struct {
+	sync.RWMutex
+	codecs map[string]Codec
+}{
+	codecs: make(map[string]Codec),
+}
+
+// Register adds a codec to the registry. Typically the codec packages
+// register themselves in their init() function. Registering a codec
+// with a name which already exists will replace the existing codec.
+func Register(c Codec) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.codecs[c.Name] = c
+}
+
+// Lookup returns the codec registered under the given name.
+func Lookup(name string) (Codec, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	c, ok := registry.codecs[name]
+	return c, ok
+}
+
+// Codecs returns all registered codecs, ordered by their priority
+// (highest first).
+func Codecs() []Codec {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	codecs := make([]Codec, 0, len(registry.codecs))
+	for _, c := range registry.codecs {
+		codecs = append(codecs, c)
+	}
+
+	sort.Slice(codecs, func(i, j int) bool {
+		if codecs[i].Priority != codecs[j].Priority {
+			return codecs[i].Priority > codecs[j].Priority
+		}
+		return codecs[i].Name < codecs[j].Name
+	})
+
+	return codecs
+}
+
+// Names returns the names of all registered codecs, ordered by their
+// priority (highest first).
+func Names() []string {
+	names := []string{}
+	for _, c := range Codecs() {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// NewEncoder creates an encoder of the codec registered under the
+// given name.
+func NewEncoder(name string, opts Options) (Encoder, error) {
+	c, ok := Lookup(name)
+	if !ok || c.NewEncoder == nil {
+		return nil, fmt.Errorf("no encoder registered for codec '%s'", name)
+	}
+	return c.NewEncoder(opts)
+}
+
+// NewDecoder creates a decoder of the codec registered under the
+// given name.
+func NewDecoder(name string, opts Options) (Decoder, error) {
+	c, ok := Lookup(name)
+	if !ok || c.NewDecoder == nil {
+		return nil, fmt.Errorf("no decoder registered for codec '%s'", name)
+	}
+	return c.NewDecoder(opts)
+}
+
+// Parameters describes the audio formats which are supported for a
+// codec, e.g. as advertised by a remote audio server. Empty lists
+// don't restrict the format.
+type Parameters struct {
+	Name        string
+	Samplerates []int
+	BitDepths   []int
+	Channels    []int
+}
+
+// Parameters returns the audio formats supported by the codec.
+func (c Codec) Parameters() Parameters {
+	return Parameters{
+		Name:        c.Name,
+		Samplerates: c.Samplerates,
+		BitDepths:   c.BitDepths,
+		Channels:    c.Channels,
+	}
+}
+
+// Supports checks if audio in the given format can be processed.
+// Values of the format which are zero are not checked.
+func (p Parameters) Supports(f Options) bool {
+	return contains(p.Samplerates, f.Samplerate) &&
+		contains(p.BitDepths, f.Bitdepth) &&
+		contains(p.Channels, f.Channels)
+}
+
+// contains checks if the value is in the list. An empty list or a
+// zero value always match.
+func contains(list []int, value int) bool {
+	if len(list) == 0 || value == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Format is a codec together with the format of the audio which it
+// encodes.
+type Format struct {
+	Name string
+	Options
+}
+
+// Negotiate returns the first format of the preferred list whose codec
+// is supported by the remote side with the same sampling rate, bit depth
+// and amount of channels. An error is returned if there is no format
+// in common.
+func Negotiate(preferred []Format, remote []Parameters) (Format, error) {
+	for _, p := range preferred {
+		for _, r := range remote {
+			if p.Name == r.Name && r.Supports(p.Options) {
+				return p, nil
+			}
+		}
+	}
+	return Format{}, fmt.Errorf("no common audio codec (local: %v, remote: %v)", preferred, remote)
+}
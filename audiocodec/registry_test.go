@@ -0,0 +1,51 @@
+package audiocodec
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+
+	opus := Format{Name: "opus", Options: Options{Samplerate: 48000, Channels: 1}}
+	pcm16 := Format{Name: "pcm", Options: Options{Samplerate: 16000, Channels: 1, Bitdepth: 16}}
+	pcm32 := Format{Name: "pcm", Options: Options{Samplerate: 48000, Channels: 1, Bitdepth: 32}}
+
+	server := []Parameters{
+		{Name: "opus", Samplerates: []int{48000}, BitDepths: []int{16}, Channels: []int{1, 2}},
+		{Name: "pcm", Samplerates: []int{8000, 48000}, BitDepths: []int{16}, Channels: []int{1, 2}},
+	}
+
+	tests := []struct {
+		name      string
+		preferred []Format
+		remote    []Parameters
+		expected  Format
+		err       bool
+	}{
+		{name: "first preferred", preferred: []Format{opus, pcm16}, remote: server, expected: opus},
+		{name: "order of preference", preferred: []Format{pcm32, pcm16, opus}, remote: server, expected: opus},
+		{name: "unsupported samplerate", preferred: []Format{pcm16}, remote: server, err: true},
+		{name: "unsupported bit depth", preferred: []Format{pcm32}, remote: server, err: true},
+		{name: "unsupported channels", preferred: []Format{{Name: "opus", Options: Options{Samplerate: 48000, Channels: 6}}}, remote: server, err: true},
+		{name: "unknown codec", preferred: []Format{{Name: "mp3"}}, remote: server, err: true},
+		{name: "unrestricted parameters", preferred: []Format{pcm32}, remote: []Parameters{{Name: "pcm"}}, expected: pcm32},
+		{name: "unspecified format", preferred: []Format{{Name: "pcm"}}, remote: server, expected: Format{Name: "pcm"}},
+		{name: "no remote codecs", preferred: []Format{opus}, remote: nil, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := Negotiate(tc.preferred, tc.remote)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", f)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f != tc.expected {
+				t.Errorf("got %+v, expected %+v", f, tc.expected)
+			}
+		})
+	}
+}
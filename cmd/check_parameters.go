@@ -4,7 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/dh1tw/remoteAudio/audio/fft"
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+	"github.com/dh1tw/remoteAudio/audiocodec"
 	"github.com/spf13/viper"
 	"gopkg.in/hraban/opus.v2"
 )
@@ -55,8 +59,68 @@ func checkAudioParameterValues() error {
 		}
 	}
 
+	if viper.GetInt("opus.packet-loss") < 0 || viper.GetInt("opus.packet-loss") > 100 {
+		return &parmError{
+			parm: "opus.packet-loss",
+			msg:  "allowed values are [0...100]",
+		}
+	}
+
+	minBitrate := viper.GetInt("opus.min-bitrate")
+	maxBitrate := viper.GetInt("opus.max-bitrate")
+	if minBitrate < 6000 || maxBitrate > 510000 || minBitrate > maxBitrate {
+		return &parmError{
+			parm: "opus.min-bitrate / opus.max-bitrate",
+			msg:  "allowed values are [6000...510000] and min-bitrate <= max-bitrate",
+		}
+	}
+
+	if mc := viper.GetInt("opus.max-complexity"); mc < viper.GetInt("opus.complexity") || mc > 10 {
+		return &parmError{
+			parm: "opus.max-complexity",
+			msg:  "allowed values are [opus.complexity...10]",
+		}
+	}
+
+	audioCodec := strings.ToLower(viper.GetString("audio.codec"))
+	if _, ok := audiocodec.Lookup(audioCodec); !ok {
+		return &parmError{
+			parm: "audio.codec",
+			msg:  fmt.Sprintf("allowed values are %s", strings.ToUpper(strings.Join(audiocodec.Names(), ", "))),
+		}
+	}
+
+	if bd := viper.GetInt("pcm.bitdepth"); bd != 16 && bd != 32 {
+		return &parmError{
+			parm: "pcm.bitdepth",
+			msg:  "allowed values are [16 (integer), 32 (float)]",
+		}
+	}
+
+	if viper.GetInt("pcm.samplerate") < 8000 || viper.GetInt("pcm.samplerate") > 48000 {
+		return &parmError{
+			parm: "pcm.samplerate",
+			msg:  "allowed values are [8000...48000]",
+		}
+	}
+
+	if viper.GetInt("adpcm.samplerate") < 8000 || viper.GetInt("adpcm.samplerate") > 48000 {
+		return &parmError{
+			parm: "adpcm.samplerate",
+			msg:  "allowed values are [8000...48000]",
+		}
+	}
+
+	if viper.GetInt("audio.frame-length") <= 0 {
+		return &parmError{
+			parm: "audio.frame-length",
+			msg:  "value must be > 0",
+		}
+	}
+
 	opusFrameLength := float64(viper.GetInt("audio.frame-length")) / 48000
-	if opusFrameLength != 0.0025 &&
+	if audioCodec == "opus" &&
+		opusFrameLength != 0.0025 &&
 		opusFrameLength != 0.005 &&
 		opusFrameLength != 0.01 &&
 		opusFrameLength != 0.02 &&
@@ -69,6 +133,13 @@ result in 2.5, 5, 10, 20, 40, 60ms for the opus codec`,
 		}
 	}
 
+	if _, err := getRxProfiles(viper.GetStringSlice("audio.rx-profiles")); err != nil {
+		return &parmError{
+			parm: "audio.rx-profiles",
+			msg:  "allowed values are HIGH, MEDIUM, LOW",
+		}
+	}
+
 	if viper.GetInt("audio.rx-buffer-length") <= 0 {
 		return &parmError{
 			parm: "audio.rx-buffer-length",
@@ -76,6 +147,153 @@ result in 2.5, 5, 10, 20, 40, 60ms for the opus codec`,
 		}
 	}
 
+	if g := viper.GetFloat64("audio.anti-vox-gain"); g < 0 || g > 10 {
+		return &parmError{
+			parm: "audio.anti-vox-gain",
+			msg:  "allowed values are [0...10]",
+		}
+	}
+
+	if t := viper.GetFloat64("agc.target"); t <= 0 || t > 1 {
+		return &parmError{
+			parm: "agc.target",
+			msg:  "allowed values are (0...1]",
+		}
+	}
+
+	if viper.GetFloat64("agc.max-gain") < 1 {
+		return &parmError{
+			parm: "agc.max-gain",
+			msg:  "value must be >= 1",
+		}
+	}
+
+	if viper.GetDuration("agc.attack") < 0 ||
+		viper.GetDuration("agc.decay") < 0 ||
+		viper.GetDuration("agc.hang-time") < 0 {
+		return &parmError{
+			parm: "agc.attack / agc.decay / agc.hang-time",
+			msg:  "values must be >= 0",
+		}
+	}
+
+	for _, key := range []string{"eq.rx-sections", "eq.tx-sections"} {
+		if _, err := getEQSections(key); err != nil {
+			return &parmError{
+				parm: key,
+				msg:  err.Error(),
+			}
+		}
+	}
+
+	if s := viper.GetFloat64("nr.strength"); s < 0 || s > 1 {
+		return &parmError{
+			parm: "nr.strength",
+			msg:  "allowed values are [0...1]",
+		}
+	}
+
+	if f := viper.GetFloat64("notch.frequency"); f < 50 || f > 10000 {
+		return &parmError{
+			parm: "notch.frequency",
+			msg:  "allowed values are [50...10000]",
+		}
+	}
+
+	if q := viper.GetFloat64("notch.q"); q <= 0 || q > 100 {
+		return &parmError{
+			parm: "notch.q",
+			msg:  "allowed values are (0...100]",
+		}
+	}
+
+	if s := viper.GetInt("analyser.size"); !fft.IsPowerOfTwo(s) || s < 256 || s > 16384 {
+		return &parmError{
+			parm: "analyser.size",
+			msg:  "allowed values are powers of two within [256...16384]",
+		}
+	}
+
+	if r := viper.GetFloat64("analyser.rate"); r < 1 || r > 50 {
+		return &parmError{
+			parm: "analyser.rate",
+			msg:  "allowed values are [1...50]",
+		}
+	}
+
+	if t := viper.GetDuration("aec.tail-length"); t < time.Millisecond*10 || t > time.Second {
+		return &parmError{
+			parm: "aec.tail-length",
+			msg:  "allowed values are [10ms...1s]",
+		}
+	}
+
+	if mu := viper.GetFloat64("aec.mu"); mu <= 0 || mu > 1 {
+		return &parmError{
+			parm: "aec.mu",
+			msg:  "allowed values are (0...1]",
+		}
+	}
+
+	switch strings.ToLower(viper.GetString("recorder.format")) {
+	case "wav", "flac", "opus":
+	default:
+		return &parmError{
+			parm: "recorder.format",
+			msg:  "allowed values are 'wav', 'flac' or 'opus'",
+		}
+	}
+
+	if s := viper.GetInt("recorder.max-size"); s < 0 || s > 4000 {
+		return &parmError{
+			parm: "recorder.max-size",
+			msg:  "allowed values are [0...4000] MB",
+		}
+	}
+
+	if d := viper.GetDuration("recorder.max-duration"); d < 0 {
+		return &parmError{
+			parm: "recorder.max-duration",
+			msg:  "must not be negative",
+		}
+	}
+
+	if t := viper.GetFloat64("compressor.threshold"); t < -60 || t > 0 {
+		return &parmError{
+			parm: "compressor.threshold",
+			msg:  "allowed values are [-60...0]",
+		}
+	}
+
+	if r := viper.GetFloat64("compressor.ratio"); r < 1 || r > 20 {
+		return &parmError{
+			parm: "compressor.ratio",
+			msg:  "allowed values are [1...20]",
+		}
+	}
+
+	if g := viper.GetFloat64("compressor.makeup-gain"); g < 0 || g > 30 {
+		return &parmError{
+			parm: "compressor.makeup-gain",
+			msg:  "allowed values are [0...30]",
+		}
+	}
+
+	if l := viper.GetFloat64("compressor.limit"); l < -30 || l > 0 {
+		return &parmError{
+			parm: "compressor.limit",
+			msg:  "allowed values are [-30...0]",
+		}
+	}
+
+	if viper.GetDuration("compressor.attack") < 0 ||
+		viper.GetDuration("compressor.release") < 0 {
+		return &parmError{
+			parm: "compressor.attack / compressor.release",
+			msg:  "values must be >= 0",
+		}
+	}
+
 	return nil
 }
 
@@ -120,3 +338,31 @@ func getOpusMaxBandwith(maxBw string) (opus.Bandwidth, error) {
 
 	return 0, errors.New("unknown opus max bandwidth value")
 }
+
+// getEQSections returns the filter sections of an equalizer from the
+// settings. If no sections are configured, the tx equalizer defaults to
+// a 300-2700Hz bandpass (SSB); the rx equalizer has no filters.
+func getEQSections(key string) ([]eq.Section, error) {
+	if !viper.IsSet(key) {
+		if key == "eq.tx-sections" {
+			return []eq.Section{
+				{Type: eq.HighPass, Frequency: 300, Q: 0.707},
+				{Type: eq.LowPass, Frequency: 2700, Q: 0.707},
+			}, nil
+		}
+		return nil, nil
+	}
+
+	sections := []eq.Section{}
+	if err := viper.UnmarshalKey(key, &sections); err != nil {
+		return nil, err
+	}
+
+	for _, s := range sections {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return sections, nil
+}
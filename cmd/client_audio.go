@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/chain"
+	"github.com/dh1tw/remoteAudio/audio/nodes/aec"
+	"github.com/dh1tw/remoteAudio/audio/nodes/agc"
+	"github.com/dh1tw/remoteAudio/audio/nodes/analyser"
+	"github.com/dh1tw/remoteAudio/audio/nodes/compressor"
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+	"github.com/dh1tw/remoteAudio/audio/nodes/meter"
+	"github.com/dh1tw/remoteAudio/audio/nodes/notch"
+	"github.com/dh1tw/remoteAudio/audio/nodes/nr"
+	"github.com/dh1tw/remoteAudio/audio/nodes/tap"
+	"github.com/dh1tw/remoteAudio/audio/nodes/vox"
+	"github.com/dh1tw/remoteAudio/audio/sinks/pbWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/scWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/wsWriter"
+	"github.com/dh1tw/remoteAudio/audio/sources/pbReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/scReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/wsReader"
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	"github.com/dh1tw/remoteAudio/keyer"
+	"github.com/dh1tw/remoteAudio/recorder"
+	"github.com/dh1tw/remoteAudio/rtp"
+	"github.com/dh1tw/remoteAudio/trx"
+	"github.com/dh1tw/remoteAudio/webserver"
+	"github.com/spf13/viper"
+)
+
+// audioClient contains the audio devices, chains and the Trx of a client.
+// It is independent of the transport through which the client talks to
+// the audio servers.
+type audioClient struct {
+	trx           *trx.Trx
+	mic           *scReader.ScReader
+	speaker       *scWriter.ScWriter
+	browserSink   *wsWriter.WsWriter
+	browserSource *wsReader.WsReader
+	rxAGC         *agc.AGC
+	txAGC         *agc.AGC
+	codecs        []audiocodec.Format // preferred audio codecs for sending audio
+}
+
+// newAudioClient creates the audio devices, the rx and tx audio chains and
+// the Trx, configured by the audio settings. The audio is exchanged with
+// the audio servers through the broker (or the RTP media path if the
+// media.transport is 'rtp'). The broker must be connected and the audio
+// devices must have been initialized before.
+func newAudioClient(userName string, br broker.Broker) (*audioClient, error) {
+
+	// viper settings need to be copied in local variables
+	// since viper lookups allocate of each lookup a copy
+	// and are quite inperformant
+	audioFramesPerBuffer := viper.GetInt("audio.frame-length")
+
+	oDeviceName := viper.GetString("output-device.device-name")
+	oHostAPI := viper.GetString("output-device.hostapi")
+	oSamplerate := viper.GetFloat64("output-device.samplerate")
+	oLatency := viper.GetDuration("output-device.latency")
+	oChannels := viper.GetInt("output-device.channels")
+	oRingBufferSize := viper.GetInt("audio.rx-buffer-length")
+
+	iDeviceName := viper.GetString("input-device.device-name")
+	iHostAPI := viper.GetString("input-device.hostapi")
+	iSamplerate := viper.GetFloat64("input-device.samplerate")
+	iLatency := viper.GetDuration("input-device.latency")
+	iChannels := viper.GetInt("input-device.channels")
+
+	audioCodec := strings.ToLower(viper.GetString("audio.codec"))
+
+	mediaTransport := strings.ToLower(viper.GetString("media.transport"))
+	mediaAddress := viper.GetString("media.address")
+	if len(mediaAddress) == 0 {
+		mediaAddress = ":0" // random port
+	}
+
+	encoders, err := newEncoderConfig(iChannels)
+	if err != nil {
+		return nil, err
+	}
+
+	rxVolume := viper.GetInt("audio.rx-volume")
+	txVolume := viper.GetInt("audio.tx-volume")
+
+	speaker, err := scWriter.NewScWriter(
+		scWriter.HostAPI(oHostAPI),
+		scWriter.DeviceName(oDeviceName),
+		scWriter.Channels(oChannels),
+		scWriter.Samplerate(oSamplerate),
+		scWriter.Latency(oLatency),
+		scWriter.RingBufferSize(oRingBufferSize),
+		scWriter.FramesPerBuffer(audioFramesPerBuffer),
+	)
+	if err != nil {
+		return nil, err
+	}
+	speaker.SetVolume(float32(rxVolume) / 100)
+
+	mic, err := scReader.NewScReader(
+		scReader.HostAPI(iHostAPI),
+		scReader.DeviceName(iDeviceName),
+		scReader.Channels(iChannels),
+		scReader.Samplerate(iSamplerate),
+		scReader.Latency(iLatency),
+		scReader.FramesPerBuffer(audioFramesPerBuffer),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	fromNetwork, err := pbReader.NewPbReader()
+	if err != nil {
+		return nil, err
+	}
+
+	// the encoder for the protobuf writer is selected by the
+	// audio.codec parameter
+	encoder, err := encoders.newEncoder(audioCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	toNetwork, err := pbWriter.NewPbWriter(
+		pbWriter.Encoder(encoder),
+		pbWriter.Channels(iChannels),
+		pbWriter.FramesPerBuffer(audioFramesPerBuffer),
+		pbWriter.UserID(userName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	toNetwork.SetVolume(float32(txVolume) / 100)
+
+	ac := &audioClient{
+		speaker: speaker,
+		mic:     mic,
+	}
+
+	voxStateChanged := func(newState bool) {
+		if err := ac.trx.SetVOX(newState); err != nil {
+			log.Printf("unable to set vox: %s\n", err.Error())
+		}
+	}
+	voxEnabled := viper.GetBool("audio.vox")
+	voxThreshold := viper.GetFloat64("audio.vox-threshold")
+	voxHoldtime := viper.GetDuration("audio.vox-holdtime")
+	antiVoxGain := viper.GetFloat64("audio.anti-vox-gain")
+	rxProfile := strings.ToLower(viper.GetString("audio.rx-profile"))
+
+	_vox := vox.New(
+		vox.Enabled(voxEnabled),
+		vox.StateChanged(voxStateChanged),
+		vox.Threshold(float32(voxThreshold)),
+		vox.HoldTime(voxHoldtime),
+		vox.AntiVoxGain(float32(antiVoxGain)))
+
+	agcOpts := []agc.Option{
+		agc.Target(float32(viper.GetFloat64("agc.target"))),
+		agc.Attack(viper.GetDuration("agc.attack")),
+		agc.Decay(viper.GetDuration("agc.decay")),
+		agc.HangTime(viper.GetDuration("agc.hang-time")),
+		agc.MaxGain(float32(viper.GetFloat64("agc.max-gain"))),
+	}
+
+	// the gain is adjusted after the vox, otherwise the amplified
+	// background noise would trigger the vox
+	ac.txAGC = agc.New(append(agcOpts, agc.Enabled(viper.GetBool("agc.tx")))...)
+	ac.rxAGC = agc.New(append(agcOpts, agc.Enabled(viper.GetBool("agc.rx")))...)
+
+	// the compressor / limiter is the last node before the audio is
+	// sent, so that its limit can't be exceeded by the nodes in front
+	_compressor := compressor.New(
+		compressor.Enabled(viper.GetBool("compressor.enabled")),
+		compressor.Threshold(float32(viper.GetFloat64("compressor.threshold"))),
+		compressor.Ratio(float32(viper.GetFloat64("compressor.ratio"))),
+		compressor.MakeupGain(float32(viper.GetFloat64("compressor.makeup-gain"))),
+		compressor.Limit(float32(viper.GetFloat64("compressor.limit"))),
+		compressor.Attack(viper.GetDuration("compressor.attack")),
+		compressor.Release(viper.GetDuration("compressor.release")),
+	)
+
+	rxSections, err := getEQSections("eq.rx-sections")
+	if err != nil {
+		return nil, err
+	}
+	rxEQ, err := eq.New(eq.Enabled(viper.GetBool("eq.rx")), eq.Sections(rxSections...))
+	if err != nil {
+		return nil, err
+	}
+
+	// the tx audio is filtered before the compressor, so that the
+	// compressor doesn't react on the removed frequencies
+	txSections, err := getEQSections("eq.tx-sections")
+	if err != nil {
+		return nil, err
+	}
+	txEQ, err := eq.New(eq.Enabled(viper.GetBool("eq.tx")), eq.Sections(txSections...))
+	if err != nil {
+		return nil, err
+	}
+
+	// the levels are shown in the web interface; the tx level is
+	// measured in front of the vox, so that it can be compared with
+	// the vox threshold
+	rxMeter := meter.New()
+	txMeter := meter.New()
+
+	// the echo of the speaker is removed from the mic audio before it
+	// is measured, so that it doesn't trigger the vox
+	txAEC := aec.New(
+		aec.Enabled(viper.GetBool("aec.enabled")),
+		aec.TailLength(viper.GetDuration("aec.tail-length")),
+		aec.Mu(viper.GetFloat64("aec.mu")),
+	)
+
+	txChainOpts := []chain.Option{
+		chain.DefaultSource("mic"),
+		chain.Node(txAEC),
+		chain.Node(txMeter),
+		chain.Node(_vox),
+		chain.Node(txEQ),
+		chain.Node(ac.txAGC),
+		chain.Node(_compressor),
+		chain.DefaultSink("toNetwork"),
+	}
+
+	// with a mixer, the voice keyer is transmitted together with the
+	// microphone instead of replacing it
+	var txMixer *audio.Mixer
+	if viper.GetBool("audio.tx-mixer") {
+		txMixer, err = audio.NewMixer(
+			audio.MixerSamplerate(iSamplerate),
+			audio.MixerChannels(iChannels),
+			audio.MixerFrames(audioFramesPerBuffer),
+		)
+		if err != nil {
+			return nil, err
+		}
+		txChainOpts = append(txChainOpts, chain.Selector(txMixer))
+	}
+
+	tx, err := chain.NewChain(txChainOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// the noise is removed before the AGC amplifies it
+	rxNR := nr.New(
+		nr.Enabled(viper.GetBool("nr.enabled")),
+		nr.Strength(float32(viper.GetFloat64("nr.strength"))),
+	)
+
+	rxNotch := notch.New(
+		notch.Auto(viper.GetBool("notch.auto")),
+		notch.Manual(viper.GetBool("notch.manual")),
+		notch.Frequency(viper.GetFloat64("notch.frequency")),
+		notch.Q(viper.GetFloat64("notch.q")),
+	)
+
+	// the spectrum is calculated from the received audio, before it is
+	// filtered, so that interfering signals remain visible
+	rxAnalyser, err := analyser.New(
+		analyser.Size(viper.GetInt("analyser.size")),
+		analyser.Rate(viper.GetFloat64("analyser.rate")),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the speaker audio is the reference for the echo canceller and
+	// the anti-vox
+	speakerTap := tap.New()
+
+	rx, err := chain.NewChain(chain.DefaultSource("fromNetwork"),
+		chain.Node(rxAnalyser),
+		chain.Node(rxNotch),
+		chain.Node(rxNR),
+		chain.Node(rxEQ),
+		chain.Node(ac.rxAGC),
+		chain.Node(rxMeter),
+		chain.Node(speakerTap),
+		chain.DefaultSink("speaker"))
+	if err != nil {
+		return nil, err
+	}
+
+	trxOpts := trx.Options{
+		Rx:          rx,
+		Tx:          tx,
+		FromNetwork: fromNetwork,
+		ToNetwork:   toNetwork,
+		Broker:      br,
+		Vox:         _vox,
+		Compressor:  _compressor,
+		RxEQ:        rxEQ,
+		TxEQ:        txEQ,
+		NR:          rxNR,
+		Notch:       rxNotch,
+		RxMeter:     rxMeter,
+		TxMeter:     txMeter,
+		Analyser:    rxAnalyser,
+		AEC:         txAEC,
+		SpeakerTap:  speakerTap,
+		NewEncoder:  encoders.newEncoder,
+	}
+
+	keyerDir, err := dataDirectory("keyer.directory", "keyer")
+	if err != nil {
+		return nil, err
+	}
+	keyerStore, err := keyer.NewStore(keyerDir)
+	if err != nil {
+		return nil, err
+	}
+	trxOpts.Keyer = keyerStore
+	trxOpts.Mic = mic
+	trxOpts.TxMixer = txMixer
+
+	recDir, err := dataDirectory("recorder.directory", "recordings")
+	if err != nil {
+		return nil, err
+	}
+	recordings, err := recorder.NewStore(recDir)
+	if err != nil {
+		return nil, err
+	}
+	trxOpts.Recordings = recordings
+	trxOpts.RecordOpts = []recorder.Option{
+		recorder.Format(strings.ToLower(viper.GetString("recorder.format"))),
+		recorder.MaxSize(int64(viper.GetInt("recorder.max-size")) << 20),
+		recorder.MaxDuration(viper.GetDuration("recorder.max-duration")),
+	}
+
+	// exchange the audio frames directly over UDP with the servers
+	// which support it. The broker is still used for signalling.
+	if mediaTransport == "rtp" {
+		media, err := rtp.Listen(mediaAddress, rtp.KeepAlive(time.Second*2))
+		if err != nil {
+			return nil, err
+		}
+		trxOpts.Media = media
+	}
+
+	ac.trx, err = trx.NewTrx(trxOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rx profile has to be set before the audio server is selected
+	switch rxProfile {
+	case "auto":
+		ac.trx.SetAutoRxProfile(true)
+	default:
+		if err := ac.trx.SelectRxProfile(rxProfile); err != nil {
+			return nil, err
+		}
+	}
+
+	rx.Sources.AddSource("fromNetwork", fromNetwork)
+	// set and enable speaker as default sink
+	rx.Sinks.AddSink("speaker", speaker, true)
+
+	// browsers connected to the webserver can listen and talk
+	// through the /ws/audio endpoint
+	ac.browserSink, err = wsWriter.NewWsWriter(
+		wsWriter.FramesPerBuffer(audioFramesPerBuffer),
+	)
+	if err != nil {
+		return nil, err
+	}
+	ac.browserSink.SetVolume(float32(rxVolume) / 100)
+	rx.Sinks.AddSink("browser", ac.browserSink, true)
+
+	ac.browserSource, err = wsReader.NewWsReader()
+	if err != nil {
+		return nil, err
+	}
+	tx.Sources.AddSource("browser", ac.browserSource)
+	// start streaming from the network immediately
+	rx.Sources.SetSource("fromNetwork")
+
+	tx.Sources.AddSource("mic", mic)
+	tx.Sinks.AddSink("toNetwork", toNetwork, false)
+	tx.Sources.SetSource("mic")
+
+	// the configured codec is prefered; if the audio server doesn't
+	// support it, we fall back to any other codec we know
+	for _, codec := range append([]string{audioCodec}, audiocodec.Names()...) {
+		ac.codecs = append(ac.codecs, encoders.format(codec))
+	}
+
+	return ac, nil
+}
+
+// newWebServer creates the web server through which the user interacts with
+// the client. Browsers can listen and talk through the web server as well.
+func (ac *audioClient) newWebServer(host string, port int) (*webserver.WebServer, error) {
+
+	opts := []webserver.Option{
+		webserver.AudioSink(ac.browserSink),
+		webserver.AudioSource(ac.browserSource, "browser"),
+	}
+
+	// browsers only allow access to the microphone via https (except on
+	// localhost)
+	cert := viper.GetString("http.cert")
+	key := viper.GetString("http.key")
+	if len(cert) > 0 || len(key) > 0 {
+		if len(cert) == 0 || len(key) == 0 {
+			return nil, errors.New("both, http.cert and http.key must be set for https")
+		}
+		opts = append(opts, webserver.TLS(cert, key))
+	}
+
+	return webserver.NewWebServer(host, port, ac.trx, opts...)
+}
+
+// close shuts down the audio devices of the client.
+func (ac *audioClient) close() {
+	// TBD: close also router (and all sinks)
+	// complete the headers of the recordings
+	ac.trx.SetRxRecording(false)
+	ac.trx.SetTxRecording(false)
+	ac.mic.Close()
+	ac.speaker.Close()
+}
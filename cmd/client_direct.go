@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/direct"
+	"github.com/dh1tw/remoteAudio/proxy"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"github.com/dh1tw/remoteAudio/utils"
+	"github.com/gordonklaus/portaudio"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var directClientCmd = &cobra.Command{
+	Use:   "direct",
+	Short: "Direct Client (without broker)",
+	Long: `Direct Client for bi-directional audio streaming
+
+The client connects directly to a single audio server ('server direct')
+through a point-to-point TCP connection. No broker is needed. By default
+the audio is sent through the same TCP connection. With '--transport rtp'
+the audio frames are exchanged over UDP (RTP) if the server provides a
+media path.
+
+The client authenticates with the shared secret ('--secret') of the
+server. The connection is not encrypted; if the server is reachable from
+the internet, tunnel the connection through a VPN or SSH.
+
+In order to find the supported audio devices and audio host APIs
+for your platform run:
+
+$ remoteAudio(.exe) enumerate
+
+You can interact with the remoteAudio client through it's integrated
+web server / REST API. In order to use the REST API check the project's
+wiki https://github.com/dh1tw/remoteAudio/wiki.
+`,
+	Run: directAudioClient,
+}
+
+func init() {
+	clientCmd.AddCommand(directClientCmd)
+	directClientCmd.Flags().StringP("server-address", "s", "localhost:7700", "TCP address (host:port) of the audio server")
+	directClientCmd.Flags().StringP("username", "U", "", "Username (auto generated if empty)")
+	directClientCmd.Flags().String("secret", "", "shared secret of the audio server")
+	directClientCmd.Flags().StringP("http-host", "w", "127.0.0.1", "Host (use '0.0.0.0' to listen on all network adapters)")
+	directClientCmd.Flags().StringP("http-port", "k", "9090", "Port to access the web interface")
+	directClientCmd.Flags().String("http-cert", "", "TLS certificate (PEM) for serving the web interface via https")
+	directClientCmd.Flags().String("http-key", "", "TLS private key (PEM) for serving the web interface via https")
+	directClientCmd.Flags().Int32("tx-volume", 70, "volume of tx audio stream on startup")
+	directClientCmd.Flags().Int32("rx-volume", 70, "volume of rx audio stream on startup")
+	directClientCmd.Flags().BoolP("stream-on-startup", "t", false, "start the local and remote audio streams on startup")
+	directClientCmd.Flags().Bool("vox", false, "enable vox (voice activation)")
+	directClientCmd.Flags().Float32("vox-threshold", 0.1, "vox threshold (0...1)")
+	directClientCmd.Flags().Duration("vox-holdtime", time.Millisecond*500, "vox hold time")
+	directClientCmd.Flags().Float32("anti-vox-gain", 0, "anti-vox gain (0...10); the rx level times the gain raises the vox threshold")
+	directClientCmd.Flags().Bool("tx-mixer", false, "mix the voice keyer with the microphone instead of replacing it")
+	directClientCmd.Flags().String("transport", "tcp", "transport of the audio frames ('tcp' or 'rtp'); rtp falls back to tcp if the server doesn't support it")
+	directClientCmd.Flags().String("media-address", ":0", "local UDP address of the RTP media path")
+	directClientCmd.Flags().String("rx-profile", "", "rx profile of the audio stream ('high', 'medium', 'low' or 'auto' for selecting it based on the latency)")
+}
+
+func directAudioClient(cmd *cobra.Command, args []string) {
+
+	// Try to read config file
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	} else {
+		if strings.Contains(err.Error(), "Not Found in") {
+			fmt.Println("no config file found")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error parsing config file %v: %v\n",
+				viper.ConfigFileUsed(), err)
+			os.Exit(1)
+		}
+	}
+
+	// check if values from config file / pflags are valid
+	if err := checkAudioParameterValues(); err != nil {
+		exit(err)
+	}
+
+	// bind the pflags to viper settings
+	viper.BindPFlag("direct.server-address", cmd.Flags().Lookup("server-address"))
+	viper.BindPFlag("direct.username", cmd.Flags().Lookup("username"))
+	viper.BindPFlag("direct.secret", cmd.Flags().Lookup("secret"))
+	viper.BindPFlag("http.host", cmd.Flags().Lookup("http-host"))
+	viper.BindPFlag("http.port", cmd.Flags().Lookup("http-port"))
+	viper.BindPFlag("http.cert", cmd.Flags().Lookup("http-cert"))
+	viper.BindPFlag("http.key", cmd.Flags().Lookup("http-key"))
+	viper.BindPFlag("audio.rx-volume", cmd.Flags().Lookup("rx-volume"))
+	viper.BindPFlag("audio.tx-volume", cmd.Flags().Lookup("tx-volume"))
+	viper.BindPFlag("audio.stream-on-startup", cmd.Flags().Lookup("stream-on-startup"))
+	viper.BindPFlag("audio.vox", cmd.Flags().Lookup("vox"))
+	viper.BindPFlag("audio.vox-threshold", cmd.Flags().Lookup("vox-threshold"))
+	viper.BindPFlag("audio.vox-holdtime", cmd.Flags().Lookup("vox-holdtime"))
+	viper.BindPFlag("audio.anti-vox-gain", cmd.Flags().Lookup("anti-vox-gain"))
+	viper.BindPFlag("audio.tx-mixer", cmd.Flags().Lookup("tx-mixer"))
+	viper.BindPFlag("audio.rx-profile", cmd.Flags().Lookup("rx-profile"))
+	viper.BindPFlag("media.transport", cmd.Flags().Lookup("transport"))
+	viper.BindPFlag("media.address", cmd.Flags().Lookup("media-address"))
+
+	streamOnStartup := viper.GetBool("audio.stream-on-startup")
+
+	switch transport := strings.ToLower(viper.GetString("media.transport")); transport {
+	case "tcp", "rtp":
+	default:
+		exit(fmt.Errorf("unknown transport '%s'", transport))
+	}
+
+	serverAddress := viper.GetString("direct.server-address")
+	httpHost := viper.GetString("http.host")
+	httpPort := viper.GetInt("http.port")
+
+	userName := viper.GetString("direct.username")
+	if len(userName) == 0 {
+		userName = fmt.Sprintf("client-%s", utils.RandStringRunes(5))
+		log.Printf("username not set; auto generated unique username '%s'\n", userName)
+	}
+
+	if err := portaudio.Initialize(); err != nil {
+		exit(err)
+	}
+	defer portaudio.Terminate()
+
+	cl, err := direct.Dial(serverAddress, viper.GetString("direct.secret"), time.Second*3)
+	if err != nil {
+		exit(err)
+	}
+
+	// the connection leads to a single server; ask it for its name
+	caps, err := sbAudio.NewServerService("", cl).GetCapabilities(context.Background(), &sbAudio.None{})
+	if err != nil {
+		exit(fmt.Errorf("unable to retrieve the capabilities of %s: %v", serverAddress, err))
+	}
+	serverName := caps.GetName()
+
+	ac, err := newAudioClient(userName, cl.Options().Broker)
+	if err != nil {
+		exit(err)
+	}
+
+	doneCh := make(chan struct{})
+	audioSvr, err := proxy.NewAudioServer(serverName, cl, doneCh,
+		proxy.Codecs(ac.codecs...))
+	if err != nil {
+		exit(fmt.Errorf("audio server for %s unavailable", serverName))
+	}
+
+	ac.trx.AddServer(audioSvr)
+	if err := ac.trx.SelectServer(serverName); err != nil {
+		exit(err)
+	}
+
+	if streamOnStartup {
+		if err := ac.trx.SetPTT(true); err != nil {
+			exit(err)
+		}
+		if err := audioSvr.StartRxStream(); err != nil {
+			exit(err)
+		}
+	}
+
+	// without the server there is nothing left to do
+	go func() {
+		select {
+		case <-doneCh:
+		case <-cl.Done():
+		}
+		ac.close()
+		exit(fmt.Errorf("connection to server %s closed", serverAddress))
+	}()
+
+	web, err := ac.newWebServer(httpHost, httpPort)
+	if err != nil {
+		exit(err)
+	}
+
+	go web.Start()
+
+	// Channel to handle OS signals
+	osSignals := make(chan os.Signal, 1)
+	//subscribe to os.Interrupt (CTRL-C signal)
+	signal.Notify(osSignals, os.Interrupt)
+
+	<-osSignals
+
+	ac.close()
+	cl.Close()
+}
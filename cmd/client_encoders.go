@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	"github.com/dh1tw/remoteAudio/audiocodec/adpcm"
+	"github.com/dh1tw/remoteAudio/audiocodec/opus"
+	"github.com/dh1tw/remoteAudio/audiocodec/pcm"
+	"github.com/spf13/viper"
+	libopus "gopkg.in/hraban/opus.v2"
+)
+
+// encoderConfig contains the configured settings of the encoders with
+// which a client sends audio to the audio servers. The same settings are
+// offered to the servers during the codec negotiation.
+type encoderConfig struct {
+	channels          int
+	opusBitrate       int
+	opusComplexity    int
+	opusApplication   libopus.Application
+	opusMaxBandwidth  libopus.Bandwidth
+	opusFEC           bool
+	opusPacketLoss    int
+	opusAdaptive      bool
+	opusMinBitrate    int
+	opusMaxBitrate    int
+	opusMaxComplexity int
+	pcmSamplerate     int
+	pcmBitDepth       int
+	adpcmSamplerate   int
+}
+
+// newEncoderConfig reads the settings of the opus, pcm and adpcm encoders
+// from the configuration. The values have been checked before.
+func newEncoderConfig(channels int) (encoderConfig, error) {
+
+	opusApplication, err := getOpusApplication(viper.GetString("opus.application"))
+	if err != nil {
+		return encoderConfig{}, err
+	}
+	opusMaxBandwidth, err := getOpusMaxBandwith(viper.GetString("opus.max-bandwidth"))
+	if err != nil {
+		return encoderConfig{}, err
+	}
+
+	return encoderConfig{
+		channels:          channels,
+		opusBitrate:       viper.GetInt("opus.bitrate"),
+		opusComplexity:    viper.GetInt("opus.complexity"),
+		opusApplication:   opusApplication,
+		opusMaxBandwidth:  opusMaxBandwidth,
+		opusFEC:           viper.GetBool("opus.fec"),
+		opusPacketLoss:    viper.GetInt("opus.packet-loss"),
+		opusAdaptive:      viper.GetBool("opus.adaptive"),
+		opusMinBitrate:    viper.GetInt("opus.min-bitrate"),
+		opusMaxBitrate:    viper.GetInt("opus.max-bitrate"),
+		opusMaxComplexity: viper.GetInt("opus.max-complexity"),
+		pcmSamplerate:     viper.GetInt("pcm.samplerate"),
+		pcmBitDepth:       viper.GetInt("pcm.bitdepth"),
+		adpcmSamplerate:   viper.GetInt("adpcm.samplerate"),
+	}, nil
+}
+
+// format returns the format of the audio encoded with the codec.
+func (c encoderConfig) format(codec string) audiocodec.Format {
+	f := audiocodec.Format{
+		Name: codec,
+		Options: audiocodec.Options{
+			Channels: c.channels,
+		},
+	}
+
+	switch codec {
+	case "pcm":
+		f.Samplerate = c.pcmSamplerate
+		f.Bitdepth = c.pcmBitDepth
+	case "adpcm":
+		f.Samplerate = c.adpcmSamplerate
+	case "opus":
+		f.Samplerate = 48000 // opus only works well with 48kHz
+	}
+
+	return f
+}
+
+// newEncoder creates an encoder for the codec with the configured
+// settings. Codecs without settings are created with their defaults.
+func (c encoderConfig) newEncoder(codec string) (audiocodec.Encoder, error) {
+	f := c.format(codec)
+
+	switch codec {
+	case "pcm":
+		return pcm.NewEncoder(
+			pcm.Channels(f.Channels),
+			pcm.Samplerate(float64(f.Samplerate)),
+			pcm.BitDepth(f.Bitdepth),
+		)
+	case "adpcm":
+		return adpcm.NewEncoder(
+			adpcm.Channels(f.Channels),
+			adpcm.Samplerate(float64(f.Samplerate)),
+		)
+	case "opus":
+		return opus.NewEncoder(
+			opus.Bitrate(c.opusBitrate),
+			opus.Complexity(c.opusComplexity),
+			opus.Channels(f.Channels),
+			opus.Samplerate(float64(f.Samplerate)),
+			opus.Application(c.opusApplication),
+			opus.MaxBandwidth(c.opusMaxBandwidth),
+			opus.InBandFEC(c.opusFEC),
+			opus.PacketLoss(c.opusPacketLoss),
+			opus.Adaptive(c.opusAdaptive),
+			opus.BitrateRange(c.opusMinBitrate, c.opusMaxBitrate),
+			opus.ComplexityRange(c.opusComplexity, c.opusMaxComplexity),
+		)
+	}
+
+	return audiocodec.NewEncoder(codec, f.Options)
+}
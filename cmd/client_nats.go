@@ -16,17 +16,10 @@ import (
 	"github.com/asim/go-micro/v3/client"
 	"github.com/asim/go-micro/v3/registry"
 	"github.com/asim/go-micro/v3/transport"
-	"github.com/dh1tw/remoteAudio/audio/chain"
-	"github.com/dh1tw/remoteAudio/audio/nodes/vox"
-	"github.com/dh1tw/remoteAudio/audio/sinks/pbWriter"
-	"github.com/dh1tw/remoteAudio/audio/sinks/scWriter"
-	"github.com/dh1tw/remoteAudio/audio/sources/pbReader"
-	"github.com/dh1tw/remoteAudio/audio/sources/scReader"
-	"github.com/dh1tw/remoteAudio/audiocodec/opus"
+	"github.com/dh1tw/remoteAudio/audiocodec"
 	"github.com/dh1tw/remoteAudio/proxy"
 	"github.com/dh1tw/remoteAudio/trx"
 	"github.com/dh1tw/remoteAudio/utils"
-	"github.com/dh1tw/remoteAudio/webserver"
 	"github.com/gordonklaus/portaudio"
 	"github.com/nats-io/nats.go"
 	"github.com/spf13/cobra"
@@ -40,7 +33,9 @@ var natsClientCmd = &cobra.Command{
 	Long: `NATS Client for bi-directional audio streaming
 
 The audio streaming is done through the protocol NATS. You need a NATS
-broker up and running to which the client can connect to.
+broker up and running to which the client can connect to. With
+'--transport rtp' the audio frames are exchanged directly over UDP
+(RTP) with servers which provide a media path, bypassing the broker.
 
 In order to find the supported audio devices and audio host APIs
 for your platform run:
@@ -70,12 +65,19 @@ func init() {
 	natsClientCmd.Flags().StringP("server-name", "Y", "", "default audio server (e.g. 'ts480')")
 	natsClientCmd.Flags().StringP("http-host", "w", "127.0.0.1", "Host (use '0.0.0.0' to listen on all network adapters)")
 	natsClientCmd.Flags().StringP("http-port", "k", "9090", "Port to access the web interface")
+	natsClientCmd.Flags().String("http-cert", "", "TLS certificate (PEM) for serving the web interface via https")
+	natsClientCmd.Flags().String("http-key", "", "TLS private key (PEM) for serving the web interface via https")
 	natsClientCmd.Flags().Int32("tx-volume", 70, "volume of tx audio stream on startup")
 	natsClientCmd.Flags().Int32("rx-volume", 70, "volume of rx audio stream on startup")
 	natsClientCmd.Flags().BoolP("stream-on-startup", "t", false, "start the local and remote audio streams on startup")
 	natsClientCmd.Flags().Bool("vox", false, "enable vox (voice activation)")
 	natsClientCmd.Flags().Float32("vox-threshold", 0.1, "vox threshold (0...1)")
 	natsClientCmd.Flags().Duration("vox-holdtime", time.Millisecond*500, "vox hold time")
+	natsClientCmd.Flags().Float32("anti-vox-gain", 0, "anti-vox gain (0...10); the rx level times the gain raises the vox threshold")
+	natsClientCmd.Flags().Bool("tx-mixer", false, "mix the voice keyer with the microphone instead of replacing it")
+	natsClientCmd.Flags().String("transport", "nats", "transport of the audio frames ('nats' or 'rtp'); rtp falls back to nats if the server doesn't support it")
+	natsClientCmd.Flags().String("media-address", ":0", "local UDP address of the RTP media path")
+	natsClientCmd.Flags().String("rx-profile", "", "rx profile of the audio stream ('high', 'medium', 'low' or 'auto' for selecting it based on the latency)")
 }
 
 func natsAudioClient(cmd *cobra.Command, args []string) {
@@ -106,12 +108,19 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 	viper.BindPFlag("server.name", cmd.Flags().Lookup("server-name"))
 	viper.BindPFlag("http.host", cmd.Flags().Lookup("http-host"))
 	viper.BindPFlag("http.port", cmd.Flags().Lookup("http-port"))
+	viper.BindPFlag("http.cert", cmd.Flags().Lookup("http-cert"))
+	viper.BindPFlag("http.key", cmd.Flags().Lookup("http-key"))
 	viper.BindPFlag("audio.rx-volume", cmd.Flags().Lookup("rx-volume"))
 	viper.BindPFlag("audio.tx-volume", cmd.Flags().Lookup("tx-volume"))
 	viper.BindPFlag("audio.stream-on-startup", cmd.Flags().Lookup("stream-on-startup"))
 	viper.BindPFlag("audio.vox", cmd.Flags().Lookup("vox"))
 	viper.BindPFlag("audio.vox-threshold", cmd.Flags().Lookup("vox-threshold"))
 	viper.BindPFlag("audio.vox-holdtime", cmd.Flags().Lookup("vox-holdtime"))
+	viper.BindPFlag("audio.anti-vox-gain", cmd.Flags().Lookup("anti-vox-gain"))
+	viper.BindPFlag("audio.tx-mixer", cmd.Flags().Lookup("tx-mixer"))
+	viper.BindPFlag("audio.rx-profile", cmd.Flags().Lookup("rx-profile"))
+	viper.BindPFlag("media.transport", cmd.Flags().Lookup("transport"))
+	viper.BindPFlag("media.address", cmd.Flags().Lookup("media-address"))
 
 	// profiling server
 	// go func() {
@@ -121,38 +130,14 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 	// viper settings need to be copied in local variables
 	// since viper lookups allocate of each lookup a copy
 	// and are quite inperformant
-	audioFramesPerBuffer := viper.GetInt("audio.frame-length")
-
-	oDeviceName := viper.GetString("output-device.device-name")
-	oHostAPI := viper.GetString("output-device.hostapi")
-	oSamplerate := viper.GetFloat64("output-device.samplerate")
-	oLatency := viper.GetDuration("output-device.latency")
-	oChannels := viper.GetInt("output-device.channels")
-	oRingBufferSize := viper.GetInt("audio.rx-buffer-length")
-
-	iDeviceName := viper.GetString("input-device.device-name")
-	iHostAPI := viper.GetString("input-device.hostapi")
-	iSamplerate := viper.GetFloat64("input-device.samplerate")
-	iLatency := viper.GetDuration("input-device.latency")
-	iChannels := viper.GetInt("input-device.channels")
-
-	opusBitrate := viper.GetInt("opus.bitrate")
-	opusComplexity := viper.GetInt("opus.complexity")
-	//values checked before
-
-	opusApplication, err := getOpusApplication(viper.GetString("opus.application"))
-	if err != nil {
-		exit(err)
-	}
-	opusMaxBandwidth, err := getOpusMaxBandwith(viper.GetString("opus.max-bandwidth"))
-	if err != nil {
-		exit(err)
-	}
-
-	rxVolume := viper.GetInt("audio.rx-volume")
-	txVolume := viper.GetInt("audio.tx-volume")
 	streamOnStartup := viper.GetBool("audio.stream-on-startup")
 
+	switch transport := strings.ToLower(viper.GetString("media.transport")); transport {
+	case "nats", "rtp":
+	default:
+		exit(fmt.Errorf("unknown transport '%s'", transport))
+	}
+
 	natsUsername := viper.GetString("nats.username")
 	natsPassword := viper.GetString("nats.password")
 	natsBrokerURL := viper.GetString("nats.broker-url")
@@ -210,101 +195,12 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 		client.ContentType("application/proto-rpc"),
 	)
 
-	speaker, err := scWriter.NewScWriter(
-		scWriter.HostAPI(oHostAPI),
-		scWriter.DeviceName(oDeviceName),
-		scWriter.Channels(oChannels),
-		scWriter.Samplerate(oSamplerate),
-		scWriter.Latency(oLatency),
-		scWriter.RingBufferSize(oRingBufferSize),
-		scWriter.FramesPerBuffer(audioFramesPerBuffer),
-	)
-	if err != nil {
-		exit(err)
-	}
-	speaker.SetVolume(float32(rxVolume) / 100)
-
-	mic, err := scReader.NewScReader(
-		scReader.HostAPI(iHostAPI),
-		scReader.DeviceName(iDeviceName),
-		scReader.Channels(iChannels),
-		scReader.Samplerate(iSamplerate),
-		scReader.Latency(iLatency),
-		scReader.FramesPerBuffer(audioFramesPerBuffer),
-	)
-	if err != nil {
-		exit(err)
-	}
-
-	fromNetwork, err := pbReader.NewPbReader()
-	if err != nil {
-		exit(err)
-	}
-
-	// opus Encoder for PbWriter
-	opusEncoder, err := opus.NewEncoder(
-		opus.Bitrate(opusBitrate),
-		opus.Complexity(opusComplexity),
-		opus.Channels(iChannels),
-		opus.Samplerate(48000), // opus only works well with 48kHz
-		opus.Application(opusApplication),
-		opus.MaxBandwidth(opusMaxBandwidth),
-	)
-	if err != nil {
-		exit(err)
-	}
-
 	userName := natsUsername
 	if len(userName) == 0 {
 		userName = fmt.Sprintf("client-%s", utils.RandStringRunes(5))
 		log.Printf("username not set; auto generated unique username '%s'\n", userName)
 	}
 
-	toNetwork, err := pbWriter.NewPbWriter(
-		pbWriter.Encoder(opusEncoder),
-		pbWriter.Channels(iChannels),
-		pbWriter.FramesPerBuffer(audioFramesPerBuffer),
-		pbWriter.UserID(userName),
-	)
-	if err != nil {
-		exit(err)
-	}
-	toNetwork.SetVolume(float32(txVolume) / 100)
-
-	var _trx *trx.Trx
-
-	voxStateChanged := func(newState bool) {
-		if err := _trx.SetVOX(newState); err != nil {
-			log.Printf("unable to set vox: %s\n", err.Error())
-		}
-	}
-	voxEnabled := viper.GetBool("audio.vox")
-	voxThreshold := viper.GetFloat64("audio.vox-threshold")
-	voxHoldtime := viper.GetDuration("audio.vox-holdtime")
-
-	_vox := vox.New(
-		vox.Enabled(voxEnabled),
-		vox.StateChanged(voxStateChanged),
-		vox.Threshold(float32(voxThreshold)),
-		vox.HoldTime(voxHoldtime))
-
-	txChainOpts := []chain.Option{
-		chain.DefaultSource("mic"),
-		chain.Node(_vox),
-		chain.DefaultSink("toNetwork"),
-	}
-
-	tx, err := chain.NewChain(txChainOpts...)
-	if err != nil {
-		exit(err)
-	}
-
-	rx, err := chain.NewChain(chain.DefaultSource("fromNetwork"),
-		chain.DefaultSink("speaker"))
-	if err != nil {
-		exit(err)
-	}
-
 	// init the client
 	if err := cl.Init(); err != nil {
 		exit(err)
@@ -315,46 +211,28 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 		exit(err)
 	}
 
-	trxOpts := trx.Options{
-		Rx:          rx,
-		Tx:          tx,
-		FromNetwork: fromNetwork,
-		ToNetwork:   toNetwork,
-		Broker:      br,
-		Vox:         _vox,
-	}
-
-	_trx, err = trx.NewTrx(trxOpts)
+	ac, err := newAudioClient(userName, br)
 	if err != nil {
 		exit(err)
 	}
 
-	rx.Sources.AddSource("fromNetwork", fromNetwork)
-	// set and enable speaker as default sink
-	rx.Sinks.AddSink("speaker", speaker, true)
-	// start streaming from the network immediately
-	rx.Sources.SetSource("fromNetwork")
-
-	tx.Sources.AddSource("mic", mic)
-	tx.Sinks.AddSink("toNetwork", toNetwork, false)
-	tx.Sources.SetSource("mic")
-
 	// if a radio name is specified, create immediately
 	// an audioServer object
 	if len(serverName) > 0 {
 		doneCh := make(chan struct{})
-		audioSvr, err := proxy.NewAudioServer(serverName, cl, doneCh)
+		audioSvr, err := proxy.NewAudioServer(serverName, cl, doneCh,
+			proxy.Codecs(ac.codecs...))
 		if err != nil {
 			exit(fmt.Errorf("audio server for %s unavailable", serverName))
 		}
 
-		_trx.AddServer(audioSvr)
-		if err := _trx.SelectServer(serverName); err != nil {
+		ac.trx.AddServer(audioSvr)
+		if err := ac.trx.SelectServer(serverName); err != nil {
 			exit(err)
 		}
 
 		if streamOnStartup {
-			if err := _trx.SetPTT(true); err != nil {
+			if err := ac.trx.SetPTT(true); err != nil {
 				exit(err)
 			}
 			if err := audioSvr.StartRxStream(); err != nil {
@@ -364,18 +242,19 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 
 		go func() {
 			<-doneCh
-			_trx.RemoveServer(serverName)
+			ac.trx.RemoveServer(serverName)
 		}()
 	}
 
 	nc := natsClient{
-		trx:    _trx,
+		trx:    ac.trx,
 		client: cl,
+		codecs: ac.codecs,
 	}
 
 	go nc.watchRegistry()
 
-	web, err := webserver.NewWebServer(httpHost, httpPort, _trx)
+	web, err := ac.newWebServer(httpHost, httpPort)
 	if err != nil {
 		exit(err)
 	}
@@ -392,8 +271,7 @@ func natsAudioClient(cmd *cobra.Command, args []string) {
 		case sig := <-osSignals:
 			if sig == os.Interrupt {
 				// TBD: close also router (and all sinks)
-				mic.Close()
-				speaker.Close()
+				ac.close()
 				return
 			}
 		}
@@ -411,6 +289,7 @@ func exit(err error) {
 type natsClient struct {
 	client client.Client
 	trx    *trx.Trx
+	codecs []audiocodec.Format // preferred audio codecs for sending audio
 }
 
 // watchRegistry is a blocking function which continuously
@@ -473,7 +352,8 @@ func (nc *natsClient) addServer(aServerName string) error {
 	sName := nameFromFQSN(aServerName)
 
 	doneCh := make(chan struct{})
-	audioSvr, err := proxy.NewAudioServer(sName, nc.client, doneCh)
+	audioSvr, err := proxy.NewAudioServer(sName, nc.client, doneCh,
+		proxy.Codecs(nc.codecs...))
 	if err != nil {
 		return err
 	}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -48,10 +49,61 @@ func init() {
 	RootCmd.PersistentFlags().Int("opus-bitrate", 32000, "Bitrate (bits/sec) generated by the opus encoder")
 	RootCmd.PersistentFlags().Int("opus-complexity", 9, "Computational complexity of opus encoder")
 	RootCmd.PersistentFlags().String("opus-max-bandwidth", "wideband", "maximum bandwidth of opus encoder")
+	RootCmd.PersistentFlags().Bool("opus-fec", false, "enable in-band forward error correction of opus encoder")
+	RootCmd.PersistentFlags().Int("opus-packet-loss", 0, "expected packet loss (in percent) of the network link")
+	RootCmd.PersistentFlags().Bool("opus-adaptive", false, "adapt bitrate, complexity and bandwidth of the opus encoder to the reported network conditions")
+	RootCmd.PersistentFlags().Int("opus-min-bitrate", 6000, "lower bitrate (bits/sec) bound of the adaptive opus encoder")
+	RootCmd.PersistentFlags().Int("opus-max-bitrate", 64000, "upper bitrate (bits/sec) bound of the adaptive opus encoder")
+	RootCmd.PersistentFlags().Int("opus-max-complexity", 10, "upper complexity bound of the adaptive opus encoder")
 
+	RootCmd.PersistentFlags().Int("pcm-samplerate", 48000, "Sampling rate of the raw PCM audio stream")
+	RootCmd.PersistentFlags().Int("pcm-bitdepth", 16, "Bit depth of the raw PCM audio stream (16 bit integer or 32 bit float)")
+
+	RootCmd.PersistentFlags().Int("adpcm-samplerate", 8000, "Sampling rate of the ADPCM audio stream")
+
+	RootCmd.PersistentFlags().String("audio-codec", "opus", "Audio codec used for streaming ('opus', 'pcm' or 'adpcm')")
 	RootCmd.PersistentFlags().IntP("audio-frame-length", "f", 480, "Amount of audio samples in one frame")
 	RootCmd.PersistentFlags().IntP("rx-buffer-length", "R", 10, "Buffer length (in frames) for incoming Audio packets")
 
+	RootCmd.PersistentFlags().Bool("agc-rx", false, "enable the automatic gain control of the rx audio (client)")
+	RootCmd.PersistentFlags().Bool("agc-tx", false, "enable the automatic gain control of the tx audio (client)")
+	RootCmd.PersistentFlags().Float64("agc-target", 0.5, "peak level (0...1) to which the automatic gain control adjusts the audio")
+	RootCmd.PersistentFlags().Duration("agc-attack", time.Millisecond*10, "attack time of the automatic gain control")
+	RootCmd.PersistentFlags().Duration("agc-decay", time.Millisecond*500, "decay time of the automatic gain control")
+	RootCmd.PersistentFlags().Duration("agc-hang-time", time.Millisecond*200, "hang time of the automatic gain control")
+	RootCmd.PersistentFlags().Float64("agc-max-gain", 10, "maximum gain (linear) of the automatic gain control")
+
+	RootCmd.PersistentFlags().Bool("eq-rx", false, "enable the equalizer of the rx audio (client); the filters are set in the config file")
+	RootCmd.PersistentFlags().Bool("eq-tx", false, "enable the equalizer of the tx audio (client); defaults to a 300-2700Hz bandpass")
+
+	RootCmd.PersistentFlags().Bool("nr", false, "enable the noise reduction of the rx audio (client)")
+	RootCmd.PersistentFlags().Float64("nr-strength", 0.5, "strength (0...1) of the noise reduction")
+
+	RootCmd.PersistentFlags().Bool("notch-auto", false, "enable the automatic notch filter of the rx audio")
+	RootCmd.PersistentFlags().Bool("notch-manual", false, "enable the manual notch filter of the rx audio")
+	RootCmd.PersistentFlags().Float64("notch-frequency", 1000, "frequency (Hz) of the manual notch filter")
+	RootCmd.PersistentFlags().Float64("notch-q", 10, "quality factor of the manual notch filter (higher is narrower)")
+
+	RootCmd.PersistentFlags().Int("analyser-size", 2048, "fft size (power of two) of the rx spectrum analyser (client)")
+	RootCmd.PersistentFlags().Float64("analyser-rate", 10, "spectra per second calculated by the rx spectrum analyser")
+
+	RootCmd.PersistentFlags().Bool("aec", false, "enable the acoustic echo canceller of the tx audio (client)")
+	RootCmd.PersistentFlags().Duration("aec-tail-length", time.Millisecond*250, "longest echo delay (speaker -> mic) removed by the echo canceller")
+	RootCmd.PersistentFlags().Float64("aec-mu", 0.5, "step size (0...1) of the echo canceller's adaptive filter")
+	RootCmd.PersistentFlags().String("keyer-directory", "", "directory of the voice keyer messages (default is $HOME/.remoteAudio/keyer)")
+	RootCmd.PersistentFlags().String("recorder-directory", "", "directory of the rx / tx recordings (default is $HOME/.remoteAudio/recordings)")
+	RootCmd.PersistentFlags().String("recorder-format", "wav", "format of the recordings: 'wav', 'flac' or 'opus' (Ogg Opus; requires the opus codec)")
+	RootCmd.PersistentFlags().Int("recorder-max-size", 0, "start a new recording file after this size in MB (0 = disabled)")
+	RootCmd.PersistentFlags().Duration("recorder-max-duration", 0, "start a new recording file after this duration (0 = disabled)")
+
+	RootCmd.PersistentFlags().Bool("compressor", false, "enable the compressor / limiter of the tx audio (client)")
+	RootCmd.PersistentFlags().Float64("compressor-threshold", -20, "level (dBFS) above which the tx audio is compressed")
+	RootCmd.PersistentFlags().Float64("compressor-ratio", 4, "compression ratio (e.g. 4 for 4:1)")
+	RootCmd.PersistentFlags().Float64("compressor-makeup-gain", 6, "gain (dB) applied after the compression")
+	RootCmd.PersistentFlags().Float64("compressor-limit", -1, "ceiling (dBFS) of the brickwall limiter")
+	RootCmd.PersistentFlags().Duration("compressor-attack", time.Millisecond*5, "attack time of the compressor")
+	RootCmd.PersistentFlags().Duration("compressor-release", time.Millisecond*100, "release time of the compressor and the limiter")
+
 	viper.BindPFlag("input-device.hostapi", RootCmd.PersistentFlags().Lookup("input-device-hostapi"))
 	viper.BindPFlag("input-device.device-name", RootCmd.PersistentFlags().Lookup("input-device-name"))
 	viper.BindPFlag("input-device.samplerate", RootCmd.PersistentFlags().Lookup("input-device-samplerate"))
@@ -68,9 +120,75 @@ func init() {
 	viper.BindPFlag("opus.bitrate", RootCmd.PersistentFlags().Lookup("opus-bitrate"))
 	viper.BindPFlag("opus.complexity", RootCmd.PersistentFlags().Lookup("opus-complexity"))
 	viper.BindPFlag("opus.max-bandwidth", RootCmd.PersistentFlags().Lookup("opus-max-bandwidth"))
+	viper.BindPFlag("opus.fec", RootCmd.PersistentFlags().Lookup("opus-fec"))
+	viper.BindPFlag("opus.packet-loss", RootCmd.PersistentFlags().Lookup("opus-packet-loss"))
+	viper.BindPFlag("opus.adaptive", RootCmd.PersistentFlags().Lookup("opus-adaptive"))
+	viper.BindPFlag("opus.min-bitrate", RootCmd.PersistentFlags().Lookup("opus-min-bitrate"))
+	viper.BindPFlag("opus.max-bitrate", RootCmd.PersistentFlags().Lookup("opus-max-bitrate"))
+	viper.BindPFlag("opus.max-complexity", RootCmd.PersistentFlags().Lookup("opus-max-complexity"))
+
+	viper.BindPFlag("pcm.samplerate", RootCmd.PersistentFlags().Lookup("pcm-samplerate"))
+	viper.BindPFlag("pcm.bitdepth", RootCmd.PersistentFlags().Lookup("pcm-bitdepth"))
+
+	viper.BindPFlag("adpcm.samplerate", RootCmd.PersistentFlags().Lookup("adpcm-samplerate"))
 
+	viper.BindPFlag("audio.codec", RootCmd.PersistentFlags().Lookup("audio-codec"))
 	viper.BindPFlag("audio.frame-length", RootCmd.PersistentFlags().Lookup("audio-frame-length"))
 	viper.BindPFlag("audio.rx-buffer-length", RootCmd.PersistentFlags().Lookup("rx-buffer-length"))
+
+	viper.BindPFlag("agc.rx", RootCmd.PersistentFlags().Lookup("agc-rx"))
+	viper.BindPFlag("agc.tx", RootCmd.PersistentFlags().Lookup("agc-tx"))
+	viper.BindPFlag("agc.target", RootCmd.PersistentFlags().Lookup("agc-target"))
+	viper.BindPFlag("agc.attack", RootCmd.PersistentFlags().Lookup("agc-attack"))
+	viper.BindPFlag("agc.decay", RootCmd.PersistentFlags().Lookup("agc-decay"))
+	viper.BindPFlag("agc.hang-time", RootCmd.PersistentFlags().Lookup("agc-hang-time"))
+	viper.BindPFlag("agc.max-gain", RootCmd.PersistentFlags().Lookup("agc-max-gain"))
+
+	viper.BindPFlag("eq.rx", RootCmd.PersistentFlags().Lookup("eq-rx"))
+	viper.BindPFlag("eq.tx", RootCmd.PersistentFlags().Lookup("eq-tx"))
+
+	viper.BindPFlag("nr.enabled", RootCmd.PersistentFlags().Lookup("nr"))
+	viper.BindPFlag("nr.strength", RootCmd.PersistentFlags().Lookup("nr-strength"))
+
+	viper.BindPFlag("notch.auto", RootCmd.PersistentFlags().Lookup("notch-auto"))
+	viper.BindPFlag("notch.manual", RootCmd.PersistentFlags().Lookup("notch-manual"))
+	viper.BindPFlag("notch.frequency", RootCmd.PersistentFlags().Lookup("notch-frequency"))
+	viper.BindPFlag("notch.q", RootCmd.PersistentFlags().Lookup("notch-q"))
+
+	viper.BindPFlag("analyser.size", RootCmd.PersistentFlags().Lookup("analyser-size"))
+	viper.BindPFlag("analyser.rate", RootCmd.PersistentFlags().Lookup("analyser-rate"))
+
+	viper.BindPFlag("aec.enabled", RootCmd.PersistentFlags().Lookup("aec"))
+	viper.BindPFlag("aec.tail-length", RootCmd.PersistentFlags().Lookup("aec-tail-length"))
+	viper.BindPFlag("aec.mu", RootCmd.PersistentFlags().Lookup("aec-mu"))
+
+	viper.BindPFlag("keyer.directory", RootCmd.PersistentFlags().Lookup("keyer-directory"))
+
+	viper.BindPFlag("recorder.directory", RootCmd.PersistentFlags().Lookup("recorder-directory"))
+	viper.BindPFlag("recorder.format", RootCmd.PersistentFlags().Lookup("recorder-format"))
+	viper.BindPFlag("recorder.max-size", RootCmd.PersistentFlags().Lookup("recorder-max-size"))
+	viper.BindPFlag("recorder.max-duration", RootCmd.PersistentFlags().Lookup("recorder-max-duration"))
+
+	viper.BindPFlag("compressor.enabled", RootCmd.PersistentFlags().Lookup("compressor"))
+	viper.BindPFlag("compressor.threshold", RootCmd.PersistentFlags().Lookup("compressor-threshold"))
+	viper.BindPFlag("compressor.ratio", RootCmd.PersistentFlags().Lookup("compressor-ratio"))
+	viper.BindPFlag("compressor.makeup-gain", RootCmd.PersistentFlags().Lookup("compressor-makeup-gain"))
+	viper.BindPFlag("compressor.limit", RootCmd.PersistentFlags().Lookup("compressor-limit"))
+	viper.BindPFlag("compressor.attack", RootCmd.PersistentFlags().Lookup("compressor-attack"))
+	viper.BindPFlag("compressor.release", RootCmd.PersistentFlags().Lookup("compressor-release"))
+}
+
+// dataDirectory returns the directory set in the config (key). If it is
+// not set, the directory with the name in $HOME/.remoteAudio is used.
+func dataDirectory(key, name string) (string, error) {
+	if dir := viper.GetString(key); len(dir) > 0 {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".remoteAudio", name), nil
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/hraban/opus.v2"
+)
+
+// rxProfile describes an additional encoding of the rx audio stream
+// which is published by the server on its own sub-topic. This allows
+// clients with a poor network connection to receive the audio with
+// a lower bitrate than clients on the LAN.
+type rxProfile struct {
+	bitrate      int
+	maxBandwidth opus.Bandwidth
+}
+
+// rxProfiles contains the available encoding profiles
+var rxProfiles = map[string]rxProfile{
+	"high": {
+		bitrate:      64000,
+		maxBandwidth: opus.Fullband,
+	},
+	"medium": {
+		bitrate:      24000,
+		maxBandwidth: opus.Wideband,
+	},
+	"low": {
+		bitrate:      8000,
+		maxBandwidth: opus.Narrowband,
+	},
+}
+
+// getRxProfiles returns the sorted, lower case names of the rx profiles
+// and checks if they exist.
+func getRxProfiles(names []string) ([]string, error) {
+	profiles := []string{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) == 0 {
+			continue
+		}
+		if _, ok := rxProfiles[name]; !ok {
+			return nil, fmt.Errorf("unknown rx profile '%s'", name)
+		}
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
@@ -0,0 +1,806 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/dh1tw/remoteAudio/audio/chain"
+	"github.com/dh1tw/remoteAudio/audio/nodes/doorman"
+	"github.com/dh1tw/remoteAudio/audio/nodes/notch"
+	"github.com/dh1tw/remoteAudio/audio/sinks/pbWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/scWriter"
+	"github.com/dh1tw/remoteAudio/audio/sources/pbReader"
+	"github.com/dh1tw/remoteAudio/audio/sources/scReader"
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	"github.com/dh1tw/remoteAudio/audiocodec/adpcm"
+	"github.com/dh1tw/remoteAudio/audiocodec/opus"
+	"github.com/dh1tw/remoteAudio/audiocodec/pcm"
+	"github.com/dh1tw/remoteAudio/recorder"
+	"github.com/dh1tw/remoteAudio/rtp"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/viper"
+)
+
+// checkServerName checks if the name can be used as the name of an
+// audio server
+func checkServerName(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("server name missing")
+	}
+
+	if strings.ContainsAny(name, " _\n\r") {
+		return fmt.Errorf("forbidden character in server name '%s'", name)
+	}
+
+	return nil
+}
+
+// newAudioServer creates an audio server with its rx and tx audio chains,
+// configured by the audio settings. The audio server is independent of
+// the transport; the audio streams and state updates are published on
+// the broker and on the topics derived from the serviceName. The audio
+// devices must have been initialized before.
+func newAudioServer(name string, index int, serviceName string, br broker.Broker) (*audioServer, error) {
+
+	// viper settings need to be copied in local variables
+	// since viper lookups allocate of each lookup a copy
+	// and are quite unperformant
+
+	audioFramesPerBuffer := viper.GetInt("audio.frame-length")
+
+	rxProfileNames, err := getRxProfiles(viper.GetStringSlice("audio.rx-profiles"))
+	if err != nil {
+		return nil, err
+	}
+
+	oDeviceName := viper.GetString("output-device.device-name")
+	oHostAPI := viper.GetString("output-device.hostapi")
+	oSamplerate := viper.GetFloat64("output-device.samplerate")
+	oLatency := viper.GetDuration("output-device.latency")
+	oChannels := viper.GetInt("output-device.channels")
+	oRingBufferSize := viper.GetInt("audio.rx-buffer-length")
+
+	iDeviceName := viper.GetString("input-device.device-name")
+	iHostAPI := viper.GetString("input-device.hostapi")
+	iSamplerate := viper.GetFloat64("input-device.samplerate")
+	iLatency := viper.GetDuration("input-device.latency")
+	iChannels := viper.GetInt("input-device.channels")
+
+	opusBitrate := viper.GetInt("opus.bitrate")
+	opusComplexity := viper.GetInt("opus.complexity")
+	opusFEC := viper.GetBool("opus.fec")
+	opusPacketLoss := viper.GetInt("opus.packet-loss")
+	opusAdaptive := viper.GetBool("opus.adaptive")
+	opusMinBitrate := viper.GetInt("opus.min-bitrate")
+	opusMaxBitrate := viper.GetInt("opus.max-bitrate")
+	opusMaxComplexity := viper.GetInt("opus.max-complexity")
+
+	pcmSamplerate := viper.GetInt("pcm.samplerate")
+	pcmBitDepth := viper.GetInt("pcm.bitdepth")
+
+	adpcmSamplerate := viper.GetInt("adpcm.samplerate")
+
+	mediaAddress := viper.GetString("media.address")
+	mediaPublicAddr := viper.GetString("media.public-address")
+
+	audioCodec := strings.ToLower(viper.GetString("audio.codec"))
+
+	// value checked before
+	opusApplication, _ := getOpusApplication(viper.GetString("opus.application"))
+	opusMaxBandwidth, _ := getOpusMaxBandwith(viper.GetString("opus.max-bandwidth"))
+
+	ns := &audioServer{
+		name:            name,
+		rxAudioTopic:    serviceName + ".rx",
+		rxFeedbackTopic: serviceName + ".feedback.rx",
+		txFeedbackTopic: serviceName + ".feedback.tx",
+		rxReports:       make(map[string]rxReport),
+		txAudioTopic:    serviceName + ".tx",
+		stateTopic:      serviceName + ".state",
+		broker:          br,
+		serverIndex:     index,
+		rxCodec:         audioCodec,
+		lastPing:        time.Now(),
+		mediaPeers:      make(map[uint32]*mediaPeer),
+	}
+
+	// the RTP media path allows the clients to exchange the audio frames
+	// directly over UDP, bypassing the broker
+	if len(mediaAddress) > 0 {
+		publicAddress, err := mediaPublicAddress(mediaAddress, mediaPublicAddr)
+		if err != nil {
+			return nil, err
+		}
+		media, err := rtp.Listen(mediaAddress)
+		if err != nil {
+			return nil, err
+		}
+		media.SetHandler(ns.mediaCb)
+		ns.media = media
+		ns.mediaAddress = publicAddress
+	}
+
+	// create an sound card writer (typically feeding audio into the
+	// microphone of the transceiver)
+	mic, err := scWriter.NewScWriter(
+		scWriter.HostAPI(oHostAPI),
+		scWriter.DeviceName(oDeviceName),
+		scWriter.Channels(oChannels),
+		scWriter.Samplerate(oSamplerate),
+		scWriter.Latency(oLatency),
+		scWriter.RingBufferSize(oRingBufferSize),
+		scWriter.FramesPerBuffer(audioFramesPerBuffer),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a soundcard reader (typically connected to the speaker
+	// of the transceiver)
+	radioAudio, err := scReader.NewScReader(
+		scReader.HostAPI(iHostAPI),
+		scReader.DeviceName(iDeviceName),
+		scReader.Channels(iChannels),
+		scReader.Samplerate(iSamplerate),
+		scReader.Latency(iLatency),
+		scReader.FramesPerBuffer(audioFramesPerBuffer),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a Protobuf reader through which will decode the incomming
+	// data from the network
+	fromNetwork, err := pbReader.NewPbReader()
+	if err != nil {
+		return nil, err
+	}
+
+	// the encoder for the protobuf writer is selected by the
+	// audio.codec parameter
+	var encoder audiocodec.Encoder
+	switch audioCodec {
+	case "pcm":
+		encoder, err = pcm.NewEncoder(
+			pcm.Channels(iChannels),
+			pcm.Samplerate(float64(pcmSamplerate)),
+			pcm.BitDepth(pcmBitDepth),
+		)
+	case "adpcm":
+		encoder, err = adpcm.NewEncoder(
+			adpcm.Channels(iChannels),
+			adpcm.Samplerate(float64(adpcmSamplerate)),
+		)
+	default:
+		encoder, err = opus.NewEncoder(
+			opus.Bitrate(opusBitrate),
+			opus.Complexity(opusComplexity),
+			opus.Channels(iChannels),
+			opus.Samplerate(48000),
+			opus.Application(opusApplication),
+			opus.MaxBandwidth(opusMaxBandwidth),
+			opus.InBandFEC(opusFEC),
+			opus.PacketLoss(opusPacketLoss),
+			opus.Adaptive(opusAdaptive),
+			opus.BitrateRange(opusMinBitrate, opusMaxBitrate),
+			opus.ComplexityRange(opusComplexity, opusMaxComplexity),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// create a protobuf serializer which will encode our audio data
+	// and send it on the wire
+	toNetwork, err := pbWriter.NewPbWriter(
+		pbWriter.Encoder(encoder),
+		pbWriter.Channels(iChannels),
+		pbWriter.FramesPerBuffer(audioFramesPerBuffer),
+		pbWriter.ToWireCb(ns.toWireCb),
+		pbWriter.UserID(name),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	onTxUserChanged := func(txUser string) {
+		ns.Lock()
+		ns.txUser = txUser
+		ns.Unlock()
+		if ns.txArchive != nil && len(txUser) > 0 {
+			ns.txArchive.SetLabel(recorder.Label("tx", name, txUser))
+		}
+		if err := ns.sendState(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	dm, err := doorman.NewDoorman(doorman.TXUserChanged(onTxUserChanged))
+	if err != nil {
+		return nil, err
+	}
+
+	// create the sending chain (from network to microphone)
+	tx, err := chain.NewChain(chain.DefaultSource("fromNetwork"),
+		chain.DefaultSink("mic"), chain.Node(dm))
+	if err != nil {
+		return nil, err
+	}
+
+	// add audio sinks & sources to the tx audio chain
+	tx.Sources.AddSource("fromNetwork", fromNetwork)
+	// stream immediately audio from the network to the radio
+	if err := tx.Sources.SetSource("fromNetwork"); err != nil {
+		return nil, err
+	}
+	tx.Sinks.AddSink("mic", mic, true)
+
+	// removes carriers and tuning tones before the audio is sent
+	// to the clients
+	rxNotch := notch.New(
+		notch.Auto(viper.GetBool("notch.auto")),
+		notch.Manual(viper.GetBool("notch.manual")),
+		notch.Frequency(viper.GetFloat64("notch.frequency")),
+		notch.Q(viper.GetFloat64("notch.q")),
+	)
+
+	// create the receiving audio chain (from speaker to network)
+	rx, err := chain.NewChain(chain.DefaultSource("radioAudio"),
+		chain.Node(rxNotch),
+		chain.DefaultSink("toNetwork"))
+	if err != nil {
+		return nil, err
+	}
+
+	// add audio sinks & sources to the rx audio chain
+	rx.Sources.AddSource("radioAudio", radioAudio)
+	if err := rx.Sources.SetSource("radioAudio"); err != nil {
+		return nil, err
+	}
+	rx.Sinks.AddSink("toNetwork", toNetwork, false)
+
+	// each rx profile has its own encoder and is published on
+	// a sub-topic of the rx audio topic
+	for _, profileName := range rxProfileNames {
+		profile := rxProfiles[profileName]
+		address := ns.rxAudioTopic + "." + profileName
+
+		profileEncoder, err := opus.NewEncoder(
+			opus.Bitrate(profile.bitrate),
+			opus.Complexity(opusComplexity),
+			opus.Channels(iChannels),
+			opus.Samplerate(48000),
+			opus.Application(opusApplication),
+			opus.MaxBandwidth(profile.maxBandwidth),
+			opus.InBandFEC(opusFEC),
+			opus.PacketLoss(opusPacketLoss),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		profileToNetwork, err := pbWriter.NewPbWriter(
+			pbWriter.Encoder(profileEncoder),
+			pbWriter.Channels(iChannels),
+			pbWriter.FramesPerBuffer(audioFramesPerBuffer),
+			pbWriter.ToWireCb(ns.publishCb(address)),
+			pbWriter.UserID(name),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		sinkName := "toNetwork-" + profileName
+		rx.Sinks.AddSink(sinkName, profileToNetwork, false)
+
+		ns.rxProfiles = append(ns.rxProfiles, serverRxProfile{
+			name:     profileName,
+			address:  address,
+			codec:    profileEncoder.Name(),
+			bitrate:  profile.bitrate,
+			sinkName: sinkName,
+		})
+	}
+
+	// the archive records the rx and tx audio streams as Ogg Opus files.
+	// The rx audio is encoded by a separate sink, so that it is recorded
+	// even if no client is listening.
+	if viper.GetBool("recorder.archive") {
+		archiveEncoder, err := opus.NewEncoder(
+			opus.Bitrate(opusBitrate),
+			opus.Complexity(opusComplexity),
+			opus.Channels(iChannels),
+			opus.Samplerate(48000),
+			opus.Application(opusApplication),
+			opus.MaxBandwidth(opusMaxBandwidth),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := ns.setupArchive(rx, archiveEncoder, iChannels, audioFramesPerBuffer); err != nil {
+			return nil, err
+		}
+	}
+
+	// assign the rx and tx audio chain to our audio server
+	ns.rx = rx
+	ns.tx = tx
+	ns.fromNetwork = fromNetwork
+	ns.toNetwork = toNetwork
+	ns.mic = mic
+	ns.radioAudio = radioAudio
+
+	return ns, nil
+}
+
+// start subscribes to the tx audio stream and the reception reports of the
+// clients and starts the background routines of the audio server. The
+// broker must be connected before.
+func (ns *audioServer) start() error {
+
+	// subscribe to the audio topic and enqueue the raw data into the pbReader
+	sub, err := ns.broker.Subscribe(ns.txAudioTopic, ns.enqueueFromWire)
+	if err != nil {
+		return fmt.Errorf("subscribe: %v", err)
+	}
+	ns.txAudioSub = sub
+
+	// subscribe to the reception reports of the clients
+	if _, err := ns.broker.Subscribe(ns.rxFeedbackTopic, ns.rxFeedbackCb); err != nil {
+		return fmt.Errorf("subscribe: %v", err)
+	}
+
+	// when no ping is received, turn of the audio stream
+	go ns.checkTimeout()
+
+	// exchange reception reports with the clients
+	go ns.feedbackLoop()
+
+	if ns.media != nil {
+		go ns.checkMediaTimeout()
+	}
+
+	return nil
+}
+
+// close shuts down the audio devices and chains of the audio server.
+func (ns *audioServer) close() {
+	ns.mic.Close()
+	ns.radioAudio.Close()
+	ns.rx.Sources.Close()
+	ns.rx.Sinks.Close()
+	ns.tx.Sources.Close()
+	ns.tx.Sinks.Close()
+	if ns.media != nil {
+		ns.media.Close()
+	}
+	if ns.rxArchive != nil {
+		ns.rxArchive.Close()
+	}
+	if ns.txArchive != nil {
+		ns.txArchive.Close()
+	}
+}
+
+// setupArchive creates the recorders of the archive and adds a sink to
+// the rx chain which encodes the rx audio for the archive.
+func (ns *audioServer) setupArchive(rx *chain.Chain, encoder audiocodec.Encoder, channels, framesPerBuffer int) error {
+
+	dir, err := dataDirectory("recorder.directory", "recordings")
+	if err != nil {
+		return err
+	}
+
+	store, err := recorder.NewStore(dir)
+	if err != nil {
+		return err
+	}
+
+	opts := []recorder.Option{
+		recorder.Format(recorder.FormatOpus),
+		recorder.MaxSize(int64(viper.GetInt("recorder.max-size")) << 20),
+		recorder.MaxDuration(viper.GetDuration("recorder.max-duration")),
+	}
+
+	ns.rxArchive = store.NewRecorder(recorder.Label("rx", ns.name), opts...)
+	ns.txArchive = store.NewRecorder(recorder.Label("tx", ns.name), opts...)
+
+	toArchive, err := pbWriter.NewPbWriter(
+		pbWriter.Encoder(encoder),
+		pbWriter.Channels(channels),
+		pbWriter.FramesPerBuffer(framesPerBuffer),
+		pbWriter.ToWireCb(ns.archiveCb(ns.rxArchive)),
+		pbWriter.UserID(ns.name),
+	)
+	if err != nil {
+		return err
+	}
+
+	rx.Sinks.AddSink("archive", toArchive, true)
+
+	return nil
+}
+
+// archiveCb returns a callback which writes the audio packets into
+// the recorder.
+func (ns *audioServer) archiveCb(rec *recorder.Recorder) func([]byte) {
+	return func(data []byte) {
+		if err := rec.Enqueue(data); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+type audioServer struct {
+	sync.RWMutex
+	name         string
+	broker       broker.Broker
+	rx           *chain.Chain
+	tx           *chain.Chain
+	mic          *scWriter.ScWriter
+	radioAudio   *scReader.ScReader
+	fromNetwork  *pbReader.PbReader
+	toNetwork    *pbWriter.PbWriter
+	rxAudioTopic string
+	txAudioTopic string
+	txAudioSub   broker.Subscriber
+	stateTopic   string
+	rxOn         bool
+	txUser       string
+	serverIndex  int
+	rxCodec      string
+	rxProfiles   []serverRxProfile
+	lastPing     time.Time
+
+	rxFeedbackTopic string
+	txFeedbackTopic string
+	rxReports       map[string]rxReport // latest reception report of each client
+
+	mediaMu      sync.RWMutex
+	media        *rtp.Conn
+	mediaAddress string // address of the RTP media path advertised to the clients
+	mediaPeers   map[uint32]*mediaPeer
+
+	rxArchive *recorder.Recorder // records the rx audio stream (optional)
+	txArchive *recorder.Recorder // records the tx audio stream (optional)
+}
+
+// feedbackInterval is the interval in which reception reports are
+// exchanged with the clients
+const feedbackInterval = time.Second * 2
+
+// rxReport is a reception report received from a client
+type rxReport struct {
+	feedback audiocodec.Feedback
+	received time.Time
+}
+
+// serverRxProfile is an additional encoding of the rx audio stream
+type serverRxProfile struct {
+	name     string
+	address  string // topic on which the audio is published
+	codec    string
+	bitrate  int
+	sinkName string // name of the pbWriter in the rx chain
+}
+
+func (ns *audioServer) enqueueFromWire(pub broker.Event) error {
+	if ns.fromNetwork == nil {
+		return nil
+	}
+	ns.archiveTx(pub.Message().Body)
+	return ns.fromNetwork.Enqueue(pub.Message().Body)
+}
+
+// rxFeedbackCb stores the reception reports of the clients about
+// our rx audio stream.
+func (ns *audioServer) rxFeedbackCb(msg broker.Event) error {
+
+	report := sbAudio.ReceptionReport{}
+	if err := proto.Unmarshal(msg.Message().Body, &report); err != nil {
+		return err
+	}
+
+	ns.Lock()
+	defer ns.Unlock()
+
+	if report.GetSenderId() != ns.name {
+		return nil
+	}
+
+	ns.rxReports[report.GetReceiverId()] = rxReport{
+		feedback: audiocodec.Feedback{
+			Loss:   float64(report.GetLoss()),
+			Jitter: time.Duration(report.GetJitter()) * time.Microsecond,
+		},
+		received: time.Now(),
+	}
+
+	return nil
+}
+
+// feedbackLoop is a blocking function which periodically adapts the
+// rx audio stream to the worst reception reported by the clients and
+// which reports the reception of the tx audio stream back to the client
+// who is transmitting.
+func (ns *audioServer) feedbackLoop() {
+
+	ticker := time.NewTicker(feedbackInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+
+		ns.Lock()
+		worst := audiocodec.Feedback{}
+		reports := 0
+		for receiver, r := range ns.rxReports {
+			if time.Since(r.received) > feedbackInterval*3 {
+				delete(ns.rxReports, receiver)
+				continue
+			}
+			reports++
+			if r.feedback.Loss > worst.Loss {
+				worst.Loss = r.feedback.Loss
+			}
+			if r.feedback.Jitter > worst.Jitter {
+				worst.Jitter = r.feedback.Jitter
+			}
+		}
+		txUser := ns.txUser
+		ns.Unlock()
+
+		if reports > 0 {
+			if err := ns.toNetwork.Feedback(worst); err != nil {
+				log.Println("feedback:", err)
+			}
+		}
+
+		if len(txUser) == 0 {
+			continue
+		}
+
+		fb, err := ns.fromNetwork.Feedback(txUser)
+		if err != nil {
+			continue // no audio received from the txUser
+		}
+
+		if err := ns.sendFeedback(ns.txFeedbackTopic, txUser, fb); err != nil {
+			log.Println("feedback:", err)
+		}
+	}
+}
+
+// sendFeedback publishes a reception report about the audio stream of
+// the sender.
+func (ns *audioServer) sendFeedback(topic, sender string, fb audiocodec.Feedback) error {
+
+	report := sbAudio.ReceptionReport{
+		SenderId:   sender,
+		ReceiverId: ns.name,
+		Loss:       float32(fb.Loss),
+		Jitter:     fb.Jitter.Microseconds(),
+	}
+
+	data, err := proto.Marshal(&report)
+	if err != nil {
+		return err
+	}
+
+	ns.publish(topic, data)
+
+	return nil
+}
+
+// archiveTx writes the received tx audio packet into the archive.
+func (ns *audioServer) archiveTx(data []byte) {
+	if ns.txArchive == nil {
+		return
+	}
+	if err := ns.txArchive.Enqueue(data); err != nil {
+		log.Println(err)
+	}
+}
+
+// Callback which is called by pbWriter to push the audio
+// packets to the network
+func (ns *audioServer) toWireCb(data []byte) {
+	ns.publish(ns.rxAudioTopic, data)
+}
+
+// publishCb returns a callback for a pbWriter which pushes the audio
+// packets to the given topic.
+func (ns *audioServer) publishCb(topic string) func([]byte) {
+	return func(data []byte) {
+		ns.publish(topic, data)
+	}
+}
+
+func (ns *audioServer) publish(topic string, data []byte) {
+
+	if ns.broker == nil {
+		log.Println("publish: broker not set") // better Fatal?
+		return
+	}
+
+	msg := &broker.Message{
+		Body: data,
+	}
+
+	err := ns.broker.Publish(topic, msg)
+	if err != nil {
+		log.Println(err) // better fatal?
+	}
+
+	ns.publishMedia(topic, data)
+}
+
+func (ns *audioServer) sendState() error {
+	ns.RLock()
+	defer ns.RUnlock()
+
+	if ns.broker == nil {
+		return fmt.Errorf("sendState: broker not set")
+	}
+
+	state := sbAudio.State{
+		RxOn:   ns.rxOn,
+		TxUser: ns.txUser,
+	}
+
+	data, err := proto.Marshal(&state)
+	if err != nil {
+		return err
+	}
+
+	msg := &broker.Message{
+		Body: data,
+	}
+
+	return ns.broker.Publish(ns.stateTopic, msg)
+}
+
+func (ns *audioServer) GetCapabilities(ctx context.Context, in *sbAudio.None, out *sbAudio.Capabilities) error {
+	ns.RLock()
+	defer ns.RUnlock()
+	out.Name = ns.name
+	out.RxStreamAddress = ns.rxAudioTopic
+	out.TxStreamAddress = ns.txAudioTopic
+	out.StateUpdatesAddress = ns.stateTopic
+	out.Index = int32(ns.serverIndex)
+	out.RxCodec = ns.rxCodec
+	out.RxFeedbackAddress = ns.rxFeedbackTopic
+	out.TxFeedbackAddress = ns.txFeedbackTopic
+	out.MediaAddress = ns.mediaAddress
+
+	for _, p := range ns.rxProfiles {
+		out.RxProfiles = append(out.RxProfiles, &sbAudio.RxProfile{
+			Name:    p.name,
+			Address: p.address,
+			Codec:   p.codec,
+			Bitrate: int32(p.bitrate),
+		})
+	}
+
+	// advertise the codecs we are able to decode
+	for _, c := range audiocodec.Codecs() {
+		if c.NewDecoder == nil {
+			continue
+		}
+		out.Codecs = append(out.Codecs, &sbAudio.CodecParameters{
+			Name:          c.Name,
+			SamplingRates: toInt32(c.Samplerates),
+			BitDepths:     toInt32(c.BitDepths),
+			Channels:      toInt32(c.Channels),
+		})
+	}
+	return nil
+}
+
+// toInt32 converts a slice of ints into a slice of int32
+func toInt32(values []int) []int32 {
+	res := make([]int32, 0, len(values))
+	for _, v := range values {
+		res = append(res, int32(v))
+	}
+	return res
+}
+
+func (ns *audioServer) GetState(ctx context.Context, in *sbAudio.None, out *sbAudio.State) error {
+	rxOn, txUser, err := ns.getState()
+	if err != nil {
+		return err
+	}
+	out.RxOn = rxOn
+	out.TxUser = txUser
+	return nil
+}
+
+func (ns *audioServer) StartStream(ctx context.Context, in, out *sbAudio.None) error {
+
+	if err := ns.enableRx(true); err != nil {
+		log.Println("StartStream:", err)
+		return err
+	}
+
+	ns.Lock()
+	ns.rxOn = true
+	ns.Unlock()
+
+	if err := ns.sendState(); err != nil {
+		log.Println("StartStream:", err)
+		return err
+	}
+	return nil
+}
+
+func (ns *audioServer) StopStream(ctx context.Context, in, out *sbAudio.None) error {
+
+	if err := ns.enableRx(false); err != nil {
+		log.Println("StopStream:", err)
+		return err
+	}
+
+	ns.Lock()
+	ns.rxOn = false
+	ns.Unlock()
+
+	if err := ns.sendState(); err != nil {
+		log.Println("StopStream:", err)
+		return err
+	}
+	return nil
+}
+
+func (ns *audioServer) Ping(ctx context.Context, in, out *sbAudio.PingPong) error {
+	out.Ping = in.Ping
+	ns.Lock()
+	defer ns.Unlock()
+	ns.lastPing = time.Now()
+	return nil
+}
+
+// enableRx enables / disables the rx audio stream, including the
+// streams of all rx profiles.
+func (ns *audioServer) enableRx(state bool) error {
+	if err := ns.rx.Enable(state); err != nil {
+		return err
+	}
+	for _, p := range ns.rxProfiles {
+		if err := ns.rx.Sinks.EnableSink(p.sinkName, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ns *audioServer) getState() (bool, string, error) {
+	ns.RLock()
+	defer ns.RUnlock()
+	_, rxOn, err := ns.rx.Sinks.Sink("toNetwork")
+	if err != nil {
+		return false, "", err
+	}
+	return rxOn, ns.txUser, nil
+}
+
+func (ns *audioServer) checkTimeout() {
+
+	ticker := time.NewTicker(time.Minute)
+
+	for {
+		<-ticker.C
+		ns.RLock()
+		if time.Since(ns.lastPing) > time.Duration(time.Minute) {
+			if err := ns.enableRx(false); err != nil {
+				log.Println("checkTimeout: ", err)
+			}
+		}
+		ns.RUnlock()
+	}
+}
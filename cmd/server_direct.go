@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/dh1tw/remoteAudio/direct"
+	"github.com/gordonklaus/portaudio"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var directServerCmd = &cobra.Command{
+	Use:   "direct",
+	Short: "Direct Server (without broker)",
+	Long: `Direct Server for bi-directional audio streaming
+
+The server is typically connected to an audio device, e.g. a radio. Unlike
+the NATS server, no broker is needed. The clients ('client direct') connect
+directly to the server through a point-to-point TCP connection, which
+carries the control messages and (by default) the audio. With
+'--media-address' the audio can be exchanged over UDP (RTP) instead.
+
+By default the server only accepts connections from the local host. The
+clients authenticate with a shared secret ('--secret'), which is required
+if the server listens on another address. The connection is not
+encrypted; if the server is reachable from the internet, tunnel the
+connection through a VPN or SSH.
+
+In order to find the supported audio devices and audio host APIs
+for your platform run:
+
+$ remoteAudio(.exe) enumerate
+`,
+	Run: directAudioServer,
+}
+
+func init() {
+	serverCmd.AddCommand(directServerCmd)
+	directServerCmd.Flags().StringP("address", "a", "127.0.0.1:7700", "TCP address on which the server listens for clients (e.g. ':7700' for all interfaces)")
+	directServerCmd.Flags().String("secret", "", "shared secret of the server and its clients; required unless the server listens on the loopback interface")
+	directServerCmd.Flags().StringP("server-name", "Y", "", "server name (e.g. 'ts480')")
+	directServerCmd.Flags().Int("server-index", 1, "server index - only needed for consistent order in the GUI")
+	directServerCmd.Flags().String("media-address", "", "UDP address (e.g. ':5100') of the RTP media path; disabled if empty")
+	directServerCmd.Flags().String("media-public-address", "", "address (host:port) of the RTP media path advertised to the clients (default: media-address)")
+	directServerCmd.Flags().StringSlice("rx-profiles", []string{}, "additional encoding profiles of the rx audio stream (high, medium, low)")
+	directServerCmd.Flags().Bool("archive", false, "record the rx and tx audio streams as Ogg Opus files (see recorder-directory)")
+}
+
+func directAudioServer(cmd *cobra.Command, args []string) {
+
+	// Try to read config file
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	} else {
+		if strings.Contains(err.Error(), "Not Found in") {
+			fmt.Println("no config file found")
+		} else {
+			fmt.Fprintf(os.Stderr, "Error parsing config file %v: %v\n",
+				viper.ConfigFileUsed(), err)
+			os.Exit(1)
+		}
+	}
+
+	// check if values from config file / pflags are valid
+	if err := checkAudioParameterValues(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// bind the pflags to viper settings
+	viper.BindPFlag("direct.address", cmd.Flags().Lookup("address"))
+	viper.BindPFlag("direct.secret", cmd.Flags().Lookup("secret"))
+	viper.BindPFlag("audio.rx-profiles", cmd.Flags().Lookup("rx-profiles"))
+	viper.BindPFlag("recorder.archive", cmd.Flags().Lookup("archive"))
+	viper.BindPFlag("media.address", cmd.Flags().Lookup("media-address"))
+	viper.BindPFlag("media.public-address", cmd.Flags().Lookup("media-public-address"))
+	viper.BindPFlag("server.name", cmd.Flags().Lookup("server-name"))
+	viper.BindPFlag("server.index", cmd.Flags().Lookup("server-index"))
+
+	address := viper.GetString("direct.address")
+	secret := viper.GetString("direct.secret")
+	serverIndex := viper.GetInt("server.index")
+	serverName := viper.GetString("server.name")
+
+	if err := checkServerName(serverName); err != nil {
+		exit(err)
+	}
+
+	if err := checkDirectSecret(address, secret); err != nil {
+		exit(err)
+	}
+
+	if err := portaudio.Initialize(); err != nil {
+		exit(err)
+	}
+
+	defer portaudio.Terminate()
+
+	ds, err := direct.Listen(address, secret)
+	if err != nil {
+		exit(err)
+	}
+
+	// the topics are named like the ones of the NATS server
+	serviceName := fmt.Sprintf("shackbus.radio.%s.audio", serverName)
+
+	ns, err := newAudioServer(serverName, serverIndex, serviceName, ds.Broker())
+	if err != nil {
+		exit(err)
+	}
+
+	// subscribe to the topics of the audio server
+	if err := ns.start(); err != nil {
+		exit(err)
+	}
+
+	// the RPC endpoints are named like the ones of the micro service
+	if err := ds.Handle("Server", ns); err != nil {
+		exit(err)
+	}
+
+	go func() {
+		if err := ds.Serve(); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	log.Printf("audio server %s listening on %s\n", serverName, ds.Addr())
+
+	// Channel to handle OS signals
+	osSignals := make(chan os.Signal, 1)
+	//subscribe to os.Interrupt (CTRL-C signal)
+	signal.Notify(osSignals, os.Interrupt)
+
+	<-osSignals
+
+	ds.Close()
+	ns.close()
+}
+
+// checkDirectSecret returns an error if the server would accept clients
+// from other hosts without a shared secret.
+func checkDirectSecret(address, secret string) error {
+	if len(secret) > 0 {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address '%s': %v", address, err)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	return fmt.Errorf("a secret is required if the server listens on '%s'", address)
+}
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/rtp"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+)
+
+// mediaTimeout is the duration after which a media session is closed
+// if no packets (incl. keepalives) have been received from the client
+const mediaTimeout = time.Second * 10
+
+// mediaPeer is a client which exchanges audio with the server through
+// the RTP media path
+type mediaPeer struct {
+	userID    string
+	rxAddress string       // rx stream which the client receives
+	addr      *net.UDPAddr // learned from the packets sent by the client
+	lastSeen  time.Time
+}
+
+// mediaPublicAddress returns the address of the RTP media path which is
+// advertised to the clients. Since the clients can't guess our host,
+// the host must be specified.
+func mediaPublicAddress(address, publicAddress string) (string, error) {
+	if len(publicAddress) == 0 {
+		publicAddress = address
+	}
+
+	host, _, err := net.SplitHostPort(publicAddress)
+	if err != nil {
+		return "", fmt.Errorf("invalid media address '%s': %v", publicAddress, err)
+	}
+
+	if ip := net.ParseIP(host); len(host) == 0 || ip != nil && ip.IsUnspecified() {
+		return "", fmt.Errorf("media-public-address must be set when media-address (%s) doesn't contain a host", address)
+	}
+
+	return publicAddress, nil
+}
+
+// OpenMediaSession opens a new session on the RTP media path or updates
+// the rx stream of an existing session.
+func (ns *audioServer) OpenMediaSession(ctx context.Context, in *sbAudio.MediaSessionRequest, out *sbAudio.MediaSession) error {
+
+	if ns.media == nil {
+		return fmt.Errorf("RTP media path not enabled")
+	}
+
+	if !ns.validRxAddress(in.GetRxAddress()) {
+		return fmt.Errorf("unknown rx stream '%s'", in.GetRxAddress())
+	}
+
+	ns.mediaMu.Lock()
+	defer ns.mediaMu.Unlock()
+
+	session := in.GetSession()
+	peer, ok := ns.mediaPeers[session]
+	if !ok {
+		session = ns.newMediaSessionID()
+		peer = &mediaPeer{
+			userID: in.GetUserId(),
+		}
+		ns.mediaPeers[session] = peer
+		log.Printf("opened media session %d for %s\n", session, peer.userID)
+	}
+
+	peer.rxAddress = in.GetRxAddress()
+	peer.lastSeen = time.Now()
+
+	out.Session = session
+	out.MediaAddress = ns.mediaAddress
+
+	return nil
+}
+
+// CloseMediaSession closes a session on the RTP media path.
+func (ns *audioServer) CloseMediaSession(ctx context.Context, in *sbAudio.MediaSessionRequest, out *sbAudio.None) error {
+	ns.mediaMu.Lock()
+	defer ns.mediaMu.Unlock()
+
+	ns.closeMediaSession(in.GetSession())
+
+	return nil
+}
+
+// closeMediaSession removes a media session. This method is not safe
+// for concurrent access.
+func (ns *audioServer) closeMediaSession(session uint32) {
+	peer, ok := ns.mediaPeers[session]
+	if !ok {
+		return
+	}
+	if peer.addr != nil {
+		ns.media.Forget(peer.addr)
+	}
+	delete(ns.mediaPeers, session)
+	log.Printf("closed media session %d of %s\n", session, peer.userID)
+}
+
+// newMediaSessionID returns a random, unused session id. This method
+// is not safe for concurrent access.
+func (ns *audioServer) newMediaSessionID() uint32 {
+	for {
+		id := rand.Uint32()
+		if _, ok := ns.mediaPeers[id]; id != 0 && !ok {
+			return id
+		}
+	}
+}
+
+// validRxAddress checks if audio is published on the given address
+func (ns *audioServer) validRxAddress(address string) bool {
+	if address == ns.rxAudioTopic {
+		return true
+	}
+	for _, p := range ns.rxProfiles {
+		if address == p.address {
+			return true
+		}
+	}
+	return false
+}
+
+// mediaCb is executed for each packet received through the RTP media
+// path. The SSRC of the packet identifies the media session. The client's
+// address is learned from the packets, so that clients behind a NAT can
+// be reached.
+func (ns *audioServer) mediaCb(from *net.UDPAddr, h rtp.Header, payload []byte) {
+
+	ns.mediaMu.Lock()
+	peer, ok := ns.mediaPeers[h.SSRC]
+	if ok {
+		peer.addr = from
+		peer.lastSeen = time.Now()
+	}
+	ns.mediaMu.Unlock()
+
+	if !ok || len(payload) == 0 || ns.fromNetwork == nil {
+		return
+	}
+
+	ns.archiveTx(payload)
+	if err := ns.fromNetwork.Enqueue(payload); err != nil {
+		log.Println(err)
+	}
+}
+
+// publishMedia sends the data to all clients of the RTP media path
+// which receive the stream published on the topic.
+func (ns *audioServer) publishMedia(topic string, data []byte) {
+	if ns.media == nil {
+		return
+	}
+
+	ns.mediaMu.RLock()
+	defer ns.mediaMu.RUnlock()
+
+	for _, peer := range ns.mediaPeers {
+		if peer.addr == nil || peer.rxAddress != topic {
+			continue
+		}
+		if err := ns.media.WriteTo(data, peer.addr); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// checkMediaTimeout is a blocking function which closes the media
+// sessions of clients which have disappeared.
+func (ns *audioServer) checkMediaTimeout() {
+
+	ticker := time.NewTicker(mediaTimeout / 2)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ns.mediaMu.Lock()
+		for session, peer := range ns.mediaPeers {
+			if time.Since(peer.lastSeen) > mediaTimeout {
+				ns.closeMediaSession(session)
+			}
+		}
+		ns.mediaMu.Unlock()
+	}
+}
@@ -0,0 +1,130 @@
+package direct
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// The peers authenticate each other with a shared secret before any
+// message is exchanged. The secret itself is never sent; each side
+// proves its knowledge by returning the HMAC-SHA256 of a random
+// challenge of the other side:
+//
+//	server -> client: magic, challenge of the server
+//	client -> server: mac(challenge of the server), challenge of the client
+//	server -> client: status, mac(challenge of the client)
+//
+// The handshake doesn't encrypt the connection.
+
+// authMagic identifies the protocol (and its version) of the connection
+var authMagic = []byte("RAD1")
+
+// challengeSize is the number of random bytes of a challenge
+const challengeSize = 32
+
+// handshakeTimeout is the time within which the handshake has to be completed
+const handshakeTimeout = time.Second * 5
+
+// status of the authentication, sent by the server
+const (
+	authOK     byte = 0
+	authDenied byte = 1
+)
+
+// errAuth is returned if the peer doesn't know the shared secret
+var errAuth = errors.New("authentication failed")
+
+// mac returns the HMAC-SHA256 of the challenge, keyed with the secret
+func mac(secret string, challenge []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(challenge)
+	return h.Sum(nil)
+}
+
+func newChallenge() ([]byte, error) {
+	c := make([]byte, challengeSize)
+	if _, err := rand.Read(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// serverHandshake authenticates the client connected on conn.
+func serverHandshake(conn net.Conn, secret string) error {
+
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	challenge, err := newChallenge()
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(append(append([]byte{}, authMagic...), challenge...)); err != nil {
+		return err
+	}
+
+	res := make([]byte, sha256.Size+challengeSize)
+	if _, err := io.ReadFull(conn, res); err != nil {
+		return err
+	}
+
+	if !hmac.Equal(res[:sha256.Size], mac(secret, challenge)) {
+		conn.Write([]byte{authDenied})
+		return errAuth
+	}
+
+	_, err = conn.Write(append([]byte{authOK}, mac(secret, res[sha256.Size:])...))
+	return err
+}
+
+// clientHandshake authenticates the client towards the server on conn
+// and verifies that the server knows the secret, too.
+func clientHandshake(conn net.Conn, secret string) error {
+
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	req := make([]byte, len(authMagic)+challengeSize)
+	if _, err := io.ReadFull(conn, req); err != nil {
+		return err
+	}
+
+	if !hmac.Equal(req[:len(authMagic)], authMagic) {
+		return fmt.Errorf("unsupported protocol %q", req[:len(authMagic)])
+	}
+
+	challenge, err := newChallenge()
+	if err != nil {
+		return err
+	}
+
+	res := append(mac(secret, req[len(authMagic):]), challenge...)
+	if _, err := conn.Write(res); err != nil {
+		return err
+	}
+
+	var status [1]byte
+	if _, err := io.ReadFull(conn, status[:]); err != nil {
+		return err
+	}
+	if status[0] != authOK {
+		return errAuth
+	}
+
+	serverMac := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(conn, serverMac); err != nil {
+		return err
+	}
+	if !hmac.Equal(serverMac, mac(secret, challenge)) {
+		return errAuth
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package direct
+
+import (
+	"crypto/sha256"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestHandshake(t *testing.T) {
+
+	tests := []struct {
+		name         string
+		serverSecret string
+		clientSecret string
+		ok           bool
+	}{
+		{name: "same secret", serverSecret: "s3cr3t", clientSecret: "s3cr3t", ok: true},
+		{name: "wrong secret", serverSecret: "s3cr3t", clientSecret: "guess", ok: false},
+		{name: "missing secret", serverSecret: "s3cr3t", clientSecret: "", ok: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, c := net.Pipe()
+			defer s.Close()
+			defer c.Close()
+
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- serverHandshake(s, tc.serverSecret)
+			}()
+
+			clientErr := clientHandshake(c, tc.clientSecret)
+			serverErr := <-errCh
+
+			if tc.ok && (clientErr != nil || serverErr != nil) {
+				t.Fatalf("handshake failed (client: %v, server: %v)", clientErr, serverErr)
+			}
+			if !tc.ok && (clientErr != errAuth || serverErr != errAuth) {
+				t.Fatalf("expected %v (client: %v, server: %v)", errAuth, clientErr, serverErr)
+			}
+		})
+	}
+}
+
+func TestHandshakeImpostor(t *testing.T) {
+
+	s, c := net.Pipe()
+	defer s.Close()
+	defer c.Close()
+
+	// a server which doesn't know the secret accepts every client
+	go func() {
+		s.Write(append(append([]byte{}, authMagic...), make([]byte, challengeSize)...))
+		io.ReadFull(s, make([]byte, sha256.Size+challengeSize))
+		s.Write(append([]byte{authOK}, make([]byte, sha256.Size)...))
+	}()
+
+	if err := clientHandshake(c, "s3cr3t"); err != errAuth {
+		t.Fatalf("expected %v, got %v", errAuth, err)
+	}
+}
+
+func TestHandshakeProtocol(t *testing.T) {
+
+	s, c := net.Pipe()
+	defer s.Close()
+	defer c.Close()
+
+	go s.Write(make([]byte, len(authMagic)+challengeSize))
+
+	if err := clientHandshake(c, ""); err == nil {
+		t.Fatal("expected an error for an unsupported protocol")
+	}
+}
@@ -0,0 +1,238 @@
+package direct
+
+import (
+	"log"
+	"sync"
+
+	"github.com/asim/go-micro/v3/broker"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+)
+
+// Broker implements the go-micro broker.Broker interface on top of the
+// point-to-point connections of the direct mode. Messages are only sent
+// to the peers which have subscribed to the topic.
+type Broker struct {
+	sync.RWMutex
+	options broker.Options
+	peers   map[*peer]struct{}
+	subs    map[string][]*subscriber
+}
+
+// NewBroker returns a Broker without any connected peers.
+func NewBroker(opts ...broker.Option) *Broker {
+	b := &Broker{
+		peers: make(map[*peer]struct{}),
+		subs:  make(map[string][]*subscriber),
+	}
+	b.Init(opts...)
+	return b
+}
+
+// Init sets the broker options.
+func (b *Broker) Init(opts ...broker.Option) error {
+	for _, o := range opts {
+		o(&b.options)
+	}
+	return nil
+}
+
+// Options returns the broker options.
+func (b *Broker) Options() broker.Options {
+	return b.options
+}
+
+// Address returns the addresses of the connected peers.
+func (b *Broker) Address() string {
+	b.RLock()
+	defer b.RUnlock()
+	for p := range b.peers {
+		return p.conn.RemoteAddr().String()
+	}
+	return ""
+}
+
+// Connect is a no-op; peers are added when a connection is established.
+func (b *Broker) Connect() error {
+	return nil
+}
+
+// Disconnect closes the connections to all peers.
+func (b *Broker) Disconnect() error {
+	b.Lock()
+	peers := b.peers
+	b.peers = make(map[*peer]struct{})
+	b.Unlock()
+
+	for p := range peers {
+		p.close()
+	}
+	return nil
+}
+
+// Publish sends the message to all peers which have subscribed to the topic.
+// The message is queued for each peer; it is dropped for peers which don't
+// keep up with reading their messages.
+func (b *Broker) Publish(topic string, m *broker.Message, opts ...broker.PublishOption) error {
+
+	env := &sbAudio.Envelope{
+		Type:  sbAudio.Envelope_publish,
+		Topic: topic,
+		Body:  m.Body,
+	}
+
+	b.RLock()
+	defer b.RUnlock()
+
+	for p := range b.peers {
+		if !p.subscribed(topic) {
+			continue
+		}
+		if err := p.publish(env); err != nil {
+			log.Printf("direct: unable to publish to %s: %v\n", p.conn.RemoteAddr(), err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a handler for the messages published by the peers
+// on the topic.
+func (b *Broker) Subscribe(topic string, h broker.Handler, opts ...broker.SubscribeOption) (broker.Subscriber, error) {
+
+	sub := &subscriber{
+		broker:  b,
+		topic:   topic,
+		handler: h,
+		options: broker.NewSubscribeOptions(opts...),
+	}
+
+	b.Lock()
+	b.subs[topic] = append(b.subs[topic], sub)
+	b.Unlock()
+
+	b.sendAll(sbAudio.Envelope_subscribe, topic)
+
+	return sub, nil
+}
+
+// String returns the name of the broker implementation.
+func (b *Broker) String() string {
+	return "direct"
+}
+
+// addPeer adds a peer and informs it about our subscriptions
+func (b *Broker) addPeer(p *peer) {
+	b.Lock()
+	defer b.Unlock()
+
+	b.peers[p] = struct{}{}
+
+	for topic, subs := range b.subs {
+		for range subs {
+			p.send(&sbAudio.Envelope{
+				Type:  sbAudio.Envelope_subscribe,
+				Topic: topic,
+			})
+		}
+	}
+}
+
+// removePeer removes a (disconnected) peer
+func (b *Broker) removePeer(p *peer) {
+	b.Lock()
+	defer b.Unlock()
+	delete(b.peers, p)
+}
+
+// sendAll sends a message without body to all peers
+func (b *Broker) sendAll(t sbAudio.Envelope_Type, topic string) {
+	b.RLock()
+	defer b.RUnlock()
+
+	for p := range b.peers {
+		p.send(&sbAudio.Envelope{
+			Type:  t,
+			Topic: topic,
+		})
+	}
+}
+
+// deliver passes a message published by a peer on to the subscribers
+func (b *Broker) deliver(topic string, body []byte) {
+	b.RLock()
+	subs := b.subs[topic]
+	b.RUnlock()
+
+	for _, sub := range subs {
+		ev := &event{
+			topic:   topic,
+			message: &broker.Message{Body: body},
+		}
+		if err := sub.handler(ev); err != nil {
+			ev.err = err
+			if eh := b.options.ErrorHandler; eh != nil {
+				eh(ev)
+			}
+		}
+	}
+}
+
+// unsubscribe removes a subscriber
+func (b *Broker) unsubscribe(sub *subscriber) {
+	b.Lock()
+	subs := b.subs[sub.topic]
+	for i, s := range subs {
+		if s == sub {
+			b.subs[sub.topic] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	if len(b.subs[sub.topic]) == 0 {
+		delete(b.subs, sub.topic)
+	}
+	b.Unlock()
+
+	b.sendAll(sbAudio.Envelope_unsubscribe, sub.topic)
+}
+
+type subscriber struct {
+	broker  *Broker
+	topic   string
+	handler broker.Handler
+	options broker.SubscribeOptions
+}
+
+func (s *subscriber) Options() broker.SubscribeOptions {
+	return s.options
+}
+
+func (s *subscriber) Topic() string {
+	return s.topic
+}
+
+func (s *subscriber) Unsubscribe() error {
+	s.broker.unsubscribe(s)
+	return nil
+}
+
+type event struct {
+	topic   string
+	message *broker.Message
+	err     error
+}
+
+func (e *event) Topic() string {
+	return e.topic
+}
+
+func (e *event) Message() *broker.Message {
+	return e.message
+}
+
+func (e *event) Ack() error {
+	return nil
+}
+
+func (e *event) Error() error {
+	return e.err
+}
@@ -0,0 +1,218 @@
+package direct
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/asim/go-micro/v3/client"
+	"github.com/asim/go-micro/v3/codec"
+	"github.com/golang/protobuf/proto"
+)
+
+// Client implements the go-micro client.Client interface on top of a
+// point-to-point connection to a direct Server. The RPC requests and the
+// messages of the client's Broker are sent over the same connection.
+type Client struct {
+	options client.Options
+	peer    *peer
+	broker  *Broker
+}
+
+// Dial connects to a direct Server at the given address (host:port) and
+// authenticates with the shared secret.
+func Dial(address, secret string, timeout time.Duration, opts ...client.Option) (*Client, error) {
+
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("direct: %v", err)
+	}
+
+	if err := clientHandshake(conn, secret); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("direct: handshake with %s failed: %v", address, err)
+	}
+
+	c := &Client{
+		broker: NewBroker(),
+	}
+
+	c.options = client.Options{
+		ContentType: "application/protobuf",
+		CallOptions: client.CallOptions{
+			RequestTimeout: client.DefaultRequestTimeout,
+		},
+	}
+	c.Init(opts...)
+	c.options.Broker = c.broker
+
+	c.peer = newPeer(conn, c.broker, nil)
+	c.broker.addPeer(c.peer)
+
+	go func() {
+		c.peer.readLoop()
+		c.broker.removePeer(c.peer)
+	}()
+
+	return c, nil
+}
+
+// Done returns a channel which is closed when the connection to the
+// server is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.peer.done()
+}
+
+// Close closes the connection to the server.
+func (c *Client) Close() error {
+	return c.peer.close()
+}
+
+// Init sets the client options.
+func (c *Client) Init(opts ...client.Option) error {
+	for _, o := range opts {
+		o(&c.options)
+	}
+	return nil
+}
+
+// Options returns the client options. The broker of the options
+// exchanges its messages through the connection to the server.
+func (c *Client) Options() client.Options {
+	return c.options
+}
+
+// NewMessage creates a message which can be published through the client.
+func (c *Client) NewMessage(topic string, msg interface{}, opts ...client.MessageOption) client.Message {
+	return &message{
+		topic:   topic,
+		payload: msg,
+	}
+}
+
+// NewRequest creates a RPC request. The service name is ignored since
+// the connection leads to a single server.
+func (c *Client) NewRequest(service, endpoint string, req interface{}, reqOpts ...client.RequestOption) client.Request {
+	return &request{
+		service:  service,
+		endpoint: endpoint,
+		body:     req,
+	}
+}
+
+// Call executes a RPC request on the server. The request and response
+// must be protobuf messages.
+func (c *Client) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
+
+	callOpts := c.options.CallOptions
+	for _, o := range opts {
+		o(&callOpts)
+	}
+
+	in, ok := req.Body().(proto.Message)
+	if !ok {
+		return fmt.Errorf("direct: request body is not a protobuf message")
+	}
+	out, ok := rsp.(proto.Message)
+	if !ok {
+		return fmt.Errorf("direct: response is not a protobuf message")
+	}
+
+	body, err := proto.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	if callOpts.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callOpts.RequestTimeout)
+		defer cancel()
+	}
+
+	res, err := c.peer.call(ctx, req.Endpoint(), body)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(res, out)
+}
+
+// Stream is not supported.
+func (c *Client) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
+	return nil, errors.New("direct: streams are not supported")
+}
+
+// Publish publishes a protobuf message through the client's broker.
+func (c *Client) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+
+	payload, ok := msg.Payload().(proto.Message)
+	if !ok {
+		return fmt.Errorf("direct: payload is not a protobuf message")
+	}
+
+	body, err := proto.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	return c.broker.Publish(msg.Topic(), &broker.Message{Body: body})
+}
+
+// String returns the name of the client implementation.
+func (c *Client) String() string {
+	return "direct"
+}
+
+type message struct {
+	topic   string
+	payload interface{}
+}
+
+func (m *message) Topic() string {
+	return m.topic
+}
+
+func (m *message) Payload() interface{} {
+	return m.payload
+}
+
+func (m *message) ContentType() string {
+	return "application/protobuf"
+}
+
+type request struct {
+	service  string
+	endpoint string
+	body     interface{}
+}
+
+func (r *request) Service() string {
+	return r.service
+}
+
+func (r *request) Method() string {
+	return r.endpoint
+}
+
+func (r *request) Endpoint() string {
+	return r.endpoint
+}
+
+func (r *request) ContentType() string {
+	return "application/protobuf"
+}
+
+func (r *request) Body() interface{} {
+	return r.body
+}
+
+func (r *request) Codec() codec.Writer {
+	return nil
+}
+
+func (r *request) Stream() bool {
+	return false
+}
@@ -0,0 +1,306 @@
+package direct
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"github.com/golang/protobuf/proto"
+)
+
+// maxMessageSize limits the size of a single message on the connection
+const maxMessageSize = 1 << 20
+
+// writeTimeout is the time after which a peer which doesn't read its
+// messages is disconnected
+const writeTimeout = time.Second * 5
+
+// sendQueueSize is the number of messages which can be queued for a peer
+// before publications are dropped
+const sendQueueSize = 64
+
+// errClosed is returned when the connection to the peer has been closed
+var errClosed = errors.New("direct: connection closed")
+
+// errQueueFull is returned when a message couldn't be queued because the
+// peer doesn't keep up with reading its messages
+var errQueueFull = errors.New("direct: send queue full")
+
+// requestHandler is executed for RPC requests received from a peer
+type requestHandler func(endpoint string, body []byte) ([]byte, error)
+
+// peer is the remote end of a point-to-point connection. Messages are
+// sent as length prefixed (uint32, big endian) sbAudio.Envelope. They are
+// queued and written by the peer's writeLoop, so that a slow peer doesn't
+// block the senders.
+type peer struct {
+	sync.Mutex
+	conn      net.Conn
+	writer    *bufio.Writer
+	sendCh    chan []byte
+	dropped   int // publications dropped since the queue was full
+	broker    *Broker
+	onRequest requestHandler
+	lastID    uint64
+	pending   map[uint64]chan *sbAudio.Envelope
+	topics    map[string]int // topics the peer has subscribed to
+	doneCh    chan struct{}
+	doneOnce  sync.Once
+}
+
+func newPeer(conn net.Conn, b *Broker, onRequest requestHandler) *peer {
+	p := &peer{
+		conn:      conn,
+		writer:    bufio.NewWriter(conn),
+		sendCh:    make(chan []byte, sendQueueSize),
+		broker:    b,
+		onRequest: onRequest,
+		pending:   make(map[uint64]chan *sbAudio.Envelope),
+		topics:    make(map[string]int),
+		doneCh:    make(chan struct{}),
+	}
+
+	go p.writeLoop()
+
+	return p
+}
+
+// enqueue marshals an envelope and adds it to the send queue without
+// blocking.
+func (p *peer) enqueue(env *sbAudio.Envelope) error {
+
+	data, err := proto.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-p.doneCh:
+		return errClosed
+	default:
+	}
+
+	select {
+	case p.sendCh <- data:
+		return nil
+	default:
+		return errQueueFull
+	}
+}
+
+// send queues a control message (subscription, request or response).
+// Since these messages must not get lost, the peer is disconnected if its
+// send queue is full.
+func (p *peer) send(env *sbAudio.Envelope) error {
+	err := p.enqueue(env)
+	if err == errQueueFull {
+		p.close()
+	}
+	return err
+}
+
+// publish queues a publication (e.g. an audio frame). If the send queue
+// is full, the publication is dropped.
+func (p *peer) publish(env *sbAudio.Envelope) error {
+	err := p.enqueue(env)
+
+	p.Lock()
+	defer p.Unlock()
+
+	switch {
+	case err == errQueueFull:
+		if p.dropped == 0 {
+			log.Printf("direct: send queue of %s full; dropping publications\n", p.conn.RemoteAddr())
+		}
+		p.dropped++
+		return nil
+	case err == nil && p.dropped > 0:
+		log.Printf("direct: %d publications to %s dropped\n", p.dropped, p.conn.RemoteAddr())
+		p.dropped = 0
+	}
+	return err
+}
+
+// writeLoop writes the queued messages to the connection until it is
+// closed. The peer is disconnected if a message can't be written within
+// the writeTimeout.
+func (p *peer) writeLoop() {
+
+	var size [4]byte
+
+	for {
+		select {
+		case data := <-p.sendCh:
+			binary.BigEndian.PutUint32(size[:], uint32(len(data)))
+
+			p.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+			if _, err := p.writer.Write(size[:]); err != nil {
+				p.close()
+				return
+			}
+			if _, err := p.writer.Write(data); err != nil {
+				p.close()
+				return
+			}
+			// write the queued messages at once
+			if len(p.sendCh) > 0 {
+				continue
+			}
+			if err := p.writer.Flush(); err != nil {
+				p.close()
+				return
+			}
+		case <-p.doneCh:
+			return
+		}
+	}
+}
+
+// call sends a request to the peer and waits for the response
+func (p *peer) call(ctx context.Context, endpoint string, body []byte) ([]byte, error) {
+
+	resCh := make(chan *sbAudio.Envelope, 1)
+
+	p.Lock()
+	p.lastID++
+	id := p.lastID
+	p.pending[id] = resCh
+	p.Unlock()
+
+	defer func() {
+		p.Lock()
+		delete(p.pending, id)
+		p.Unlock()
+	}()
+
+	req := &sbAudio.Envelope{
+		Type:  sbAudio.Envelope_request,
+		Topic: endpoint,
+		Id:    id,
+		Body:  body,
+	}
+
+	if err := p.send(req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-resCh:
+		if len(res.GetError()) > 0 {
+			return nil, errors.New(res.GetError())
+		}
+		return res.GetBody(), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.doneCh:
+		return nil, errClosed
+	}
+}
+
+// readLoop is a blocking function which reads the messages from the
+// connection until it is closed.
+func (p *peer) readLoop() error {
+
+	defer p.close()
+
+	reader := bufio.NewReader(p.conn)
+	var size [4]byte
+
+	for {
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return err
+		}
+
+		n := binary.BigEndian.Uint32(size[:])
+		if n > maxMessageSize {
+			return fmt.Errorf("direct: message too large (%d bytes)", n)
+		}
+
+		data := make([]byte, n)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return err
+		}
+
+		env := &sbAudio.Envelope{}
+		if err := proto.Unmarshal(data, env); err != nil {
+			return err
+		}
+
+		switch env.GetType() {
+		case sbAudio.Envelope_publish:
+			p.broker.deliver(env.GetTopic(), env.GetBody())
+		case sbAudio.Envelope_subscribe:
+			p.Lock()
+			p.topics[env.GetTopic()]++
+			p.Unlock()
+		case sbAudio.Envelope_unsubscribe:
+			p.Lock()
+			if p.topics[env.GetTopic()]--; p.topics[env.GetTopic()] <= 0 {
+				delete(p.topics, env.GetTopic())
+			}
+			p.Unlock()
+		case sbAudio.Envelope_request:
+			go p.handleRequest(env)
+		case sbAudio.Envelope_response:
+			p.Lock()
+			resCh, ok := p.pending[env.GetId()]
+			p.Unlock()
+			if ok {
+				resCh <- env
+			}
+		}
+	}
+}
+
+// subscribed returns true if the peer has subscribed to the topic
+func (p *peer) subscribed(topic string) bool {
+	p.Lock()
+	defer p.Unlock()
+	return p.topics[topic] > 0
+}
+
+// handleRequest executes the request handler and sends the response
+// back to the peer.
+func (p *peer) handleRequest(req *sbAudio.Envelope) {
+
+	res := &sbAudio.Envelope{
+		Type:  sbAudio.Envelope_response,
+		Topic: req.GetTopic(),
+		Id:    req.GetId(),
+	}
+
+	if p.onRequest == nil {
+		res.Error = fmt.Sprintf("unknown endpoint %s", req.GetTopic())
+	} else {
+		body, err := p.onRequest(req.GetTopic(), req.GetBody())
+		if err != nil {
+			res.Error = err.Error()
+		}
+		res.Body = body
+	}
+
+	p.send(res) // the connection is closed on errors
+}
+
+// done returns a channel which is closed when the connection is closed
+func (p *peer) done() <-chan struct{} {
+	return p.doneCh
+}
+
+func (p *peer) close() error {
+	var err error
+	p.doneOnce.Do(func() {
+		close(p.doneCh)
+		err = p.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,59 @@
+package direct
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+)
+
+func TestSlowPeer(t *testing.T) {
+
+	// nobody reads from the other end of the pipe
+	conn, other := net.Pipe()
+	defer other.Close()
+
+	p := newPeer(conn, NewBroker(), nil)
+	defer p.close()
+
+	env := &sbAudio.Envelope{
+		Type:  sbAudio.Envelope_publish,
+		Topic: "audio",
+		Body:  make([]byte, 100),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < sendQueueSize*4; i++ {
+			if err := p.publish(env); err != nil {
+				t.Error(err)
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publish blocked")
+	}
+
+	p.Lock()
+	dropped := p.dropped
+	p.Unlock()
+	if dropped == 0 {
+		t.Error("expected dropped publications")
+	}
+
+	// control messages must not get lost; the peer is disconnected
+	if err := p.send(&sbAudio.Envelope{Type: sbAudio.Envelope_subscribe, Topic: "audio"}); err != errQueueFull {
+		t.Errorf("expected %v, got %v", errQueueFull, err)
+	}
+
+	select {
+	case <-p.done():
+	case <-time.After(time.Second):
+		t.Error("peer not disconnected")
+	}
+}
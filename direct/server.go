@@ -0,0 +1,182 @@
+package direct
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"reflect"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Server accepts the point-to-point connections of clients. RPC requests
+// received from the clients are dispatched to the registered handlers;
+// publications are exchanged through the server's Broker.
+type Server struct {
+	sync.RWMutex
+	listener net.Listener
+	secret   string
+	broker   *Broker
+	methods  map[string]method
+}
+
+// method is a RPC method of a registered handler with the signature
+// func(context.Context, *In, *Out) error
+type method struct {
+	fn  reflect.Value
+	in  reflect.Type
+	out reflect.Type
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+	protoType   = reflect.TypeOf((*proto.Message)(nil)).Elem()
+)
+
+// Listen opens a TCP listener on the given address (e.g. "127.0.0.1:7700").
+// Only clients which know the shared secret are accepted.
+func Listen(address, secret string) (*Server, error) {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("direct: %v", err)
+	}
+
+	s := &Server{
+		listener: l,
+		secret:   secret,
+		broker:   NewBroker(),
+		methods:  make(map[string]method),
+	}
+
+	return s, nil
+}
+
+// Broker returns the broker through which messages are exchanged with
+// the connected clients.
+func (s *Server) Broker() *Broker {
+	return s.broker
+}
+
+// Addr returns the address of the listener.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Handle registers the exported methods of hdlr which have the signature
+// func(context.Context, *In, *Out) error (with In and Out being protobuf
+// messages) as RPC endpoints "<name>.<Method>", following the go-micro
+// naming (e.g. Server.GetCapabilities).
+func (s *Server) Handle(name string, hdlr interface{}) error {
+
+	v := reflect.ValueOf(hdlr)
+	t := v.Type()
+
+	s.Lock()
+	defer s.Unlock()
+
+	registered := 0
+
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		mt := m.Type // includes the receiver
+
+		if mt.NumIn() != 4 || mt.NumOut() != 1 {
+			continue
+		}
+		if mt.In(1) != contextType || mt.Out(0) != errorType {
+			continue
+		}
+		if !mt.In(2).Implements(protoType) || !mt.In(3).Implements(protoType) {
+			continue
+		}
+		if mt.In(2).Kind() != reflect.Ptr || mt.In(3).Kind() != reflect.Ptr {
+			continue
+		}
+
+		s.methods[name+"."+m.Name] = method{
+			fn:  v.Method(i),
+			in:  mt.In(2).Elem(),
+			out: mt.In(3).Elem(),
+		}
+		registered++
+	}
+
+	if registered == 0 {
+		return fmt.Errorf("direct: %T has no suitable methods", hdlr)
+	}
+
+	return nil
+}
+
+// Serve is a blocking function which accepts the connections of the
+// clients until the listener is closed.
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close closes the listener and all client connections.
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.broker.Disconnect()
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+
+	if err := serverHandshake(conn, s.secret); err != nil {
+		log.Printf("direct: client %s rejected (%v)\n", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	log.Println("direct: client connected from", conn.RemoteAddr())
+
+	p := newPeer(conn, s.broker, s.handleRequest)
+	s.broker.addPeer(p)
+
+	err := p.readLoop()
+	s.broker.removePeer(p)
+
+	log.Printf("direct: client %s disconnected (%v)\n", conn.RemoteAddr(), err)
+}
+
+// handleRequest executes the RPC method registered for the endpoint
+func (s *Server) handleRequest(endpoint string, body []byte) ([]byte, error) {
+
+	s.RLock()
+	m, ok := s.methods[endpoint]
+	s.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown endpoint %s", endpoint)
+	}
+
+	in := reflect.New(m.in)
+	out := reflect.New(m.out)
+
+	if err := proto.Unmarshal(body, in.Interface().(proto.Message)); err != nil {
+		return nil, err
+	}
+
+	res := m.fn.Call([]reflect.Value{
+		reflect.ValueOf(context.Background()),
+		in,
+		out,
+	})
+
+	if err, _ := res[0].Interface().(error); err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(out.Interface().(proto.Message))
+}
@@ -0,0 +1,235 @@
+// Package keyer provides the storage of the voice keyer (DVK) messages.
+// The messages are WAV, FLAC or Ogg Opus files which are kept in a directory
+// on disk.
+package keyer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio/audiofile"
+)
+
+// MaxSize is the maximum size (in bytes) of a voice keyer message
+const MaxSize = 20 << 20
+
+// extensions are the file extensions of the voice keyer messages
+var extensions = []string{".wav", ".flac", ".opus"}
+
+// signatures mark the beginning of FLAC and Ogg files
+var (
+	flacSignature = []byte("fLaC")
+	oggSignature  = []byte("OggS")
+)
+
+// validName restricts the message names, so that they can't escape
+// from the directory
+var validName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// ErrInvalidName is returned if the name of a message contains characters
+// other than letters, digits, '-' and '_' or if it is too long.
+var ErrInvalidName = errors.New("invalid message name; allowed are up to 32 letters, digits, '-' and '_'")
+
+// Message contains the properties of a voice keyer message.
+type Message struct {
+	Name       string        `json:"name"`
+	Duration   time.Duration `json:"duration"`
+	Samplerate int           `json:"samplerate"`
+	Channels   int           `json:"channels"`
+	Size       int64         `json:"size"`
+}
+
+// Store manages the voice keyer messages in a directory.
+type Store struct {
+	sync.Mutex
+	directory string
+}
+
+// NewStore returns a Store for the messages in the directory. The
+// directory is created if it doesn't exist.
+func NewStore(directory string) (*Store, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{directory: directory}, nil
+}
+
+// Path returns the path of the message's audio file.
+func (s *Store) Path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", ErrInvalidName
+	}
+
+	for _, ext := range extensions {
+		path := filepath.Join(s.directory, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("unknown message %s", name)
+}
+
+// Messages returns the messages in the store, sorted by name. Files which
+// are no valid audio files are skipped.
+func (s *Store) Messages() ([]Message, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	files, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := []Message{}
+	for _, f := range files {
+		ext := filepath.Ext(f.Name())
+		if f.IsDir() || !isExtension(ext) {
+			continue
+		}
+		name := strings.TrimSuffix(f.Name(), ext)
+		if !validName.MatchString(name) {
+			continue
+		}
+		msg, err := readMessage(filepath.Join(s.directory, f.Name()), "")
+		if err != nil {
+			continue
+		}
+		msg.Name = name
+		msgs = append(msgs, msg)
+	}
+
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].Name < msgs[j].Name
+	})
+
+	return msgs, nil
+}
+
+// Save stores the WAV, FLAC or Ogg Opus file read from r as the message
+// with the given name. An existing message with the same name is replaced.
+// The file is only stored if it is a valid audio file of at most MaxSize
+// bytes.
+func (s *Store) Save(name string, r io.Reader) (Message, error) {
+	if !validName.MatchString(name) {
+		return Message{}, ErrInvalidName
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	tmp, err := os.CreateTemp(s.directory, ".upload-*")
+	if err != nil {
+		return Message{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, io.LimitReader(r, MaxSize+1))
+	tmp.Close()
+	if err != nil {
+		return Message{}, err
+	}
+	if n > MaxSize {
+		return Message{}, fmt.Errorf("message exceeds the maximum size of %d bytes", MaxSize)
+	}
+
+	format, ext, err := fileFormat(tmp.Name())
+	if err != nil {
+		return Message{}, err
+	}
+
+	msg, err := readMessage(tmp.Name(), format)
+	if err != nil {
+		return Message{}, err
+	}
+	msg.Name = name
+
+	if err := os.Rename(tmp.Name(), filepath.Join(s.directory, name+ext)); err != nil {
+		return Message{}, err
+	}
+
+	// remove the message in the other formats
+	for _, e := range extensions {
+		if e != ext {
+			os.Remove(filepath.Join(s.directory, name+e))
+		}
+	}
+
+	return msg, nil
+}
+
+// Delete removes a message from the store.
+func (s *Store) Delete(name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// readMessage returns the properties of an audio file. If format is
+// empty, the format is derived from the extension of the file.
+func readMessage(path, format string) (Message, error) {
+	src, err := audiofile.NewSource(path, audiofile.Format(format))
+	if err != nil {
+		return Message{}, err
+	}
+	defer src.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Duration:   src.Duration(),
+		Samplerate: int(src.Samplerate()),
+		Channels:   src.Channels(),
+		Size:       info.Size(),
+	}, nil
+}
+
+// fileFormat determines the format (and the extension) of an uploaded
+// file by its signature. Files which are neither FLAC nor Ogg files are
+// expected to be WAV files.
+func fileFormat(path string) (string, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	head := make([]byte, len(flacSignature))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return "", "", errors.New("invalid audio file")
+	}
+
+	switch {
+	case bytes.Equal(head, flacSignature):
+		return audiofile.FLAC, ".flac", nil
+	case bytes.Equal(head, oggSignature):
+		return audiofile.OPUS, ".opus", nil
+	}
+	return audiofile.WAV, ".wav", nil
+}
+
+// isExtension checks if ext is the extension of a message.
+func isExtension(ext string) bool {
+	for _, e := range extensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
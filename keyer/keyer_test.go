@@ -0,0 +1,198 @@
+package keyer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dh1tw/remoteAudio/audio/audiofile"
+	goaudio "github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// wavFile returns a short mono 16 bit WAV file.
+func wavFile(t *testing.T) []byte {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.wav")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc := wav.NewEncoder(f, 8000, 16, 1, 1)
+	buf := &goaudio.IntBuffer{
+		Format: &goaudio.Format{NumChannels: 1, SampleRate: 8000},
+		Data:   make([]int, 800),
+	}
+	if err := enc.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// files returns the names of the files in dir.
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileFormat(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format string
+		ext    string
+		err    bool
+	}{
+		{name: "flac", data: []byte("fLaC\x00\x00\x00\x22"), format: audiofile.FLAC, ext: ".flac"},
+		{name: "ogg", data: []byte("OggS\x00\x02"), format: audiofile.OPUS, ext: ".opus"},
+		{name: "wav", data: []byte("RIFF\x24\x00\x00\x00WAVE"), format: audiofile.WAV, ext: ".wav"},
+		{name: "unknown signature", data: []byte("this is not an audio file"), format: audiofile.WAV, ext: ".wav"},
+		{name: "signature is case sensitive", data: []byte("flac\x00\x00"), format: audiofile.WAV, ext: ".wav"},
+		{name: "too short", data: []byte("fLa"), err: true},
+		{name: "empty", data: []byte{}, err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "upload")
+			if err := os.WriteFile(path, tc.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			format, ext, err := fileFormat(path)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", format)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if format != tc.format || ext != tc.ext {
+				t.Errorf("got %s (%s), expected %s (%s)", format, ext, tc.format, tc.ext)
+			}
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		existing []string // files in the store before the upload
+		msg      string
+		data     []byte
+		err      bool
+		files    []string // files in the store after the upload
+	}{
+		{
+			name:  "new message",
+			msg:   "cq",
+			data:  wavFile(t),
+			files: []string{"cq.wav"},
+		},
+		{
+			name:     "replaces the other formats",
+			existing: []string{"cq.flac", "cq.opus"},
+			msg:      "cq",
+			data:     wavFile(t),
+			files:    []string{"cq.wav"},
+		},
+		{
+			name:     "replaces the same format",
+			existing: []string{"cq.wav"},
+			msg:      "cq",
+			data:     wavFile(t),
+			files:    []string{"cq.wav"},
+		},
+		{
+			name:     "other messages are kept",
+			existing: []string{"cq2.flac", "qrz.opus"},
+			msg:      "cq",
+			data:     wavFile(t),
+			files:    []string{"cq.wav", "cq2.flac", "qrz.opus"},
+		},
+		{
+			name:     "invalid file keeps the message",
+			existing: []string{"cq.flac"},
+			msg:      "cq",
+			data:     []byte("this is not an audio file"),
+			err:      true,
+			files:    []string{"cq.flac"},
+		},
+		{
+			name:     "oversize file keeps the message",
+			existing: []string{"cq.opus"},
+			msg:      "cq",
+			data:     make([]byte, MaxSize+1),
+			err:      true,
+			files:    []string{"cq.opus"},
+		},
+		{
+			name:  "invalid name",
+			msg:   "../cq",
+			data:  wavFile(t),
+			err:   true,
+			files: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			s, err := NewStore(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range tc.existing {
+				if err := os.WriteFile(filepath.Join(dir, f), []byte("old"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			msg, err := s.Save(tc.msg, bytes.NewReader(tc.data))
+			if tc.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if msg.Name != tc.msg || msg.Size != int64(len(tc.data)) {
+					t.Errorf("unexpected message %+v", msg)
+				}
+			}
+
+			// the rejected uploads must not leave any files behind
+			if got := files(t, dir); strings.Join(got, ",") != strings.Join(tc.files, ",") {
+				t.Errorf("got files %v, expected %v", got, tc.files)
+			}
+		})
+	}
+}
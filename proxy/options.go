@@ -1,19 +1,22 @@
 package proxy
 
+import "github.com/dh1tw/remoteAudio/audiocodec"
+
 // Option is the type for a function option
 type Option func(*Options)
 
 // Options is the data structure which holds the particular Options values.
 // The values are typically provided as functional options.
 type Options struct {
+	Codecs []audiocodec.Format
 }
 
-// Channels is a functional option to set the amount of channels to be used
-// with the audio device. Typically this is either Mono (1) or Stereo (2).
-// Make sure that your audio device supports the specified amount of channels.
-
-// func Channels(chs int) Option {
-// 	return func(args *Options) {
-// 		args.Channels = chs
-// 	}
-// }
+// Codecs is a functional option to set the audio codecs and the format
+// of the encoded audio (in order of preference) which can be used for
+// sending audio to the remote audio server. By default all codecs
+// registered in the audiocodec registry are used.
+func Codecs(codecs ...audiocodec.Format) Option {
+	return func(args *Options) {
+		args.Codecs = codecs
+	}
+}
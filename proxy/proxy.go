@@ -9,6 +9,7 @@ import (
 
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/asim/go-micro/v3/client"
+	"github.com/dh1tw/remoteAudio/audiocodec"
 	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
 	"github.com/golang/protobuf/proto"
 )
@@ -19,6 +20,7 @@ import (
 // implementation is based on the micro.mu microservice framework.
 type AudioServer struct {
 	sync.RWMutex
+	options        Options
 	name           string
 	index          int //static index for displaying several servers consistenly in the GUI
 	serviceName    string
@@ -26,8 +28,16 @@ type AudioServer struct {
 	rpc            sbAudio.ServerService
 	stateSub       broker.Subscriber
 	rxAddress      string
+	rxProfiles     []RxProfile
+	rxProfile      string // selected rx profile; empty for the default stream
 	txAddress      string
 	stateAddress   string
+	rxFbAddress    string                  // where the server listens for reception reports
+	txFbAddress    string                  // where the server publishes reception reports
+	mediaAddress   string                  // UDP address of the server's RTP media path
+	codecs         []audiocodec.Parameters // codecs supported by the remote audio server
+	codec          string                  // negotiated codec for sending audio
+	rxCodec        string
 	rxOn           bool
 	txUser         string
 	latency        int
@@ -37,6 +47,15 @@ type AudioServer struct {
 	doneOnce       sync.Once
 }
 
+// RxProfile is an additional encoding of the audio stream offered by the
+// remote audio server, typically with a different bitrate.
+type RxProfile struct {
+	Name    string
+	Address string
+	Codec   string
+	Bitrate int
+}
+
 // NewAudioServer is the constructor for the Audioserver proxy. The communication
 // with the remote audio server is done through a micro client. In case the
 // object disappears the doneCh will be closed.
@@ -56,6 +75,16 @@ func NewAudioServer(name string, client client.Client, doneCh chan struct{}, opt
 		doneCh:       doneCh,
 	}
 
+	for _, option := range opts {
+		option(&as.options)
+	}
+
+	if len(as.options.Codecs) == 0 {
+		for _, name := range audiocodec.Names() {
+			as.options.Codecs = append(as.options.Codecs, audiocodec.Format{Name: name})
+		}
+	}
+
 	as.rpc = sbAudio.NewServerService(as.serviceName, as.client)
 
 	if err := as.getCapabilities(); err != nil {
@@ -181,13 +210,58 @@ func (as *AudioServer) RxOn() bool {
 }
 
 // RxAddress returns the address on which the remote audio server is sending
-// out the audio.
+// out the audio. If an rx profile has been selected, the address of the
+// profile will be returned.
 func (as *AudioServer) RxAddress() string {
 	as.RLock()
 	defer as.RUnlock()
+
+	for _, p := range as.rxProfiles {
+		if p.Name == as.rxProfile {
+			return p.Address
+		}
+	}
 	return as.rxAddress
 }
 
+// RxProfiles returns the rx profiles offered by the remote audio server.
+func (as *AudioServer) RxProfiles() []RxProfile {
+	as.RLock()
+	defer as.RUnlock()
+	return append([]RxProfile{}, as.rxProfiles...)
+}
+
+// RxProfile returns the name of the selected rx profile. An empty string
+// is returned if the default audio stream is used.
+func (as *AudioServer) RxProfile() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.rxProfile
+}
+
+// SelectRxProfile selects the rx profile with which the audio shall be
+// received. An empty string selects the default audio stream. Since the
+// profiles are published on different addresses, the caller has to
+// re-subscribe to RxAddress().
+func (as *AudioServer) SelectRxProfile(name string) error {
+	as.Lock()
+	defer as.Unlock()
+
+	if len(name) == 0 {
+		as.rxProfile = ""
+		return nil
+	}
+
+	for _, p := range as.rxProfiles {
+		if p.Name == name {
+			as.rxProfile = name
+			return nil
+		}
+	}
+
+	return fmt.Errorf("audio server %s doesn't provide rx profile '%s'", as.name, name)
+}
+
 // TxAddress returns the address on which the remote audio server is listening
 // for incoming audio to be transmitted.
 func (as *AudioServer) TxAddress() string {
@@ -196,6 +270,89 @@ func (as *AudioServer) TxAddress() string {
 	return as.txAddress
 }
 
+// Codecs returns the names of the audio codecs supported by the
+// remote audio server.
+func (as *AudioServer) Codecs() []string {
+	as.RLock()
+	defer as.RUnlock()
+	names := make([]string, 0, len(as.codecs))
+	for _, c := range as.codecs {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// Codec returns the name of the audio codec which has been negotiated
+// for sending audio to the remote audio server.
+func (as *AudioServer) Codec() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.codec
+}
+
+// RxCodec returns the name of the audio codec with which the remote
+// audio server is streaming audio.
+func (as *AudioServer) RxCodec() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.rxCodec
+}
+
+// RxFeedbackAddress returns the address on which the remote audio server
+// listens for reception reports about its audio stream. An empty string
+// is returned if the server doesn't support reception reports.
+func (as *AudioServer) RxFeedbackAddress() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.rxFbAddress
+}
+
+// TxFeedbackAddress returns the address on which the remote audio server
+// publishes reception reports about the audio streams it receives. An
+// empty string is returned if the server doesn't support reception reports.
+func (as *AudioServer) TxFeedbackAddress() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.txFbAddress
+}
+
+// MediaAddress returns the UDP address (host:port) of the remote audio
+// server's RTP media path. An empty string is returned if the server
+// only supports streaming audio through the broker.
+func (as *AudioServer) MediaAddress() string {
+	as.RLock()
+	defer as.RUnlock()
+	return as.mediaAddress
+}
+
+// OpenMediaSession opens a session on the RTP media path of the remote
+// audio server, through which the audio stream published on rxAddress
+// will be received. Calling it with the id of an existing session
+// changes the received audio stream. The session id and the UDP address
+// of the server are returned.
+func (as *AudioServer) OpenMediaSession(session uint32, rxAddress, userID string) (uint32, string, error) {
+	req := &sbAudio.MediaSessionRequest{
+		Session:   session,
+		RxAddress: rxAddress,
+		UserId:    userID,
+	}
+	res, err := as.rpc.OpenMediaSession(context.Background(), req)
+	if err != nil {
+		return 0, "", err
+	}
+	return res.GetSession(), res.GetMediaAddress(), nil
+}
+
+// CloseMediaSession closes a session on the RTP media path of the remote
+// audio server.
+func (as *AudioServer) CloseMediaSession(session uint32) error {
+	req := &sbAudio.MediaSessionRequest{
+		Session: session,
+	}
+	_, err := as.rpc.CloseMediaSession(context.Background(), req)
+	return err
+}
+
 // StartRxStream tells the remote audio server to start streaming audio.
 func (as *AudioServer) StartRxStream() error {
 	_, err := as.rpc.StartStream(context.Background(), &sbAudio.None{})
@@ -297,6 +454,62 @@ func (as *AudioServer) getCapabilities() error {
 		return fmt.Errorf("getCapabilities: StateUpdatesAddress empty")
 	}
 	as.index = int(caps.GetIndex())
+	as.rxFbAddress = caps.GetRxFeedbackAddress()
+	as.txFbAddress = caps.GetTxFeedbackAddress()
+	as.mediaAddress = caps.GetMediaAddress()
+
+	// servers which don't advertise their codecs only support opus
+	as.codecs = []audiocodec.Parameters{}
+	for _, c := range caps.GetCodecs() {
+		as.codecs = append(as.codecs, audiocodec.Parameters{
+			Name:        c.GetName(),
+			Samplerates: toInt(c.GetSamplingRates()),
+			BitDepths:   toInt(c.GetBitDepths()),
+			Channels:    toInt(c.GetChannels()),
+		})
+	}
+	if len(as.codecs) == 0 {
+		as.codecs = []audiocodec.Parameters{{Name: "opus"}}
+	}
+
+	format, err := audiocodec.Negotiate(as.options.Codecs, as.codecs)
+	if err != nil {
+		return fmt.Errorf("getCapabilities: %v", err)
+	}
+	as.codec = format.Name
+
+	as.rxProfiles = []RxProfile{}
+	for _, p := range caps.GetRxProfiles() {
+		if len(p.GetAddress()) == 0 {
+			continue
+		}
+		if c, ok := audiocodec.Lookup(p.GetCodec()); !ok || c.NewDecoder == nil {
+			continue // we won't be able to decode it
+		}
+		as.rxProfiles = append(as.rxProfiles, RxProfile{
+			Name:    p.GetName(),
+			Address: p.GetAddress(),
+			Codec:   p.GetCodec(),
+			Bitrate: int(p.GetBitrate()),
+		})
+	}
+
+	as.rxCodec = caps.GetRxCodec()
+	if len(as.rxCodec) == 0 {
+		as.rxCodec = "opus"
+	}
+	if c, ok := audiocodec.Lookup(as.rxCodec); !ok || c.NewDecoder == nil {
+		return fmt.Errorf("getCapabilities: unable to decode audio codec '%s'", as.rxCodec)
+	}
 
 	return nil
 }
+
+// toInt converts a slice of int32 into a slice of ints
+func toInt(values []int32) []int {
+	res := make([]int, 0, len(values))
+	for _, v := range values {
+		res = append(res, int(v))
+	}
+	return res
+}
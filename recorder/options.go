@@ -0,0 +1,64 @@
+package recorder
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// formats of the recordings
+const (
+	FormatWAV  = "wav"  // decoded audio
+	FormatFLAC = "flac" // decoded audio, lossless compressed
+	FormatOpus = "opus" // Ogg Opus; the encoded frames from the network
+)
+
+// Options contains the parameters for initializing a Recorder.
+type Options struct {
+	Format      string
+	Samplerate  float64
+	Channels    int
+	MaxSize     int64
+	MaxDuration time.Duration
+}
+
+// Format is a functional option to set the format of the recordings
+// (FormatWAV, FormatFLAC or FormatOpus). By default WAV files are recorded.
+func Format(f string) Option {
+	return func(args *Options) {
+		args.Format = f
+	}
+}
+
+// Samplerate is a functional option to set the samplerate of the
+// WAV / FLAC recordings. By default the audio is recorded with 48kHz.
+func Samplerate(s float64) Option {
+	return func(args *Options) {
+		args.Samplerate = s
+	}
+}
+
+// Channels is a functional option to set the amount of channels of the
+// WAV / FLAC recordings. By default the audio is recorded in mono.
+func Channels(chs int) Option {
+	return func(args *Options) {
+		args.Channels = chs
+	}
+}
+
+// MaxSize is a functional option to set the maximum size (in bytes) of
+// a file. When the size has been reached, a new file is started. 0
+// disables the size based rotation.
+func MaxSize(s int64) Option {
+	return func(args *Options) {
+		args.MaxSize = s
+	}
+}
+
+// MaxDuration is a functional option to set the maximum duration of a
+// file. When the duration has elapsed, a new file is started. 0 disables
+// the time based rotation.
+func MaxDuration(d time.Duration) Option {
+	return func(args *Options) {
+		args.MaxDuration = d
+	}
+}
@@ -0,0 +1,140 @@
+// Package recorder provides the recording of audio into WAV, FLAC or Ogg
+// Opus files. The recordings are kept in a directory on disk.
+package recorder
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// validName restricts the names of the recordings, so that they can't
+// escape from the directory
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*\.(wav|flac|opus)$`)
+
+// invalidLabelChars matches the characters which are replaced in the
+// parts of a label
+var invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// ErrInvalidName is returned if the name of a recording is not valid.
+var ErrInvalidName = errors.New("invalid recording name")
+
+// Recording contains the properties of a recording.
+type Recording struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Store manages the recordings in a directory.
+type Store struct {
+	sync.Mutex
+	directory string
+}
+
+// NewStore returns a Store for the recordings in the directory. The
+// directory is created if it doesn't exist.
+func NewStore(directory string) (*Store, error) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{directory: directory}, nil
+}
+
+// Path returns the path of a recording.
+func (s *Store) Path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", ErrInvalidName
+	}
+
+	path := filepath.Join(s.directory, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("unknown recording %s", name)
+	}
+	return path, nil
+}
+
+// Recordings returns the recordings in the store, sorted by name (which
+// starts with the date).
+func (s *Store) Recordings() ([]Recording, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	files, err := os.ReadDir(s.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	recs := []Recording{}
+	for _, f := range files {
+		if f.IsDir() || !validName.MatchString(f.Name()) {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		recs = append(recs, Recording{
+			Name:     f.Name(),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+		})
+	}
+
+	sort.Slice(recs, func(i, j int) bool {
+		return recs[i].Name < recs[j].Name
+	})
+
+	return recs, nil
+}
+
+// Delete removes a recording from the store.
+func (s *Store) Delete(name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	path, err := s.Path(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// create returns the path of a new recording. The name consists of the
+// date and the label; the extension is the format. This method is not
+// safe for concurrent access.
+func (s *Store) create(label, format string, t time.Time) string {
+	base := t.Format("20060102-150405")
+	if len(label) > 0 {
+		base += "_" + label
+	}
+
+	extension := "." + format
+	path := filepath.Join(s.directory, base+extension)
+	for i := 2; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+		path = filepath.Join(s.directory, fmt.Sprintf("%s_%d%s", base, i, extension))
+	}
+}
+
+// Label joins the parts (e.g. direction, server name and user) into a
+// label which can be used in the names of the recordings. Empty parts are
+// skipped and characters which aren't allowed in file names are replaced.
+func Label(parts ...string) string {
+	res := []string{}
+	for _, p := range parts {
+		p = strings.Trim(invalidLabelChars.ReplaceAllString(p, "-"), "-")
+		if len(p) > 0 {
+			res = append(res, p)
+		}
+	}
+	return strings.Join(res, "_")
+}
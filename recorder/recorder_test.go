@@ -0,0 +1,57 @@
+package recorder
+
+import "testing"
+
+func TestValidName(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{name: "20240101-120000.wav", valid: true},
+		{name: "20240101-120000_rx_server.flac", valid: true},
+		{name: "20240101-120000_tx_2.opus", valid: true},
+		{name: "", valid: false},
+		{name: ".wav", valid: false},
+		{name: "../x.wav", valid: false},
+		{name: "a/b.wav", valid: false},
+		{name: `a\b.wav`, valid: false},
+		{name: "..", valid: false},
+		{name: ".hidden.wav", valid: false},
+		{name: "recording.mp3", valid: false},
+		{name: "recording.wav.txt", valid: false},
+		{name: "recording", valid: false},
+		{name: "a b.wav", valid: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if valid := validName.MatchString(tc.name); valid != tc.valid {
+				t.Errorf("got %v, expected %v", valid, tc.valid)
+			}
+		})
+	}
+}
+
+func TestLabel(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		parts []string
+		label string
+	}{
+		{name: "empty", parts: nil, label: ""},
+		{name: "parts", parts: []string{"rx", "server", "user"}, label: "rx_server_user"},
+		{name: "skip empty", parts: []string{"rx", "", "user"}, label: "rx_user"},
+		{name: "path", parts: []string{"../etc/passwd"}, label: "etc-passwd"},
+		{name: "spaces", parts: []string{" my server "}, label: "my-server"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if label := Label(tc.parts...); label != tc.label {
+				t.Errorf("got %q, expected %q", label, tc.label)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package recorder
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/audiofile"
+	"github.com/dh1tw/remoteAudio/audio/sinks/oggWriter"
+	"github.com/dh1tw/remoteAudio/audio/sinks/wavWriter"
+)
+
+// Recorder writes the audio into files of a Store. In the WAV and FLAC
+// formats it implements the audio.Sink interface and records the decoded
+// audio. In the Ogg Opus format the encoded frames are written as they are
+// sent / received on the network (see Enqueue). A new file is started when the
+// maximum size or duration of a file has been reached, or when the label
+// changes (e.g. another station is transmitting).
+type Recorder struct {
+	sync.Mutex
+	store   *Store
+	options Options
+	label   string
+	rotate  bool // start a new file with the next msg
+	volume  float32
+	file    audiofile.Sink
+	ogg     *oggWriter.OggWriter
+	opened  time.Time
+}
+
+// NewRecorder returns a Recorder which writes into the store. The label
+// is part of the file names. The file is created with the first audio msg.
+func (s *Store) NewRecorder(label string, opts ...Option) *Recorder {
+	r := &Recorder{
+		store: s,
+		options: Options{
+			Format:     FormatWAV,
+			Samplerate: wavWriter.DefaultSamplerate,
+			Channels:   wavWriter.DefaultChannels,
+		},
+		label:  label,
+		volume: 1,
+	}
+
+	for _, opt := range opts {
+		opt(&r.options)
+	}
+
+	return r
+}
+
+// Format returns the format of the recordings.
+func (r *Recorder) Format() string {
+	r.Lock()
+	defer r.Unlock()
+	return r.options.Format
+}
+
+// SetLabel sets the label for the names of the files. If the label
+// changes, a new file will be started.
+func (r *Recorder) SetLabel(label string) {
+	r.Lock()
+	defer r.Unlock()
+	if label != r.label {
+		r.label = label
+		r.rotate = true
+	}
+}
+
+// Write writes the audio msg into the current WAV / FLAC file. Nothing
+// is written if the Recorder uses the Ogg Opus format.
+func (r *Recorder) Write(msg audio.Msg) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.options.Format == FormatOpus {
+		return nil
+	}
+
+	if err := r.prepareFile(); err != nil {
+		return err
+	}
+
+	return r.file.Write(msg)
+}
+
+// Enqueue writes an encoded audio frame (serialized sbAudio.Frame) into
+// the current Ogg Opus file. Nothing is written if the Recorder uses
+// another format.
+func (r *Recorder) Enqueue(data []byte) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.options.Format != FormatOpus {
+		return nil
+	}
+
+	if err := r.prepareFile(); err != nil {
+		return err
+	}
+
+	return r.ogg.Enqueue(data)
+}
+
+// prepareFile rotates the current file if necessary and opens a new
+// file. This method is not safe for concurrent access.
+func (r *Recorder) prepareFile() error {
+	if r.opened.IsZero() {
+		return r.openFile()
+	}
+	if r.expired() {
+		r.closeFile()
+		return r.openFile()
+	}
+	return nil
+}
+
+// expired indicates if the current file has to be rotated. This method
+// is not safe for concurrent access.
+func (r *Recorder) expired() bool {
+	if r.rotate {
+		return true
+	}
+	if r.options.MaxSize > 0 && r.size() >= r.options.MaxSize {
+		return true
+	}
+	if r.options.MaxDuration > 0 && time.Since(r.opened) >= r.options.MaxDuration {
+		return true
+	}
+	return false
+}
+
+// size returns the size of the current file. This method is not safe
+// for concurrent access.
+func (r *Recorder) size() int64 {
+	switch {
+	case r.file != nil:
+		return int64(r.file.Size())
+	case r.ogg != nil:
+		return r.ogg.Size()
+	}
+	return 0
+}
+
+// openFile starts a new file. This method is not safe for concurrent
+// access.
+func (r *Recorder) openFile() error {
+	r.store.Lock()
+	defer r.store.Unlock()
+
+	path := r.store.create(r.label, r.options.Format, time.Now())
+
+	switch r.options.Format {
+	case FormatOpus:
+		w, err := oggWriter.NewOggWriter(path,
+			oggWriter.Comments("TITLE="+r.label))
+		if err != nil {
+			return err
+		}
+		r.ogg = w
+	default:
+		w, err := audiofile.NewSink(path,
+			audiofile.Format(r.options.Format),
+			audiofile.Samplerate(r.options.Samplerate),
+			audiofile.Channels(r.options.Channels))
+		if err != nil {
+			return err
+		}
+		w.SetVolume(r.volume)
+		r.file = w
+	}
+
+	r.opened = time.Now()
+	r.rotate = false
+
+	return nil
+}
+
+// closeFile completes the current file. This method is not safe for
+// concurrent access.
+func (r *Recorder) closeFile() {
+	if r.file != nil {
+		if err := r.file.Close(); err != nil {
+			log.Println(err)
+		}
+		r.file = nil
+	}
+	if r.ogg != nil {
+		if err := r.ogg.Close(); err != nil {
+			log.Println(err)
+		}
+		r.ogg = nil
+	}
+	r.opened = time.Time{}
+}
+
+// Start is not implemented
+func (r *Recorder) Start() error {
+	return nil
+}
+
+// Stop is not implemented; the file stays open until the Recorder
+// is closed
+func (r *Recorder) Stop() error {
+	return nil
+}
+
+// Close completes the current file.
+func (r *Recorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+	r.closeFile()
+	return nil
+}
+
+// SetVolume sets the volume of the recorded audio (WAV / FLAC only).
+func (r *Recorder) SetVolume(v float32) {
+	r.Lock()
+	defer r.Unlock()
+	r.volume = v
+	if r.file != nil {
+		r.file.SetVolume(v)
+	}
+}
+
+// Volume returns the volume of the recorded audio.
+func (r *Recorder) Volume() float32 {
+	r.Lock()
+	defer r.Unlock()
+	return r.volume
+}
+
+// Flush is not implemented
+func (r *Recorder) Flush() {}
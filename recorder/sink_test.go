@@ -0,0 +1,182 @@
+package recorder
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"google.golang.org/protobuf/proto"
+)
+
+// msg returns 10ms of mono audio @48kHz (960 bytes in a 16 bit WAV file)
+func msg() audio.Msg {
+	return audio.Msg{
+		Data:       make([]float32, 480),
+		Channels:   1,
+		Samplerate: 48000,
+		Frames:     480,
+	}
+}
+
+// frame returns a serialized 20ms opus frame as sent on the network
+func frame(t *testing.T) []byte {
+	t.Helper()
+	data, err := proto.Marshal(&sbAudio.Frame{
+		Codec:        sbAudio.Codec_opus,
+		Channels:     sbAudio.Channels_mono,
+		SamplingRate: 48000,
+		Data:         []byte{0xf8, 0xff, 0xfe}, // CELT fullband 20ms
+		UserId:       "dl2abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// recordings returns the names of the recordings in the store.
+func recordings(t *testing.T, s *Store) []string {
+	t.Helper()
+	recs, err := s.Recordings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, r := range recs {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestRotation(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		before func(r *Recorder, i int) // called before the i-th msg is written
+		labels []string                 // labels of the expected files
+	}{
+		{
+			name:   "no rotation",
+			labels: []string{"rx_dj0abc"},
+		},
+		{
+			name:   "max size",
+			opts:   []Option{MaxSize(2 * 960)},
+			labels: []string{"rx_dj0abc", "rx_dj0abc", "rx_dj0abc"},
+		},
+		{
+			name: "max duration",
+			opts: []Option{MaxDuration(time.Minute)},
+			before: func(r *Recorder, i int) {
+				if i == 3 {
+					r.opened = r.opened.Add(-time.Minute)
+				}
+			},
+			labels: []string{"rx_dj0abc", "rx_dj0abc"},
+		},
+		{
+			name: "label change",
+			before: func(r *Recorder, i int) {
+				switch i {
+				case 2:
+					r.SetLabel("rx_dl2abc")
+				case 4:
+					r.SetLabel("rx_dl2abc") // unchanged
+				}
+			},
+			labels: []string{"rx_dj0abc", "rx_dl2abc"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := s.NewRecorder("rx_dj0abc", tc.opts...)
+			for i := 0; i < 5; i++ {
+				if tc.before != nil {
+					tc.before(r, i)
+				}
+				if err := r.Write(msg()); err != nil {
+					t.Fatal(err)
+				}
+			}
+			r.Close()
+
+			names := recordings(t, s)
+			if len(names) != len(tc.labels) {
+				t.Fatalf("got files %v, expected %d", names, len(tc.labels))
+			}
+			for i, name := range names {
+				if !strings.Contains(name, "_"+tc.labels[i]) || filepath.Ext(name) != ".wav" {
+					t.Errorf("file %s doesn't match label %s", name, tc.labels[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		format    string
+		extension string
+	}{
+		{name: "wav", format: FormatWAV, extension: ".wav"},
+		{name: "flac", format: FormatFLAC, extension: ".flac"},
+		{name: "opus", format: FormatOpus, extension: ".opus"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+
+			// the decoded audio is only recorded as WAV / FLAC
+			s, err := NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := s.NewRecorder("rx", Format(tc.format))
+			if err := r.Write(msg()); err != nil {
+				t.Fatal(err)
+			}
+			r.Close()
+
+			names := recordings(t, s)
+			if tc.format == FormatOpus {
+				if len(names) != 0 {
+					t.Errorf("decoded audio recorded as opus: %v", names)
+				}
+			} else if len(names) != 1 || filepath.Ext(names[0]) != tc.extension {
+				t.Errorf("got files %v, expected one %s file", names, tc.extension)
+			}
+
+			// the encoded frames are only recorded as Ogg Opus
+			s, err = NewStore(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			r = s.NewRecorder("rx", Format(tc.format))
+			if err := r.Enqueue(frame(t)); err != nil {
+				t.Fatal(err)
+			}
+			r.Close()
+
+			names = recordings(t, s)
+			if tc.format != FormatOpus {
+				if len(names) != 0 {
+					t.Errorf("encoded frames recorded as %s: %v", tc.format, names)
+				}
+			} else if len(names) != 1 || filepath.Ext(names[0]) != ".opus" {
+				t.Errorf("got files %v, expected one .opus file", names)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package rtp
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// maxPacketSize is the size of the receive buffer
+const maxPacketSize = 65536
+
+// Handler is the callback which is executed for every RTP packet received.
+// The payload is only valid for the duration of the call.
+type Handler func(from *net.UDPAddr, h Header, payload []byte)
+
+// Conn is a UDP socket which sends and receives audio frames in RTP
+// packets. Unlike the broker, a lost packet doesn't stall the packets
+// behind it. The payload is typically a serialized sbAudio.Frame.
+type Conn struct {
+	sync.RWMutex
+	options   Options
+	conn      *net.UDPConn
+	remote    *net.UDPAddr
+	ssrc      uint32
+	seq       map[string]uint16 // next sequence number per destination
+	start     time.Time
+	handler   Handler
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// Listen opens a UDP socket on the given address (e.g. ":5100" or
+// ":0" for a random port) and starts receiving RTP packets.
+func Listen(address string, opts ...Option) (*Conn, error) {
+
+	c := &Conn{
+		options: Options{
+			PayloadType: 96,
+			ClockRate:   48000,
+		},
+		ssrc:    rand.Uint32(),
+		seq:     make(map[string]uint16),
+		start:   time.Now(),
+		closeCh: make(chan struct{}),
+	}
+
+	for _, option := range opts {
+		option(&c.options)
+	}
+
+	laddr, err := net.ResolveUDPAddr("udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("rtp: %v", err)
+	}
+
+	conn, err := net.ListenUDP("udp", laddr)
+	if err != nil {
+		return nil, fmt.Errorf("rtp: %v", err)
+	}
+	c.conn = conn
+
+	go c.readLoop()
+
+	if c.options.KeepAlive > 0 {
+		go c.keepAlive()
+	}
+
+	return c, nil
+}
+
+// SetHandler sets the callback which is executed for every received packet.
+func (c *Conn) SetHandler(h Handler) {
+	c.Lock()
+	defer c.Unlock()
+	c.handler = h
+}
+
+// SetRemote sets the address (host:port) to which Write sends the packets.
+// An empty address disables sending.
+func (c *Conn) SetRemote(address string) error {
+
+	var raddr *net.UDPAddr
+
+	if len(address) > 0 {
+		var err error
+		raddr, err = net.ResolveUDPAddr("udp", address)
+		if err != nil {
+			return fmt.Errorf("rtp: %v", err)
+		}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.remote = raddr
+
+	return nil
+}
+
+// Remote returns the address to which Write sends the packets. If no
+// remote address is set, nil will be returned.
+func (c *Conn) Remote() *net.UDPAddr {
+	c.RLock()
+	defer c.RUnlock()
+	return c.remote
+}
+
+// SetSSRC sets the synchronization source identifier of the outgoing
+// packets. By default a random value is used.
+func (c *Conn) SetSSRC(ssrc uint32) {
+	c.Lock()
+	defer c.Unlock()
+	c.ssrc = ssrc
+}
+
+// LocalAddr returns the local address of the socket.
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// Write sends the payload to the remote address. If no remote address
+// is set, the payload is silently dropped.
+func (c *Conn) Write(payload []byte) error {
+	c.RLock()
+	raddr := c.remote
+	c.RUnlock()
+
+	if raddr == nil {
+		return nil
+	}
+
+	return c.WriteTo(payload, raddr)
+}
+
+// WriteTo sends the payload to the given address.
+func (c *Conn) WriteTo(payload []byte, addr *net.UDPAddr) error {
+
+	c.Lock()
+	key := addr.String()
+	seq := c.seq[key]
+	c.seq[key] = seq + 1
+
+	h := Header{
+		PayloadType:    c.options.PayloadType,
+		SequenceNumber: seq,
+		Timestamp:      uint32(time.Since(c.start).Seconds() * float64(c.options.ClockRate)),
+		SSRC:           c.ssrc,
+	}
+	c.Unlock()
+
+	_, err := c.conn.WriteToUDP(h.marshal(payload), addr)
+	return err
+}
+
+// Forget removes the state (e.g. sequence number) kept for the given
+// destination address.
+func (c *Conn) Forget(addr *net.UDPAddr) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.seq, addr.String())
+}
+
+// Close shuts down the socket.
+func (c *Conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closeCh)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// readLoop is a blocking function which reads the packets from the
+// socket and passes them on to the handler.
+func (c *Conn) readLoop() {
+
+	buf := make([]byte, maxPacketSize)
+
+	for {
+		n, from, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-c.closeCh:
+				return
+			default:
+			}
+			log.Println("rtp:", err)
+			continue
+		}
+
+		h, payload, err := unmarshal(buf[:n])
+		if err != nil {
+			continue // not an RTP packet
+		}
+
+		c.RLock()
+		handler := c.handler
+		c.RUnlock()
+
+		if handler != nil {
+			handler(from, h, payload)
+		}
+	}
+}
+
+// keepAlive is a blocking function which periodically sends an empty
+// packet to the remote address.
+func (c *Conn) keepAlive() {
+
+	ticker := time.NewTicker(c.options.KeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Write(nil); err != nil {
+				log.Println("rtp keepalive:", err)
+			}
+		case <-c.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package rtp
+
+import "time"
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options is the data structure which holds the particular Options values.
+// The values are typically provided as functional options.
+type Options struct {
+	PayloadType uint8
+	ClockRate   int
+	KeepAlive   time.Duration
+}
+
+// PayloadType is a functional option to set the (dynamic) RTP payload
+// type of the outgoing packets. Defaults to 96.
+func PayloadType(pt uint8) Option {
+	return func(args *Options) {
+		args.PayloadType = pt
+	}
+}
+
+// ClockRate is a functional option to set the clock rate (Hz) of the
+// RTP timestamps. Defaults to 48000.
+func ClockRate(rate int) Option {
+	return func(args *Options) {
+		args.ClockRate = rate
+	}
+}
+
+// KeepAlive is a functional option to set the interval in which empty
+// packets are sent to the remote address. This keeps NAT bindings open
+// and allows the remote side to learn our address. Defaults to 0 (off).
+func KeepAlive(interval time.Duration) Option {
+	return func(args *Options) {
+		args.KeepAlive = interval
+	}
+}
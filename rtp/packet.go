@@ -0,0 +1,81 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	version    = 2
+	headerSize = 12
+)
+
+// Header is the fixed header of an RTP packet (RFC 3550). CSRC lists and
+// header extensions are skipped when parsing and never written.
+type Header struct {
+	Marker         bool
+	PayloadType    uint8
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+}
+
+// marshal writes the header followed by the payload into a new packet.
+func (h Header) marshal(payload []byte) []byte {
+	pkt := make([]byte, headerSize+len(payload))
+
+	pkt[0] = version << 6
+	pkt[1] = h.PayloadType & 0x7f
+	if h.Marker {
+		pkt[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(pkt[2:4], h.SequenceNumber)
+	binary.BigEndian.PutUint32(pkt[4:8], h.Timestamp)
+	binary.BigEndian.PutUint32(pkt[8:12], h.SSRC)
+	copy(pkt[headerSize:], payload)
+
+	return pkt
+}
+
+// unmarshal parses an RTP packet and returns its header and payload.
+// The payload references the memory of the packet.
+func unmarshal(pkt []byte) (Header, []byte, error) {
+
+	h := Header{}
+
+	if len(pkt) < headerSize {
+		return h, nil, errors.New("rtp packet too short")
+	}
+	if pkt[0]>>6 != version {
+		return h, nil, errors.New("unsupported rtp version")
+	}
+
+	padding := pkt[0]&0x20 != 0
+	extension := pkt[0]&0x10 != 0
+	csrcCount := int(pkt[0] & 0x0f)
+
+	h.Marker = pkt[1]&0x80 != 0
+	h.PayloadType = pkt[1] & 0x7f
+	h.SequenceNumber = binary.BigEndian.Uint16(pkt[2:4])
+	h.Timestamp = binary.BigEndian.Uint32(pkt[4:8])
+	h.SSRC = binary.BigEndian.Uint32(pkt[8:12])
+
+	offset := headerSize + csrcCount*4
+	if extension {
+		if len(pkt) < offset+4 {
+			return h, nil, errors.New("rtp header extension too short")
+		}
+		offset += 4 + int(binary.BigEndian.Uint16(pkt[offset+2:offset+4]))*4
+	}
+
+	end := len(pkt)
+	if padding && end > offset {
+		end -= int(pkt[end-1])
+	}
+
+	if offset > end {
+		return h, nil, errors.New("invalid rtp packet")
+	}
+
+	return h, pkt[offset:end], nil
+}
@@ -0,0 +1,128 @@
+package rtp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMarshalUnmarshal(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		header  Header
+		payload []byte
+	}{
+		{
+			name:    "opus frame",
+			header:  Header{PayloadType: 111, SequenceNumber: 1, Timestamp: 960, SSRC: 0x12345678},
+			payload: []byte{1, 2, 3, 4, 5},
+		},
+		{
+			name:    "marker and max values",
+			header:  Header{Marker: true, PayloadType: 127, SequenceNumber: 65535, Timestamp: 0xffffffff, SSRC: 0xffffffff},
+			payload: []byte{0xff},
+		},
+		{
+			name:   "empty payload",
+			header: Header{PayloadType: 0, SequenceNumber: 42},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pkt := tc.header.marshal(tc.payload)
+			if len(pkt) != headerSize+len(tc.payload) {
+				t.Fatalf("packet size: got %d, expected %d", len(pkt), headerSize+len(tc.payload))
+			}
+
+			h, payload, err := unmarshal(pkt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h != tc.header {
+				t.Errorf("header: got %+v, expected %+v", h, tc.header)
+			}
+			if !bytes.Equal(payload, tc.payload) {
+				t.Errorf("payload: got %v, expected %v", payload, tc.payload)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+
+	header := []byte{0x80, 0x6f, 0x00, 0x01, 0x00, 0x00, 0x03, 0xc0, 0x00, 0x00, 0x00, 0x2a}
+
+	tests := []struct {
+		name    string
+		pkt     []byte
+		payload []byte
+		err     bool
+	}{
+		{
+			name:    "plain packet",
+			pkt:     append(append([]byte{}, header...), 1, 2, 3),
+			payload: []byte{1, 2, 3},
+		},
+		{
+			name: "csrc list is skipped",
+			pkt: append(append([]byte{0x82}, header[1:]...),
+				0, 0, 0, 1, 0, 0, 0, 2, 7, 8),
+			payload: []byte{7, 8},
+		},
+		{
+			name: "header extension is skipped",
+			pkt: append(append([]byte{0x90}, header[1:]...),
+				0xbe, 0xde, 0x00, 0x01, 0xaa, 0xbb, 0xcc, 0xdd, 9),
+			payload: []byte{9},
+		},
+		{
+			name: "padding is removed",
+			pkt: append(append([]byte{0xa0}, header[1:]...),
+				1, 2, 0, 0, 3),
+			payload: []byte{1, 2},
+		},
+		{
+			name: "too short",
+			pkt:  header[:8],
+			err:  true,
+		},
+		{
+			name: "wrong version",
+			pkt:  append([]byte{0x40}, header[1:]...),
+			err:  true,
+		},
+		{
+			name: "truncated header extension",
+			pkt:  append(append([]byte{0x90}, header[1:]...), 0xbe, 0xde),
+			err:  true,
+		},
+		{
+			name: "csrc list exceeds the packet",
+			pkt:  append([]byte{0x8f}, header[1:]...),
+			err:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h, payload, err := unmarshal(tc.pkt)
+			if tc.err {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := Header{PayloadType: 111, SequenceNumber: 1, Timestamp: 960, SSRC: 42}
+			if h != expected {
+				t.Errorf("header: got %+v, expected %+v", h, expected)
+			}
+			if !bytes.Equal(payload, tc.payload) {
+				t.Errorf("payload: got %v, expected %v", payload, tc.payload)
+			}
+		})
+	}
+}
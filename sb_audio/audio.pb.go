@@ -73,9 +73,10 @@ func (Channels) EnumDescriptor() ([]byte, []int) {
 type Codec int32
 
 const (
-	Codec_none Codec = 0
-	Codec_opus Codec = 1
-	Codec_pcm  Codec = 2
+	Codec_none  Codec = 0
+	Codec_opus  Codec = 1
+	Codec_pcm   Codec = 2
+	Codec_adpcm Codec = 3 // IMA ADPCM (4 bit per sample)
 )
 
 // Enum value maps for Codec.
@@ -84,11 +85,13 @@ var (
 		0: "none",
 		1: "opus",
 		2: "pcm",
+		3: "adpcm",
 	}
 	Codec_value = map[string]int32{
-		"none": 0,
-		"opus": 1,
-		"pcm":  2,
+		"none":  0,
+		"opus":  1,
+		"pcm":   2,
+		"adpcm": 3,
 	}
 )
 
@@ -119,6 +122,61 @@ func (Codec) EnumDescriptor() ([]byte, []int) {
 	return file_audio_proto_rawDescGZIP(), []int{1}
 }
 
+type Envelope_Type int32
+
+const (
+	Envelope_publish     Envelope_Type = 0
+	Envelope_request     Envelope_Type = 1
+	Envelope_response    Envelope_Type = 2
+	Envelope_subscribe   Envelope_Type = 3 // the sender wants to receive the publications of topic
+	Envelope_unsubscribe Envelope_Type = 4
+)
+
+// Enum value maps for Envelope_Type.
+var (
+	Envelope_Type_name = map[int32]string{
+		0: "publish",
+		1: "request",
+		2: "response",
+		3: "subscribe",
+		4: "unsubscribe",
+	}
+	Envelope_Type_value = map[string]int32{
+		"publish":     0,
+		"request":     1,
+		"response":    2,
+		"subscribe":   3,
+		"unsubscribe": 4,
+	}
+)
+
+func (x Envelope_Type) Enum() *Envelope_Type {
+	p := new(Envelope_Type)
+	*p = x
+	return p
+}
+
+func (x Envelope_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Envelope_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_audio_proto_enumTypes[2].Descriptor()
+}
+
+func (Envelope_Type) Type() protoreflect.EnumType {
+	return &file_audio_proto_enumTypes[2]
+}
+
+func (x Envelope_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Envelope_Type.Descriptor instead.
+func (Envelope_Type) EnumDescriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{9, 0}
+}
+
 type None struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -162,6 +220,12 @@ type Capabilities struct {
 	TxStreamAddress     string                 `protobuf:"bytes,3,opt,name=tx_stream_address,json=txStreamAddress,proto3" json:"tx_stream_address,omitempty"`             // where the Server listens for audio to be transmitted on the radio
 	StateUpdatesAddress string                 `protobuf:"bytes,4,opt,name=state_updates_address,json=stateUpdatesAddress,proto3" json:"state_updates_address,omitempty"` // where the Server listens for audio to be transmitted on the radio
 	Index               int32                  `protobuf:"varint,5,opt,name=index,proto3" json:"index,omitempty"`                                                         // static index for displaying several servers consistently in a GUI
+	Codecs              []*CodecParameters     `protobuf:"bytes,6,rep,name=codecs,proto3" json:"codecs,omitempty"`                                                        // audio codecs which the Server is able to decode
+	RxCodec             string                 `protobuf:"bytes,7,opt,name=rx_codec,json=rxCodec,proto3" json:"rx_codec,omitempty"`                                       // codec of the audio published on the rx_stream_address
+	RxProfiles          []*RxProfile           `protobuf:"bytes,8,rep,name=rx_profiles,json=rxProfiles,proto3" json:"rx_profiles,omitempty"`                              // additional encodings of the rx audio stream
+	RxFeedbackAddress   string                 `protobuf:"bytes,9,opt,name=rx_feedback_address,json=rxFeedbackAddress,proto3" json:"rx_feedback_address,omitempty"`       // where the Server listens for reception reports about the rx audio stream
+	TxFeedbackAddress   string                 `protobuf:"bytes,10,opt,name=tx_feedback_address,json=txFeedbackAddress,proto3" json:"tx_feedback_address,omitempty"`      // where the Server publishes reception reports about the tx audio streams
+	MediaAddress        string                 `protobuf:"bytes,11,opt,name=media_address,json=mediaAddress,proto3" json:"media_address,omitempty"`                       // UDP address (host:port) of the Server's RTP media path; empty if not supported
 	unknownFields       protoimpl.UnknownFields
 	sizeCache           protoimpl.SizeCache
 }
@@ -231,6 +295,368 @@ func (x *Capabilities) GetIndex() int32 {
 	return 0
 }
 
+func (x *Capabilities) GetCodecs() []*CodecParameters {
+	if x != nil {
+		return x.Codecs
+	}
+	return nil
+}
+
+func (x *Capabilities) GetRxCodec() string {
+	if x != nil {
+		return x.RxCodec
+	}
+	return ""
+}
+
+func (x *Capabilities) GetRxProfiles() []*RxProfile {
+	if x != nil {
+		return x.RxProfiles
+	}
+	return nil
+}
+
+func (x *Capabilities) GetRxFeedbackAddress() string {
+	if x != nil {
+		return x.RxFeedbackAddress
+	}
+	return ""
+}
+
+func (x *Capabilities) GetTxFeedbackAddress() string {
+	if x != nil {
+		return x.TxFeedbackAddress
+	}
+	return ""
+}
+
+func (x *Capabilities) GetMediaAddress() string {
+	if x != nil {
+		return x.MediaAddress
+	}
+	return ""
+}
+
+// Request for opening / updating a media session on the RTP media path
+type MediaSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       uint32                 `protobuf:"varint,1,opt,name=session,proto3" json:"session,omitempty"`                     // id of an existing session; 0 to open a new session
+	RxAddress     string                 `protobuf:"bytes,2,opt,name=rx_address,json=rxAddress,proto3" json:"rx_address,omitempty"` // rx stream (address) which the client wants to receive
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MediaSessionRequest) Reset() {
+	*x = MediaSessionRequest{}
+	mi := &file_audio_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediaSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediaSessionRequest) ProtoMessage() {}
+
+func (x *MediaSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediaSessionRequest.ProtoReflect.Descriptor instead.
+func (*MediaSessionRequest) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *MediaSessionRequest) GetSession() uint32 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MediaSessionRequest) GetRxAddress() string {
+	if x != nil {
+		return x.RxAddress
+	}
+	return ""
+}
+
+func (x *MediaSessionRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+// Media session on the RTP media path. The client uses the session id as
+// SSRC of the RTP packets it sends to the media address.
+type MediaSession struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Session       uint32                 `protobuf:"varint,1,opt,name=session,proto3" json:"session,omitempty"`
+	MediaAddress  string                 `protobuf:"bytes,2,opt,name=media_address,json=mediaAddress,proto3" json:"media_address,omitempty"` // UDP address (host:port) of the Server
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MediaSession) Reset() {
+	*x = MediaSession{}
+	mi := &file_audio_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediaSession) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediaSession) ProtoMessage() {}
+
+func (x *MediaSession) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediaSession.ProtoReflect.Descriptor instead.
+func (*MediaSession) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MediaSession) GetSession() uint32 {
+	if x != nil {
+		return x.Session
+	}
+	return 0
+}
+
+func (x *MediaSession) GetMediaAddress() string {
+	if x != nil {
+		return x.MediaAddress
+	}
+	return ""
+}
+
+type RxProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`        // name of the profile (e.g. high, medium, low)
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`  // where the Server publishes the audio encoded with this profile
+	Codec         string                 `protobuf:"bytes,3,opt,name=codec,proto3" json:"codec,omitempty"`      // audio codec used for this profile
+	Bitrate       int32                  `protobuf:"varint,4,opt,name=bitrate,proto3" json:"bitrate,omitempty"` // target bitrate (bits/s) of the encoder
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RxProfile) Reset() {
+	*x = RxProfile{}
+	mi := &file_audio_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RxProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RxProfile) ProtoMessage() {}
+
+func (x *RxProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RxProfile.ProtoReflect.Descriptor instead.
+func (*RxProfile) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RxProfile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RxProfile) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RxProfile) GetCodec() string {
+	if x != nil {
+		return x.Codec
+	}
+	return ""
+}
+
+func (x *RxProfile) GetBitrate() int32 {
+	if x != nil {
+		return x.Bitrate
+	}
+	return 0
+}
+
+type CodecParameters struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                // name of the codec
+	SamplingRates []int32                `protobuf:"varint,2,rep,packed,name=sampling_rates,json=samplingRates,proto3" json:"sampling_rates,omitempty"` // supported sampling rates
+	BitDepths     []int32                `protobuf:"varint,3,rep,packed,name=bit_depths,json=bitDepths,proto3" json:"bit_depths,omitempty"`             // supported bit depths
+	Channels      []int32                `protobuf:"varint,4,rep,packed,name=channels,proto3" json:"channels,omitempty"`                                // supported amount of channels
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CodecParameters) Reset() {
+	*x = CodecParameters{}
+	mi := &file_audio_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CodecParameters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CodecParameters) ProtoMessage() {}
+
+func (x *CodecParameters) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CodecParameters.ProtoReflect.Descriptor instead.
+func (*CodecParameters) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CodecParameters) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CodecParameters) GetSamplingRates() []int32 {
+	if x != nil {
+		return x.SamplingRates
+	}
+	return nil
+}
+
+func (x *CodecParameters) GetBitDepths() []int32 {
+	if x != nil {
+		return x.BitDepths
+	}
+	return nil
+}
+
+func (x *CodecParameters) GetChannels() []int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+// Reception statistics of an audio stream, sent back to its sender
+type ReceptionReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SenderId      string                 `protobuf:"bytes,1,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`       // user id of the audio stream's sender
+	ReceiverId    string                 `protobuf:"bytes,2,opt,name=receiver_id,json=receiverId,proto3" json:"receiver_id,omitempty"` // user id of the receiver
+	Loss          float32                `protobuf:"fixed32,3,opt,name=loss,proto3" json:"loss,omitempty"`                             // fraction of frames lost since the last report (0...1)
+	Jitter        int64                  `protobuf:"varint,4,opt,name=jitter,proto3" json:"jitter,omitempty"`                          // inter-arrival jitter (µs)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceptionReport) Reset() {
+	*x = ReceptionReport{}
+	mi := &file_audio_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceptionReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceptionReport) ProtoMessage() {}
+
+func (x *ReceptionReport) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceptionReport.ProtoReflect.Descriptor instead.
+func (*ReceptionReport) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ReceptionReport) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *ReceptionReport) GetReceiverId() string {
+	if x != nil {
+		return x.ReceiverId
+	}
+	return ""
+}
+
+func (x *ReceptionReport) GetLoss() float32 {
+	if x != nil {
+		return x.Loss
+	}
+	return 0
+}
+
+func (x *ReceptionReport) GetJitter() int64 {
+	if x != nil {
+		return x.Jitter
+	}
+	return 0
+}
+
 type PingPong struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Ping          int64                  `protobuf:"varint,1,opt,name=ping,proto3" json:"ping,omitempty"` // unix timestamp
@@ -240,7 +666,7 @@ type PingPong struct {
 
 func (x *PingPong) Reset() {
 	*x = PingPong{}
-	mi := &file_audio_proto_msgTypes[2]
+	mi := &file_audio_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -252,7 +678,7 @@ func (x *PingPong) String() string {
 func (*PingPong) ProtoMessage() {}
 
 func (x *PingPong) ProtoReflect() protoreflect.Message {
-	mi := &file_audio_proto_msgTypes[2]
+	mi := &file_audio_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -265,7 +691,7 @@ func (x *PingPong) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PingPong.ProtoReflect.Descriptor instead.
 func (*PingPong) Descriptor() ([]byte, []int) {
-	return file_audio_proto_rawDescGZIP(), []int{2}
+	return file_audio_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *PingPong) GetPing() int64 {
@@ -277,21 +703,23 @@ func (x *PingPong) GetPing() int64 {
 
 // Audio frame consisting of the raw audio byte array + metadata
 type Frame struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Codec         Codec                  `protobuf:"varint,1,opt,name=codec,proto3,enum=shackbus.audio.Codec" json:"codec,omitempty"`
-	Channels      Channels               `protobuf:"varint,2,opt,name=channels,proto3,enum=shackbus.audio.Channels" json:"channels,omitempty"` // Number of channels
-	FrameLength   int32                  `protobuf:"varint,3,opt,name=frame_length,json=frameLength,proto3" json:"frame_length,omitempty"`     // Audio frame length (in bytes)
-	SamplingRate  int32                  `protobuf:"varint,4,opt,name=sampling_rate,json=samplingRate,proto3" json:"sampling_rate,omitempty"`  // Audio sampling rate
-	BitDepth      int32                  `protobuf:"varint,5,opt,name=bit_depth,json=bitDepth,proto3" json:"bit_depth,omitempty"`              // Audio bit depth (8...16 bit typically)
-	Data          []byte                 `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`                                       // Audio packets as raw byte array
-	UserId        string                 `protobuf:"bytes,8,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Codec          Codec                  `protobuf:"varint,1,opt,name=codec,proto3,enum=shackbus.audio.Codec" json:"codec,omitempty"`
+	Channels       Channels               `protobuf:"varint,2,opt,name=channels,proto3,enum=shackbus.audio.Channels" json:"channels,omitempty"` // Number of channels
+	FrameLength    int32                  `protobuf:"varint,3,opt,name=frame_length,json=frameLength,proto3" json:"frame_length,omitempty"`     // Audio frame length (in bytes)
+	SamplingRate   int32                  `protobuf:"varint,4,opt,name=sampling_rate,json=samplingRate,proto3" json:"sampling_rate,omitempty"`  // Audio sampling rate
+	BitDepth       int32                  `protobuf:"varint,5,opt,name=bit_depth,json=bitDepth,proto3" json:"bit_depth,omitempty"`              // Audio bit depth (8...16 bit typically)
+	Data           []byte                 `protobuf:"bytes,6,opt,name=data,proto3" json:"data,omitempty"`                                       // Audio packets as raw byte array
+	UserId         string                 `protobuf:"bytes,8,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	SequenceNumber uint32                 `protobuf:"varint,9,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"` // Frame counter, incremented by the sender for each frame
+	Timestamp      int64                  `protobuf:"varint,10,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                                // Capture time of the frame (unix timestamp in µs)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Frame) Reset() {
 	*x = Frame{}
-	mi := &file_audio_proto_msgTypes[3]
+	mi := &file_audio_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -303,7 +731,7 @@ func (x *Frame) String() string {
 func (*Frame) ProtoMessage() {}
 
 func (x *Frame) ProtoReflect() protoreflect.Message {
-	mi := &file_audio_proto_msgTypes[3]
+	mi := &file_audio_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -316,7 +744,7 @@ func (x *Frame) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Frame.ProtoReflect.Descriptor instead.
 func (*Frame) Descriptor() ([]byte, []int) {
-	return file_audio_proto_rawDescGZIP(), []int{3}
+	return file_audio_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *Frame) GetCodec() Codec {
@@ -368,6 +796,98 @@ func (x *Frame) GetUserId() string {
 	return ""
 }
 
+func (x *Frame) GetSequenceNumber() uint32 {
+	if x != nil {
+		return x.SequenceNumber
+	}
+	return 0
+}
+
+func (x *Frame) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// Envelope wraps the messages exchanged over the point-to-point connection
+// of the direct mode (publications, RPC requests and responses)
+type Envelope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          Envelope_Type          `protobuf:"varint,1,opt,name=type,proto3,enum=shackbus.audio.Envelope_Type" json:"type,omitempty"`
+	Topic         string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"` // topic of a (un)subscription / publication or endpoint of a request (e.g. Server.Ping)
+	Id            uint64                 `protobuf:"varint,3,opt,name=id,proto3" json:"id,omitempty"`      // id of the request, repeated in the response
+	Body          []byte                 `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"` // error returned by the request handler
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Envelope) Reset() {
+	*x = Envelope{}
+	mi := &file_audio_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Envelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Envelope) ProtoMessage() {}
+
+func (x *Envelope) ProtoReflect() protoreflect.Message {
+	mi := &file_audio_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Envelope.ProtoReflect.Descriptor instead.
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return file_audio_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Envelope) GetType() Envelope_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Envelope_publish
+}
+
+func (x *Envelope) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Envelope) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Envelope) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+func (x *Envelope) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type State struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RxOn          bool                   `protobuf:"varint,1,opt,name=rx_on,json=rxOn,proto3" json:"rx_on,omitempty"`
@@ -378,7 +898,7 @@ type State struct {
 
 func (x *State) Reset() {
 	*x = State{}
-	mi := &file_audio_proto_msgTypes[4]
+	mi := &file_audio_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -390,7 +910,7 @@ func (x *State) String() string {
 func (*State) ProtoMessage() {}
 
 func (x *State) ProtoReflect() protoreflect.Message {
-	mi := &file_audio_proto_msgTypes[4]
+	mi := &file_audio_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,7 +923,7 @@ func (x *State) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use State.ProtoReflect.Descriptor instead.
 func (*State) Descriptor() ([]byte, []int) {
-	return file_audio_proto_rawDescGZIP(), []int{4}
+	return file_audio_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *State) GetRxOn() bool {
@@ -425,7 +945,7 @@ var File_audio_proto protoreflect.FileDescriptor
 var file_audio_proto_rawDesc = string([]byte{
 	0x0a, 0x0b, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0e, 0x73,
 	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x22, 0x06, 0x0a,
-	0x04, 0x4e, 0x6f, 0x6e, 0x65, 0x22, 0xc4, 0x01, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69,
+	0x04, 0x4e, 0x6f, 0x6e, 0x65, 0x22, 0xd9, 0x03, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69,
 	0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x11, 0x72, 0x78,
 	0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18,
@@ -437,55 +957,137 @@ var file_audio_proto_rawDesc = string([]byte{
 	0x74, 0x65, 0x73, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
 	0x09, 0x52, 0x13, 0x73, 0x74, 0x61, 0x74, 0x65, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x73, 0x41,
 	0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x22, 0x1e, 0x0a, 0x08,
-	0x50, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x69, 0x6e, 0x67,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x22, 0xfc, 0x01, 0x0a,
-	0x05, 0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
-	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x52, 0x05, 0x63, 0x6f,
-	0x64, 0x65, 0x63, 0x12, 0x34, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
-	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52,
-	0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x72, 0x61,
-	0x6d, 0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x0b, 0x66, 0x72, 0x61, 0x6d, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d,
-	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x0c, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74,
-	0x65, 0x12, 0x1b, 0x0a, 0x09, 0x62, 0x69, 0x74, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x62, 0x69, 0x74, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x12,
-	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61,
-	0x74, 0x61, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0x35, 0x0a, 0x05, 0x53,
-	0x74, 0x61, 0x74, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x72, 0x78, 0x5f, 0x6f, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x04, 0x72, 0x78, 0x4f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x74, 0x78, 0x5f,
-	0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74, 0x78, 0x55, 0x73,
-	0x65, 0x72, 0x2a, 0x2d, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x0b,
-	0x0a, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x6d,
-	0x6f, 0x6e, 0x6f, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x73, 0x74, 0x65, 0x72, 0x65, 0x6f, 0x10,
-	0x02, 0x2a, 0x24, 0x0a, 0x05, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x08, 0x0a, 0x04, 0x6e, 0x6f,
-	0x6e, 0x65, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x6f, 0x70, 0x75, 0x73, 0x10, 0x01, 0x12, 0x07,
-	0x0a, 0x03, 0x70, 0x63, 0x6d, 0x10, 0x02, 0x32, 0xb9, 0x02, 0x0a, 0x06, 0x53, 0x65, 0x72, 0x76,
-	0x65, 0x72, 0x12, 0x45, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
-	0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
-	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x1c, 0x2e, 0x73, 0x68,
-	0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x61, 0x70,
-	0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x47, 0x65, 0x74,
-	0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
-	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x15, 0x2e, 0x73, 0x68,
-	0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x53, 0x74, 0x61,
-	0x74, 0x65, 0x12, 0x39, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64,
-	0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62,
-	0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x12, 0x38, 0x0a,
-	0x0a, 0x53, 0x74, 0x6f, 0x70, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x2e, 0x73, 0x68,
-	0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e,
-	0x65, 0x1a, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64,
-	0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x12, 0x3a, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12,
-	0x18, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f,
-	0x2e, 0x50, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6e, 0x67, 0x1a, 0x18, 0x2e, 0x73, 0x68, 0x61, 0x63,
-	0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x50,
-	0x6f, 0x6e, 0x67, 0x42, 0x0c, 0x5a, 0x0a, 0x2e, 0x2f, 0x73, 0x62, 0x5f, 0x61, 0x75, 0x64, 0x69,
-	0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x37, 0x0a, 0x06,
+	0x63, 0x6f, 0x64, 0x65, 0x63, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x73,
+	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x6f,
+	0x64, 0x65, 0x63, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x52, 0x06, 0x63,
+	0x6f, 0x64, 0x65, 0x63, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x78, 0x5f, 0x63, 0x6f, 0x64, 0x65,
+	0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x78, 0x43, 0x6f, 0x64, 0x65, 0x63,
+	0x12, 0x3a, 0x0a, 0x0b, 0x72, 0x78, 0x5f, 0x70, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
+	0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
+	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x52, 0x78, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65,
+	0x52, 0x0a, 0x72, 0x78, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x2e, 0x0a, 0x13,
+	0x72, 0x78, 0x5f, 0x66, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x72, 0x78, 0x46, 0x65, 0x65,
+	0x64, 0x62, 0x61, 0x63, 0x6b, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2e, 0x0a, 0x13,
+	0x74, 0x78, 0x5f, 0x66, 0x65, 0x65, 0x64, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x74, 0x78, 0x46, 0x65, 0x65,
+	0x64, 0x62, 0x61, 0x63, 0x6b, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x0d,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x0b, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x22, 0x67, 0x0a, 0x13, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x65, 0x73, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x72, 0x78, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x72, 0x78, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x22, 0x4d, 0x0a, 0x0c, 0x4d, 0x65,
+	0x64, 0x69, 0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x65,
+	0x73, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x65, 0x73,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x5f, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x69, 0x0a, 0x09, 0x52, 0x78, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64,
+	0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x69,
+	0x74, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x62, 0x69, 0x74,
+	0x72, 0x61, 0x74, 0x65, 0x22, 0x87, 0x01, 0x0a, 0x0f, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x50, 0x61,
+	0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e,
+	0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x05, 0x52, 0x0d, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x52, 0x61,
+	0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x69, 0x74, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x05, 0x52, 0x09, 0x62, 0x69, 0x74, 0x44, 0x65, 0x70, 0x74,
+	0x68, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x05, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x7b,
+	0x0a, 0x0f, 0x52, 0x65, 0x63, 0x65, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x49, 0x64, 0x12, 0x1f,
+	0x0a, 0x0b, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x02, 0x52, 0x04, 0x6c,
+	0x6f, 0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x22, 0x1e, 0x0a, 0x08, 0x50,
+	0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x70, 0x69, 0x6e, 0x67, 0x22, 0xc3, 0x02, 0x0a, 0x05,
+	0x46, 0x72, 0x61, 0x6d, 0x65, 0x12, 0x2b, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x15, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e,
+	0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x6f, 0x64, 0x65, 0x63, 0x52, 0x05, 0x63, 0x6f, 0x64,
+	0x65, 0x63, 0x12, 0x34, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e,
+	0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x08,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x72, 0x61, 0x6d,
+	0x65, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x66, 0x72, 0x61, 0x6d, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x23, 0x0a, 0x0d, 0x73,
+	0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x0c, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x69, 0x6e, 0x67, 0x52, 0x61, 0x74, 0x65,
+	0x12, 0x1b, 0x0a, 0x09, 0x62, 0x69, 0x74, 0x5f, 0x64, 0x65, 0x70, 0x74, 0x68, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x08, 0x62, 0x69, 0x74, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72, 0x49, 0x64, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x65,
+	0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0e, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x4e, 0x75, 0x6d,
+	0x62, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x22, 0xdd, 0x01, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x12, 0x31,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x73,
+	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x45, 0x6e,
+	0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x74, 0x6f, 0x70, 0x69, 0x63, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x22, 0x4e, 0x0a, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x70, 0x75, 0x62,
+	0x6c, 0x69, 0x73, 0x68, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x10,
+	0x02, 0x12, 0x0d, 0x0a, 0x09, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x10, 0x03,
+	0x12, 0x0f, 0x0a, 0x0b, 0x75, 0x6e, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x10,
+	0x04, 0x22, 0x35, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x13, 0x0a, 0x05, 0x72, 0x78,
+	0x5f, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x72, 0x78, 0x4f, 0x6e, 0x12,
+	0x17, 0x0a, 0x07, 0x74, 0x78, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x74, 0x78, 0x55, 0x73, 0x65, 0x72, 0x2a, 0x2d, 0x0a, 0x08, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x73, 0x12, 0x0b, 0x0a, 0x07, 0x75, 0x6e, 0x6b, 0x6e, 0x6f, 0x77, 0x6e, 0x10,
+	0x00, 0x12, 0x08, 0x0a, 0x04, 0x6d, 0x6f, 0x6e, 0x6f, 0x10, 0x01, 0x12, 0x0a, 0x0a, 0x06, 0x73,
+	0x74, 0x65, 0x72, 0x65, 0x6f, 0x10, 0x02, 0x2a, 0x2f, 0x0a, 0x05, 0x43, 0x6f, 0x64, 0x65, 0x63,
+	0x12, 0x08, 0x0a, 0x04, 0x6e, 0x6f, 0x6e, 0x65, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x6f, 0x70,
+	0x75, 0x73, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x70, 0x63, 0x6d, 0x10, 0x02, 0x12, 0x09, 0x0a,
+	0x05, 0x61, 0x64, 0x70, 0x63, 0x6d, 0x10, 0x03, 0x32, 0xe0, 0x03, 0x0a, 0x06, 0x53, 0x65, 0x72,
+	0x76, 0x65, 0x72, 0x12, 0x45, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69,
+	0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75,
+	0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x1c, 0x2e, 0x73,
+	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x43, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x08, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75,
+	0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x15, 0x2e, 0x73,
+	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x39, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x72, 0x74, 0x53, 0x74, 0x72, 0x65,
+	0x61, 0x6d, 0x12, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75,
+	0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x1a, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b,
+	0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x12, 0x38,
+	0x0a, 0x0a, 0x53, 0x74, 0x6f, 0x70, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x14, 0x2e, 0x73,
+	0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f,
+	0x6e, 0x65, 0x1a, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75,
+	0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x12, 0x3a, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67,
+	0x12, 0x18, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69,
+	0x6f, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x50, 0x6f, 0x6e, 0x67, 0x1a, 0x18, 0x2e, 0x73, 0x68, 0x61,
+	0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x50, 0x69, 0x6e, 0x67,
+	0x50, 0x6f, 0x6e, 0x67, 0x12, 0x55, 0x0a, 0x10, 0x4f, 0x70, 0x65, 0x6e, 0x4d, 0x65, 0x64, 0x69,
+	0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b,
+	0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53,
+	0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e,
+	0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4d,
+	0x65, 0x64, 0x69, 0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x4e, 0x0a, 0x11, 0x43,
+	0x6c, 0x6f, 0x73, 0x65, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x12, 0x23, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73, 0x2e, 0x61, 0x75, 0x64, 0x69,
+	0x6f, 0x2e, 0x4d, 0x65, 0x64, 0x69, 0x61, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x73, 0x68, 0x61, 0x63, 0x6b, 0x62, 0x75, 0x73,
+	0x2e, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x2e, 0x4e, 0x6f, 0x6e, 0x65, 0x42, 0x0c, 0x5a, 0x0a, 0x2e,
+	0x2f, 0x73, 0x62, 0x5f, 0x61, 0x75, 0x64, 0x69, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
 })
 
 var (
@@ -500,35 +1102,49 @@ func file_audio_proto_rawDescGZIP() []byte {
 	return file_audio_proto_rawDescData
 }
 
-var file_audio_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_audio_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_audio_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_audio_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
 var file_audio_proto_goTypes = []any{
-	(Channels)(0),        // 0: shackbus.audio.Channels
-	(Codec)(0),           // 1: shackbus.audio.Codec
-	(*None)(nil),         // 2: shackbus.audio.None
-	(*Capabilities)(nil), // 3: shackbus.audio.Capabilities
-	(*PingPong)(nil),     // 4: shackbus.audio.PingPong
-	(*Frame)(nil),        // 5: shackbus.audio.Frame
-	(*State)(nil),        // 6: shackbus.audio.State
+	(Channels)(0),               // 0: shackbus.audio.Channels
+	(Codec)(0),                  // 1: shackbus.audio.Codec
+	(Envelope_Type)(0),          // 2: shackbus.audio.Envelope.Type
+	(*None)(nil),                // 3: shackbus.audio.None
+	(*Capabilities)(nil),        // 4: shackbus.audio.Capabilities
+	(*MediaSessionRequest)(nil), // 5: shackbus.audio.MediaSessionRequest
+	(*MediaSession)(nil),        // 6: shackbus.audio.MediaSession
+	(*RxProfile)(nil),           // 7: shackbus.audio.RxProfile
+	(*CodecParameters)(nil),     // 8: shackbus.audio.CodecParameters
+	(*ReceptionReport)(nil),     // 9: shackbus.audio.ReceptionReport
+	(*PingPong)(nil),            // 10: shackbus.audio.PingPong
+	(*Frame)(nil),               // 11: shackbus.audio.Frame
+	(*Envelope)(nil),            // 12: shackbus.audio.Envelope
+	(*State)(nil),               // 13: shackbus.audio.State
 }
 var file_audio_proto_depIdxs = []int32{
-	1, // 0: shackbus.audio.Frame.codec:type_name -> shackbus.audio.Codec
-	0, // 1: shackbus.audio.Frame.channels:type_name -> shackbus.audio.Channels
-	2, // 2: shackbus.audio.Server.GetCapabilities:input_type -> shackbus.audio.None
-	2, // 3: shackbus.audio.Server.GetState:input_type -> shackbus.audio.None
-	2, // 4: shackbus.audio.Server.StartStream:input_type -> shackbus.audio.None
-	2, // 5: shackbus.audio.Server.StopStream:input_type -> shackbus.audio.None
-	4, // 6: shackbus.audio.Server.Ping:input_type -> shackbus.audio.PingPong
-	3, // 7: shackbus.audio.Server.GetCapabilities:output_type -> shackbus.audio.Capabilities
-	6, // 8: shackbus.audio.Server.GetState:output_type -> shackbus.audio.State
-	2, // 9: shackbus.audio.Server.StartStream:output_type -> shackbus.audio.None
-	2, // 10: shackbus.audio.Server.StopStream:output_type -> shackbus.audio.None
-	4, // 11: shackbus.audio.Server.Ping:output_type -> shackbus.audio.PingPong
-	7, // [7:12] is the sub-list for method output_type
-	2, // [2:7] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	8,  // 0: shackbus.audio.Capabilities.codecs:type_name -> shackbus.audio.CodecParameters
+	7,  // 1: shackbus.audio.Capabilities.rx_profiles:type_name -> shackbus.audio.RxProfile
+	1,  // 2: shackbus.audio.Frame.codec:type_name -> shackbus.audio.Codec
+	0,  // 3: shackbus.audio.Frame.channels:type_name -> shackbus.audio.Channels
+	2,  // 4: shackbus.audio.Envelope.type:type_name -> shackbus.audio.Envelope.Type
+	3,  // 5: shackbus.audio.Server.GetCapabilities:input_type -> shackbus.audio.None
+	3,  // 6: shackbus.audio.Server.GetState:input_type -> shackbus.audio.None
+	3,  // 7: shackbus.audio.Server.StartStream:input_type -> shackbus.audio.None
+	3,  // 8: shackbus.audio.Server.StopStream:input_type -> shackbus.audio.None
+	10, // 9: shackbus.audio.Server.Ping:input_type -> shackbus.audio.PingPong
+	5,  // 10: shackbus.audio.Server.OpenMediaSession:input_type -> shackbus.audio.MediaSessionRequest
+	5,  // 11: shackbus.audio.Server.CloseMediaSession:input_type -> shackbus.audio.MediaSessionRequest
+	4,  // 12: shackbus.audio.Server.GetCapabilities:output_type -> shackbus.audio.Capabilities
+	13, // 13: shackbus.audio.Server.GetState:output_type -> shackbus.audio.State
+	3,  // 14: shackbus.audio.Server.StartStream:output_type -> shackbus.audio.None
+	3,  // 15: shackbus.audio.Server.StopStream:output_type -> shackbus.audio.None
+	10, // 16: shackbus.audio.Server.Ping:output_type -> shackbus.audio.PingPong
+	6,  // 17: shackbus.audio.Server.OpenMediaSession:output_type -> shackbus.audio.MediaSession
+	3,  // 18: shackbus.audio.Server.CloseMediaSession:output_type -> shackbus.audio.None
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
 }
 
 func init() { file_audio_proto_init() }
@@ -541,8 +1157,8 @@ func file_audio_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_audio_proto_rawDesc), len(file_audio_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   5,
+			NumEnums:      3,
+			NumMessages:   11,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
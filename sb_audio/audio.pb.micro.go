@@ -41,6 +41,8 @@ type ServerService interface {
 	StartStream(ctx context.Context, in *None, opts ...client.CallOption) (*None, error)
 	StopStream(ctx context.Context, in *None, opts ...client.CallOption) (*None, error)
 	Ping(ctx context.Context, in *PingPong, opts ...client.CallOption) (*PingPong, error)
+	OpenMediaSession(ctx context.Context, in *MediaSessionRequest, opts ...client.CallOption) (*MediaSession, error)
+	CloseMediaSession(ctx context.Context, in *MediaSessionRequest, opts ...client.CallOption) (*None, error)
 }
 
 type serverService struct {
@@ -105,6 +107,26 @@ func (c *serverService) Ping(ctx context.Context, in *PingPong, opts ...client.C
 	return out, nil
 }
 
+func (c *serverService) OpenMediaSession(ctx context.Context, in *MediaSessionRequest, opts ...client.CallOption) (*MediaSession, error) {
+	req := c.c.NewRequest(c.name, "Server.OpenMediaSession", in)
+	out := new(MediaSession)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *serverService) CloseMediaSession(ctx context.Context, in *MediaSessionRequest, opts ...client.CallOption) (*None, error) {
+	req := c.c.NewRequest(c.name, "Server.CloseMediaSession", in)
+	out := new(None)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Server service
 
 type ServerHandler interface {
@@ -113,6 +135,8 @@ type ServerHandler interface {
 	StartStream(context.Context, *None, *None) error
 	StopStream(context.Context, *None, *None) error
 	Ping(context.Context, *PingPong, *PingPong) error
+	OpenMediaSession(context.Context, *MediaSessionRequest, *MediaSession) error
+	CloseMediaSession(context.Context, *MediaSessionRequest, *None) error
 }
 
 func RegisterServerHandler(s server.Server, hdlr ServerHandler, opts ...server.HandlerOption) error {
@@ -122,6 +146,8 @@ func RegisterServerHandler(s server.Server, hdlr ServerHandler, opts ...server.H
 		StartStream(ctx context.Context, in *None, out *None) error
 		StopStream(ctx context.Context, in *None, out *None) error
 		Ping(ctx context.Context, in *PingPong, out *PingPong) error
+		OpenMediaSession(ctx context.Context, in *MediaSessionRequest, out *MediaSession) error
+		CloseMediaSession(ctx context.Context, in *MediaSessionRequest, out *None) error
 	}
 	type Server struct {
 		server
@@ -153,3 +179,11 @@ func (h *serverHandler) StopStream(ctx context.Context, in *None, out *None) err
 func (h *serverHandler) Ping(ctx context.Context, in *PingPong, out *PingPong) error {
 	return h.ServerHandler.Ping(ctx, in, out)
 }
+
+func (h *serverHandler) OpenMediaSession(ctx context.Context, in *MediaSessionRequest, out *MediaSession) error {
+	return h.ServerHandler.OpenMediaSession(ctx, in, out)
+}
+
+func (h *serverHandler) CloseMediaSession(ctx context.Context, in *MediaSessionRequest, out *None) error {
+	return h.ServerHandler.CloseMediaSession(ctx, in, out)
+}
@@ -0,0 +1,28 @@
+package trx
+
+import "errors"
+
+// errNoAEC is returned if the Trx has been created without an echo
+// canceller in the tx chain
+var errNoAEC = errors.New("no echo canceller in the tx chain")
+
+// AECEnabled indicates if the echo canceller in the tx chain is enabled
+func (x *Trx) AECEnabled() (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.aec == nil {
+		return false, errNoAEC
+	}
+	return x.aec.Enabled(), nil
+}
+
+// SetAECEnabled enables or disables the echo canceller in the tx chain
+func (x *Trx) SetAECEnabled(enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.aec == nil {
+		return errNoAEC
+	}
+	x.aec.Enable(enable)
+	return nil
+}
@@ -0,0 +1,65 @@
+package trx
+
+import (
+	"errors"
+
+	"github.com/dh1tw/remoteAudio/audio/nodes/analyser"
+)
+
+// errNoAnalyser is returned if the Trx has been created without a
+// spectrum analyser in the rx chain
+var errNoAnalyser = errors.New("no spectrum analyser in the rx chain")
+
+// SetSpectrumCb sets the callback which is called with each spectrum
+// of the rx audio.
+func (x *Trx) SetSpectrumCb(cb analyser.OnSpectrumCb) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.analyser == nil {
+		return errNoAnalyser
+	}
+	x.analyser.SetSpectrumCb(cb)
+	return nil
+}
+
+// AnalyserSize returns the FFT size of the spectrum analyser
+func (x *Trx) AnalyserSize() (int, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.analyser == nil {
+		return 0, errNoAnalyser
+	}
+	return x.analyser.Size(), nil
+}
+
+// SetAnalyserSize sets the FFT size (power of two) of the spectrum analyser
+func (x *Trx) SetAnalyserSize(size int) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.analyser == nil {
+		return errNoAnalyser
+	}
+	return x.analyser.SetSize(size)
+}
+
+// AnalyserRate returns the amount of spectra per second calculated by
+// the spectrum analyser
+func (x *Trx) AnalyserRate() (float64, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.analyser == nil {
+		return 0, errNoAnalyser
+	}
+	return x.analyser.Rate(), nil
+}
+
+// SetAnalyserRate sets the amount of spectra per second calculated by
+// the spectrum analyser
+func (x *Trx) SetAnalyserRate(rate float64) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.analyser == nil {
+		return errNoAnalyser
+	}
+	return x.analyser.SetRate(rate)
+}
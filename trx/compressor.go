@@ -0,0 +1,125 @@
+package trx
+
+import "errors"
+
+// errNoCompressor is returned if the Trx has been created without
+// a compressor in the tx chain
+var errNoCompressor = errors.New("no compressor in the tx chain")
+
+// CompressorEnabled indicates if the compressor / limiter in the tx
+// chain is enabled
+func (x *Trx) CompressorEnabled() (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return false, errNoCompressor
+	}
+	return x.compressor.Enabled(), nil
+}
+
+// SetCompressorEnabled enables or disables the compressor / limiter
+// in the tx chain
+func (x *Trx) SetCompressorEnabled(enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return errNoCompressor
+	}
+	x.compressor.Enable(enable)
+	return nil
+}
+
+// CompressorThreshold returns the threshold (dBFS) of the compressor
+func (x *Trx) CompressorThreshold() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return 0, errNoCompressor
+	}
+	return x.compressor.Threshold(), nil
+}
+
+// SetCompressorThreshold sets the threshold (dBFS) of the compressor
+func (x *Trx) SetCompressorThreshold(db float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return errNoCompressor
+	}
+	x.compressor.SetThreshold(db)
+	return nil
+}
+
+// CompressorRatio returns the compression ratio
+func (x *Trx) CompressorRatio() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return 0, errNoCompressor
+	}
+	return x.compressor.Ratio(), nil
+}
+
+// SetCompressorRatio sets the compression ratio
+func (x *Trx) SetCompressorRatio(ratio float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return errNoCompressor
+	}
+	x.compressor.SetRatio(ratio)
+	return nil
+}
+
+// CompressorMakeupGain returns the makeup gain (dB) of the compressor
+func (x *Trx) CompressorMakeupGain() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return 0, errNoCompressor
+	}
+	return x.compressor.MakeupGain(), nil
+}
+
+// SetCompressorMakeupGain sets the makeup gain (dB) of the compressor
+func (x *Trx) SetCompressorMakeupGain(db float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return errNoCompressor
+	}
+	x.compressor.SetMakeupGain(db)
+	return nil
+}
+
+// CompressorLimit returns the ceiling (dBFS) of the limiter
+func (x *Trx) CompressorLimit() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return 0, errNoCompressor
+	}
+	return x.compressor.Limit(), nil
+}
+
+// SetCompressorLimit sets the ceiling (dBFS) of the limiter
+func (x *Trx) SetCompressorLimit(db float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return errNoCompressor
+	}
+	x.compressor.SetLimit(db)
+	return nil
+}
+
+// CompressorGainReduction returns the current gain reduction (dB) of
+// the compressor and the limiter
+func (x *Trx) CompressorGainReduction() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.compressor == nil {
+		return 0, errNoCompressor
+	}
+	return x.compressor.GainReduction(), nil
+}
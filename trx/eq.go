@@ -0,0 +1,71 @@
+package trx
+
+import (
+	"errors"
+
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+)
+
+// errNoEQ is returned if the Trx has been created without an
+// equalizer in the requested chain
+var errNoEQ = errors.New("no equalizer in the audio chain")
+
+// eqNode returns the equalizer of the tx or rx chain. This method is
+// not safe for concurrent access.
+func (x *Trx) eqNode(tx bool) (*eq.EQ, error) {
+	e := x.rxEQ
+	if tx {
+		e = x.txEQ
+	}
+	if e == nil {
+		return nil, errNoEQ
+	}
+	return e, nil
+}
+
+// EQEnabled indicates if the equalizer of the tx (or rx) chain is enabled
+func (x *Trx) EQEnabled(tx bool) (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	e, err := x.eqNode(tx)
+	if err != nil {
+		return false, err
+	}
+	return e.Enabled(), nil
+}
+
+// SetEQEnabled enables or disables the equalizer of the tx (or rx) chain
+func (x *Trx) SetEQEnabled(tx bool, enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	e, err := x.eqNode(tx)
+	if err != nil {
+		return err
+	}
+	e.Enable(enable)
+	return nil
+}
+
+// EQSections returns the filter sections of the equalizer of the
+// tx (or rx) chain
+func (x *Trx) EQSections(tx bool) ([]eq.Section, error) {
+	x.Lock()
+	defer x.Unlock()
+	e, err := x.eqNode(tx)
+	if err != nil {
+		return nil, err
+	}
+	return e.Sections(), nil
+}
+
+// SetEQSections replaces the filter sections of the equalizer of the
+// tx (or rx) chain
+func (x *Trx) SetEQSections(tx bool, sections []eq.Section) error {
+	x.Lock()
+	defer x.Unlock()
+	e, err := x.eqNode(tx)
+	if err != nil {
+		return err
+	}
+	return e.SetSections(sections)
+}
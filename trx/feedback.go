@@ -0,0 +1,106 @@
+package trx
+
+import (
+	"log"
+	"time"
+
+	"github.com/asim/go-micro/v3/broker"
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	sbAudio "github.com/dh1tw/remoteAudio/sb_audio"
+	"github.com/golang/protobuf/proto"
+)
+
+// feedbackInterval is the interval in which reception reports are sent
+// to the remote audio server
+const feedbackInterval = time.Second * 2
+
+// subscribeFeedback subscribes to the reception reports which the remote
+// audio server publishes about the audio streams it receives. This method
+// is not safe for concurrent access.
+func (x *Trx) subscribeFeedback(address string) error {
+
+	if x.fbSub != nil {
+		if err := x.fbSub.Unsubscribe(); err != nil {
+			return err
+		}
+		x.fbSub = nil
+	}
+
+	// the server doesn't support reception reports
+	if len(address) == 0 {
+		return nil
+	}
+
+	sub, err := x.broker.Subscribe(address, x.txFeedbackCb)
+	if err != nil {
+		return err
+	}
+	x.fbSub = sub
+
+	return nil
+}
+
+// txFeedbackCb passes the reception reports about our own audio stream
+// on to the toNetwork pbWriter, so that the encoder can adapt to the
+// network conditions.
+func (x *Trx) txFeedbackCb(msg broker.Event) error {
+
+	report := sbAudio.ReceptionReport{}
+	if err := proto.Unmarshal(msg.Message().Body, &report); err != nil {
+		return err
+	}
+
+	if report.GetSenderId() != x.toNetwork.UserID() {
+		return nil
+	}
+
+	return x.toNetwork.Feedback(audiocodec.Feedback{
+		Loss:   float64(report.GetLoss()),
+		Jitter: time.Duration(report.GetJitter()) * time.Microsecond,
+	})
+}
+
+// feedbackLoop is a blocking function which periodically reports the
+// reception of the audio stream back to the selected remote audio server.
+func (x *Trx) feedbackLoop() {
+
+	ticker := time.NewTicker(feedbackInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+
+		x.RLock()
+		svr := x.curServer
+		x.RUnlock()
+
+		if svr == nil || len(svr.RxFeedbackAddress()) == 0 {
+			continue
+		}
+
+		fb, err := x.fromNetwork.Feedback(svr.Name())
+		if err != nil {
+			continue // no audio received from the server
+		}
+
+		report := sbAudio.ReceptionReport{
+			SenderId:   svr.Name(),
+			ReceiverId: x.toNetwork.UserID(),
+			Loss:       float32(fb.Loss),
+			Jitter:     fb.Jitter.Microseconds(),
+		}
+
+		data, err := proto.Marshal(&report)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+
+		msg := &broker.Message{
+			Body: data,
+		}
+
+		if err := x.broker.Publish(svr.RxFeedbackAddress(), msg); err != nil {
+			log.Println(err)
+		}
+	}
+}
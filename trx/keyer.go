@@ -0,0 +1,335 @@
+package trx
+
+import (
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/audiofile"
+	"github.com/dh1tw/remoteAudio/keyer"
+)
+
+// errNoKeyer is returned if the Trx has been created without a voice keyer
+var errNoKeyer = errors.New("no voice keyer available")
+
+// keyerSourceName is the name of the voice keyer's source in the tx chain
+const keyerSourceName = "keyer"
+
+// keyerTail is the time the transmitter is kept keyed after the end of
+// a message, so that the audio which is still in the nodes of the tx
+// chain is sent.
+const keyerTail = time.Millisecond * 300
+
+// KeyerState contains the state of the voice keyer.
+type KeyerState struct {
+	Message  string        // message being played; empty if stopped
+	OnAir    bool          // the message is currently transmitted
+	Repeat   bool          // the message is repeated
+	Interval time.Duration // pause between the repetitions
+}
+
+// keyerSource wraps the source of a voice keyer message and signals when
+// the end of the message has been reached.
+type keyerSource struct {
+	audio.Source
+	done chan struct{}
+}
+
+// SetCb sets the callback of the wrapped source.
+func (k *keyerSource) SetCb(cb audio.OnDataCb) {
+	if cb == nil {
+		k.Source.SetCb(nil)
+		return
+	}
+	k.Source.SetCb(func(msg audio.Msg) {
+		cb(msg)
+		if msg.EOF {
+			select {
+			case k.done <- struct{}{}:
+			default:
+			}
+		}
+	})
+}
+
+// KeyerMessages returns the messages of the voice keyer.
+func (x *Trx) KeyerMessages() ([]keyer.Message, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.keyer == nil {
+		return nil, errNoKeyer
+	}
+	return x.keyer.Messages()
+}
+
+// SaveKeyerMessage stores the WAV, FLAC or Ogg Opus file read from r as a
+// voice keyer message with the given name.
+func (x *Trx) SaveKeyerMessage(name string, r io.Reader) (keyer.Message, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.keyer == nil {
+		return keyer.Message{}, errNoKeyer
+	}
+	return x.keyer.Save(name, r)
+}
+
+// DeleteKeyerMessage removes a voice keyer message. The message can't be
+// deleted while it is played.
+func (x *Trx) DeleteKeyerMessage(name string) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.keyer == nil {
+		return errNoKeyer
+	}
+	if x.keyerState.Message == name {
+		return errors.New("message is currently played")
+	}
+	return x.keyer.Delete(name)
+}
+
+// KeyerState returns the state of the voice keyer.
+func (x *Trx) KeyerState() (KeyerState, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.keyer == nil {
+		return KeyerState{}, errNoKeyer
+	}
+	return x.keyerState, nil
+}
+
+// PlayKeyer transmits a voice keyer message. The transmitter is keyed
+// automatically while the message is played. If repeat is true, the
+// message is transmitted again after the interval until the playback is
+// stopped. A message which is currently played will be aborted. If the
+// tx chain has a mixer, the message is mixed with the microphone instead
+// of replacing it.
+// Activating the PTT, speaking into the microphone (VOX) or selecting
+// another tx source will stop the playback.
+func (x *Trx) PlayKeyer(name string, repeat bool, interval time.Duration) error {
+	x.Lock()
+	defer x.Unlock()
+
+	if x.keyer == nil {
+		return errNoKeyer
+	}
+
+	path, err := x.keyer.Path(name)
+	if err != nil {
+		return err
+	}
+
+	src, err := audiofile.NewSource(path)
+	if err != nil {
+		return err
+	}
+
+	x.stopKeyer(true)
+
+	done := make(chan struct{}, 1)
+	x.keyerSrc = &keyerSource{src, done}
+	x.tx.Sources.AddSource(keyerSourceName, x.keyerSrc)
+	if x.txMixer != nil {
+		if err := x.txMixer.SetGain(keyerSourceName, x.mixerGain(keyerSourceName)); err != nil {
+			log.Println(err)
+		}
+	}
+
+	stop := make(chan struct{})
+	x.keyerStop = stop
+	x.keyerState = KeyerState{
+		Message:  name,
+		Repeat:   repeat,
+		Interval: interval,
+	}
+
+	if err := x.startKeyer(); err != nil {
+		x.stopKeyer(true)
+		return err
+	}
+
+	go x.runKeyer(stop, done)
+
+	return nil
+}
+
+// StopKeyer stops the playback of the voice keyer.
+func (x *Trx) StopKeyer() error {
+	x.Lock()
+	defer x.Unlock()
+	if x.keyer == nil {
+		return errNoKeyer
+	}
+	x.stopKeyer(true)
+	return nil
+}
+
+// startKeyer selects the voice keyer as source of the tx chain and keys
+// the transmitter. This method is not safe for concurrent access.
+func (x *Trx) startKeyer() error {
+	if x.txMixer != nil {
+		if err := x.txMixer.EnableSource(keyerSourceName, true); err != nil {
+			return err
+		}
+	} else if err := x.tx.Sources.SetSource(keyerSourceName); err != nil {
+		return err
+	}
+	x.keyerState.OnAir = true
+	x.monitorMic(true)
+	x.notifyKeyerChange()
+	return x.setTxState(true)
+}
+
+// monitorMic lets the vox evaluate the microphone instead of the audio
+// in the tx chain while the voice keyer is on air. This way the message
+// doesn't trigger the vox, but speaking into the microphone aborts the
+// playback. The microphone audio passes neither the echo canceller nor
+// the tx chain. This method is not safe for concurrent access.
+func (x *Trx) monitorMic(on bool) {
+	x.vox.SetSidechain(on)
+
+	// the microphone remains in the mix
+	if x.txMixer != nil {
+		var cb audio.OnDataCb
+		if on {
+			cb = x.vox.WriteSidechain
+		}
+		if err := x.txMixer.SetMonitorCb(x.tx.DefaultSource(), cb); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	if !on || x.mic == nil {
+		return
+	}
+	// the microphone has been stopped when the keyer has been selected
+	// as source of the tx chain; it is handed back to the tx chain
+	// when the default source is selected again
+	x.mic.SetCb(x.vox.WriteSidechain)
+	if err := x.mic.Start(); err != nil {
+		log.Println(err)
+	}
+}
+
+// stopKeyer aborts the playback of the voice keyer. If restore is true,
+// the default source of the tx chain is selected again. This method is
+// not safe for concurrent access.
+func (x *Trx) stopKeyer(restore bool) {
+	if x.keyerStop == nil {
+		return
+	}
+	close(x.keyerStop)
+	x.keyerStop = nil
+
+	onAir := x.keyerState.OnAir
+	x.keyerState = KeyerState{}
+
+	if onAir {
+		x.monitorMic(false)
+	}
+	if onAir && restore {
+		if err := x.tx.Sources.SetSource(x.tx.DefaultSource()); err != nil {
+			log.Println(err)
+		}
+	}
+	if err := x.tx.Sources.RemoveSource(keyerSourceName); err != nil {
+		log.Println(err)
+	}
+	// closes the file of the message
+	if err := x.keyerSrc.Close(); err != nil {
+		log.Println(err)
+	}
+	x.keyerSrc = nil
+
+	if onAir && !x.pttActive && !x.voxActive {
+		if err := x.setTxState(false); err != nil {
+			log.Println(err)
+		}
+	}
+	x.notifyKeyerChange()
+}
+
+// runKeyer unkeys the transmitter at the end of each message and
+// repeats the message if requested, until stop is closed.
+func (x *Trx) runKeyer(stop, done chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-done:
+		}
+
+		// the tx chain switches back to the default source by itself,
+		// from now on the vox evaluates the microphone in the tx chain
+		x.Lock()
+		select {
+		case <-stop:
+			x.Unlock()
+			return
+		default:
+		}
+		if x.txMixer == nil {
+			x.monitorMic(false)
+		}
+		x.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(keyerTail):
+		}
+
+		x.Lock()
+		select {
+		case <-stop:
+			x.Unlock()
+			return
+		default:
+		}
+		x.keyerState.OnAir = false
+		x.monitorMic(false)
+		if !x.pttActive && !x.voxActive {
+			if err := x.setTxState(false); err != nil {
+				log.Println(err)
+			}
+		}
+		if !x.keyerState.Repeat {
+			x.stopKeyer(false)
+			x.Unlock()
+			return
+		}
+		interval := x.keyerState.Interval
+		x.notifyKeyerChange()
+		x.Unlock()
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(interval):
+		}
+
+		x.Lock()
+		select {
+		case <-stop:
+			x.Unlock()
+			return
+		default:
+		}
+		if err := x.startKeyer(); err != nil {
+			log.Println(err)
+			x.stopKeyer(true)
+		}
+		x.Unlock()
+	}
+}
+
+// notifyKeyerChange informs the parent application that the state of
+// the voice keyer has changed. This method is not safe for concurrent
+// access.
+func (x *Trx) notifyKeyerChange() {
+	if x.notifyServerChangeCb != nil {
+		go x.notifyServerChangeCb()
+	}
+}
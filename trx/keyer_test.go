@@ -0,0 +1,332 @@
+package trx
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/audiofile"
+	"github.com/dh1tw/remoteAudio/audio/chain"
+	"github.com/dh1tw/remoteAudio/audio/nodes/vox"
+	"github.com/dh1tw/remoteAudio/audio/sinks/pbWriter"
+	"github.com/dh1tw/remoteAudio/audio/sources/pbReader"
+	"github.com/dh1tw/remoteAudio/audiocodec/pcm"
+	"github.com/dh1tw/remoteAudio/direct"
+	"github.com/dh1tw/remoteAudio/keyer"
+)
+
+// testMic is the microphone of the tx chain. The test speaks into it
+// with write.
+type testMic struct {
+	sync.Mutex
+	cb      audio.OnDataCb
+	running bool
+}
+
+func (m *testMic) Start() error {
+	m.Lock()
+	defer m.Unlock()
+	m.running = true
+	return nil
+}
+
+func (m *testMic) Stop() error {
+	m.Lock()
+	defer m.Unlock()
+	m.running = false
+	return nil
+}
+
+func (m *testMic) Close() error { return m.Stop() }
+
+func (m *testMic) SetCb(cb audio.OnDataCb) {
+	m.Lock()
+	defer m.Unlock()
+	m.cb = cb
+}
+
+// write passes 10ms of audio with the given level to the callback of
+// the running microphone.
+func (m *testMic) write(level float32) {
+	m.Lock()
+	cb := m.cb
+	running := m.running
+	m.Unlock()
+	if cb == nil || !running {
+		return
+	}
+	data := make([]float32, 480)
+	for i := range data {
+		data[i] = level
+	}
+	cb(audio.Msg{Data: data, Samplerate: 48000, Channels: 1, Frames: 480})
+}
+
+// newKeyerTrx returns a Trx with a voice keyer which contains the 50ms
+// message "cq". The vox in the tx chain activates the VOX of the Trx.
+func newKeyerTrx(t *testing.T) (*Trx, *testMic) {
+	t.Helper()
+
+	dir := t.TempDir()
+	sink, err := audiofile.NewSink(filepath.Join(dir, "cq.wav"),
+		audiofile.Samplerate(48000), audiofile.Channels(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := sink.Write(audio.Msg{Data: make([]float32, 480), Samplerate: 48000, Channels: 1, Frames: 480}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err := keyer.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromNetwork, err := pbReader.NewPbReader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := pcm.NewEncoder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	toNetwork, err := pbWriter.NewPbWriter(pbWriter.Encoder(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rx, err := chain.NewChain(chain.DefaultSource("fromNetwork"), chain.DefaultSink("speaker"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx.Sources.AddSource("fromNetwork", fromNetwork)
+
+	var x *Trx
+	v := vox.New(vox.Enabled(true), vox.StateChanged(func(on bool) {
+		if err := x.SetVOX(on); err != nil {
+			t.Error(err)
+		}
+	}))
+
+	mic := &testMic{}
+	tx, err := chain.NewChain(chain.DefaultSource("mic"), chain.DefaultSink("toNetwork"),
+		chain.Node(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx.Sources.AddSource("mic", mic)
+	if err := tx.Sinks.AddSink("toNetwork", toNetwork, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Sources.SetSource("mic"); err != nil {
+		t.Fatal(err)
+	}
+
+	x, err = NewTrx(Options{
+		Rx:          rx,
+		Tx:          tx,
+		FromNetwork: fromNetwork,
+		ToNetwork:   toNetwork,
+		Broker:      direct.NewBroker(),
+		Vox:         v,
+		Keyer:       store,
+		Mic:         mic,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return x, mic
+}
+
+// waitKeyer waits until the state of the voice keyer fulfills cond.
+func waitKeyer(t *testing.T, x *Trx, desc string, cond func(KeyerState) bool) {
+	t.Helper()
+	timeout := time.After(time.Second * 2)
+	for {
+		s, err := x.KeyerState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cond(s) {
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatalf("timeout while waiting for %s, state: %+v", desc, s)
+		case <-time.After(time.Millisecond * 5):
+		}
+	}
+}
+
+func stopped(s KeyerState) bool { return s == KeyerState{} }
+func onAir(s KeyerState) bool   { return s.Message == "cq" && s.OnAir }
+func pausing(s KeyerState) bool { return s.Message == "cq" && !s.OnAir }
+
+func TestKeyerEndOfMessage(t *testing.T) {
+
+	x, mic := newKeyerTrx(t)
+
+	if err := x.PlayKeyer("cq", false, 0); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := x.KeyerState()
+	if !onAir(s) {
+		t.Fatalf("unexpected state %+v", s)
+	}
+
+	waitKeyer(t, x, "the end of the message", stopped)
+
+	// the microphone is the source of the tx chain again
+	mic.Lock()
+	running := mic.running
+	mic.Unlock()
+	if !running {
+		t.Error("microphone has not been restarted")
+	}
+	if err := x.tx.Sources.SetSource(keyerSourceName); err == nil {
+		t.Error("keyer source has not been removed from the tx chain")
+	}
+}
+
+func TestKeyerRepeat(t *testing.T) {
+
+	x, _ := newKeyerTrx(t)
+
+	if err := x.PlayKeyer("cq", true, time.Millisecond*100); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := x.KeyerState()
+	if !s.Repeat || s.Interval != time.Millisecond*100 {
+		t.Fatalf("unexpected state %+v", s)
+	}
+
+	waitKeyer(t, x, "the interval", pausing)
+	waitKeyer(t, x, "the repetition", onAir)
+	waitKeyer(t, x, "the next interval", pausing)
+
+	if err := x.StopKeyer(); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = x.KeyerState()
+	if !stopped(s) {
+		t.Errorf("unexpected state %+v", s)
+	}
+
+	// no further repetition after the keyer has been stopped
+	time.Sleep(time.Millisecond * 200)
+	s, _ = x.KeyerState()
+	if !stopped(s) {
+		t.Errorf("keyer restarted, state: %+v", s)
+	}
+}
+
+func TestKeyerAbort(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		wait  func(KeyerState) bool // state in which the keyer is aborted
+		abort func(t *testing.T, x *Trx, mic *testMic)
+	}{
+		{
+			name: "ptt on air",
+			wait: onAir,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				if err := x.SetPTT(true); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "ptt in interval",
+			wait: pausing,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				if err := x.SetPTT(true); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "vox in interval",
+			wait: pausing,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				if err := x.SetVOX(true); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "mic sidechain on air",
+			wait: onAir,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				mic.write(0.5)
+			},
+		},
+		{
+			name: "mic after the end of the message",
+			wait: onAir,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				// the tx chain is fed by the mic again, while the
+				// transmitter is still keyed for the keyerTail
+				time.Sleep(time.Millisecond * 150)
+				mic.write(0.01)
+				s, _ := x.KeyerState()
+				if !onAir(s) {
+					t.Fatalf("unexpected state %+v", s)
+				}
+				mic.write(0.5)
+			},
+		},
+		{
+			name: "tx source on air",
+			wait: onAir,
+			abort: func(t *testing.T, x *Trx, mic *testMic) {
+				if err := x.SelectTxSource("mic"); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			x, mic := newKeyerTrx(t)
+
+			if err := x.PlayKeyer("cq", true, time.Second*2); err != nil {
+				t.Fatal(err)
+			}
+			waitKeyer(t, x, "the keyer", tc.wait)
+
+			tc.abort(t, x, mic)
+			waitKeyer(t, x, "the abort", stopped)
+		})
+	}
+}
+
+func TestKeyerDeletePlaying(t *testing.T) {
+
+	x, _ := newKeyerTrx(t)
+
+	if err := x.PlayKeyer("cq", true, time.Second*2); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.DeleteKeyerMessage("cq"); err == nil {
+		t.Fatal("message deleted while it is played")
+	}
+
+	if err := x.StopKeyer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.DeleteKeyerMessage("cq"); err != nil {
+		t.Fatal(err)
+	}
+	if err := x.PlayKeyer("cq", false, 0); err == nil {
+		t.Error("deleted message played")
+	}
+}
@@ -0,0 +1,32 @@
+package trx
+
+import (
+	"errors"
+
+	"github.com/dh1tw/remoteAudio/audio/nodes/meter"
+)
+
+// errNoMeter is returned if the Trx has been created without a level
+// meter in the requested chain
+var errNoMeter = errors.New("no level meter in the audio chain")
+
+// RxLevel returns the levels of the rx audio since the last call.
+func (x *Trx) RxLevel() (meter.Level, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.rxMeter == nil {
+		return meter.Level{}, errNoMeter
+	}
+	return x.rxMeter.Level(), nil
+}
+
+// TxLevel returns the levels of the tx audio (the input of the vox)
+// since the last call.
+func (x *Trx) TxLevel() (meter.Level, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.txMeter == nil {
+		return meter.Level{}, errNoMeter
+	}
+	return x.txMeter.Level(), nil
+}
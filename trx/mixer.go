@@ -0,0 +1,75 @@
+package trx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errNoMixer is returned if the Trx has been created without a mixer in
+// the tx chain
+var errNoMixer = errors.New("no mixer in the tx chain")
+
+// mixerInputs returns the names of the sources of the mixer in the tx
+// chain (microphone and voice keyer).
+func (x *Trx) mixerInputs() []string {
+	return []string{x.tx.DefaultSource(), keyerSourceName}
+}
+
+// mixerGain returns the gain of a source of the mixer in the tx chain.
+// This method is not safe for concurrent access.
+func (x *Trx) mixerGain(name string) float32 {
+	if g, ok := x.mixerGains[name]; ok {
+		return g
+	}
+	return 1
+}
+
+// MixerGains returns the gains of the sources of the mixer in the tx chain.
+func (x *Trx) MixerGains() (map[string]float32, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.txMixer == nil {
+		return nil, errNoMixer
+	}
+
+	gains := make(map[string]float32)
+	for _, name := range x.mixerInputs() {
+		gains[name] = x.mixerGain(name)
+	}
+	return gains, nil
+}
+
+// SetMixerGain sets the gain of a source (microphone or voice keyer) of
+// the mixer in the tx chain. Only values between 0...2 are allowed.
+// Values below or above will be clipped to the minimum or maximum.
+func (x *Trx) SetMixerGain(name string, gain float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.txMixer == nil {
+		return errNoMixer
+	}
+
+	valid := false
+	for _, input := range x.mixerInputs() {
+		if input == name {
+			valid = true
+		}
+	}
+	if !valid {
+		return fmt.Errorf("unknown mixer source %s", name)
+	}
+
+	if gain < 0 {
+		gain = 0
+	} else if gain > 2 {
+		gain = 2
+	}
+	x.mixerGains[name] = gain
+
+	// the voice keyer is only a source of the mixer while a message
+	// is played; the gain is applied with the next message
+	if name == keyerSourceName && x.keyerSrc == nil {
+		return nil
+	}
+	return x.txMixer.SetGain(name, gain)
+}
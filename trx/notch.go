@@ -0,0 +1,74 @@
+package trx
+
+import "errors"
+
+// errNoNotch is returned if the Trx has been created without a notch
+// filter in the rx chain
+var errNoNotch = errors.New("no notch filter in the rx chain")
+
+// NotchAuto indicates if the automatic notch filter in the rx chain
+// is enabled
+func (x *Trx) NotchAuto() (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return false, errNoNotch
+	}
+	return x.notch.Auto(), nil
+}
+
+// SetNotchAuto enables or disables the automatic notch filter in the
+// rx chain
+func (x *Trx) SetNotchAuto(enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return errNoNotch
+	}
+	x.notch.SetAuto(enable)
+	return nil
+}
+
+// NotchManual indicates if the manual notch filter in the rx chain
+// is enabled
+func (x *Trx) NotchManual() (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return false, errNoNotch
+	}
+	return x.notch.Manual(), nil
+}
+
+// SetNotchManual enables or disables the manual notch filter in the
+// rx chain
+func (x *Trx) SetNotchManual(enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return errNoNotch
+	}
+	x.notch.SetManual(enable)
+	return nil
+}
+
+// NotchFrequency returns the frequency (Hz) of the manual notch filter
+func (x *Trx) NotchFrequency() (float64, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return 0, errNoNotch
+	}
+	return x.notch.Frequency(), nil
+}
+
+// SetNotchFrequency sets the frequency (Hz) of the manual notch filter
+func (x *Trx) SetNotchFrequency(f float64) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.notch == nil {
+		return errNoNotch
+	}
+	x.notch.SetFrequency(f)
+	return nil
+}
@@ -0,0 +1,49 @@
+package trx
+
+import "errors"
+
+// errNoNR is returned if the Trx has been created without a noise
+// reduction in the rx chain
+var errNoNR = errors.New("no noise reduction in the rx chain")
+
+// NREnabled indicates if the noise reduction in the rx chain is enabled
+func (x *Trx) NREnabled() (bool, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.nr == nil {
+		return false, errNoNR
+	}
+	return x.nr.Enabled(), nil
+}
+
+// SetNREnabled enables or disables the noise reduction in the rx chain
+func (x *Trx) SetNREnabled(enable bool) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.nr == nil {
+		return errNoNR
+	}
+	x.nr.Enable(enable)
+	return nil
+}
+
+// NRStrength returns the strength (0...1) of the noise reduction
+func (x *Trx) NRStrength() (float32, error) {
+	x.Lock()
+	defer x.Unlock()
+	if x.nr == nil {
+		return 0, errNoNR
+	}
+	return x.nr.Strength(), nil
+}
+
+// SetNRStrength sets the strength (0...1) of the noise reduction
+func (x *Trx) SetNRStrength(s float32) error {
+	x.Lock()
+	defer x.Unlock()
+	if x.nr == nil {
+		return errNoNR
+	}
+	x.nr.SetStrength(s)
+	return nil
+}
@@ -0,0 +1,192 @@
+package trx
+
+import (
+	"errors"
+	"log"
+
+	"github.com/dh1tw/remoteAudio/recorder"
+)
+
+// errNoRecorder is returned if the Trx has been created without a store
+// for the recordings
+var errNoRecorder = errors.New("recording not available")
+
+// recorderSinkName is the name of the recorders in the rx and tx chain
+const recorderSinkName = "recorder"
+
+// SetRxRecording starts or stops the recording of the rx audio. WAV / FLAC
+// recordings contain the audio as it is played on the speaker; Ogg Opus
+// recordings contain the frames as they are received from the server.
+func (x *Trx) SetRxRecording(on bool) error {
+	x.Lock()
+	defer x.Unlock()
+
+	if x.recordings == nil {
+		return errNoRecorder
+	}
+
+	if on == (x.rxRecorder != nil) {
+		return nil
+	}
+
+	if on {
+		r := x.recordings.NewRecorder(x.rxLabel(), x.recOpts...)
+		if r.Format() != recorder.FormatOpus {
+			if err := x.rx.Sinks.AddSink(recorderSinkName, r, true); err != nil {
+				return err
+			}
+		}
+		x.setRecorder(&x.rxRecorder, r)
+		return nil
+	}
+
+	if x.rxRecorder.Format() != recorder.FormatOpus {
+		if err := x.rx.Sinks.RemoveSink(recorderSinkName); err != nil {
+			return err
+		}
+	}
+	err := x.rxRecorder.Close()
+	x.setRecorder(&x.rxRecorder, nil)
+	return err
+}
+
+// RxRecording indicates if the rx audio is being recorded.
+func (x *Trx) RxRecording() bool {
+	x.RLock()
+	defer x.RUnlock()
+	return x.rxRecorder != nil
+}
+
+// SetTxRecording starts or stops the recording of the tx audio. Only the
+// audio which is sent to the remote audio server is recorded.
+func (x *Trx) SetTxRecording(on bool) error {
+	x.Lock()
+	defer x.Unlock()
+
+	if x.recordings == nil {
+		return errNoRecorder
+	}
+
+	if on == (x.txRecorder != nil) {
+		return nil
+	}
+
+	if on {
+		r := x.recordings.NewRecorder(x.txLabel(), x.recOpts...)
+		if r.Format() != recorder.FormatOpus {
+			txOn, err := x.tx.Enabled()
+			if err != nil {
+				return err
+			}
+			if err := x.tx.Sinks.AddSink(recorderSinkName, r, txOn); err != nil {
+				return err
+			}
+		}
+		x.setRecorder(&x.txRecorder, r)
+		return nil
+	}
+
+	if x.txRecorder.Format() != recorder.FormatOpus {
+		if err := x.tx.Sinks.RemoveSink(recorderSinkName); err != nil {
+			return err
+		}
+	}
+	err := x.txRecorder.Close()
+	x.setRecorder(&x.txRecorder, nil)
+	return err
+}
+
+// setRecorder sets the rx or tx recorder. The recorders are additionally
+// protected by recMu, since they are accessed from the network callbacks.
+// This method is not safe for concurrent access.
+func (x *Trx) setRecorder(rec **recorder.Recorder, r *recorder.Recorder) {
+	x.recMu.Lock()
+	defer x.recMu.Unlock()
+	*rec = r
+}
+
+// recordFrame writes an encoded audio frame into an Ogg Opus recording.
+func (x *Trx) recordFrame(rec **recorder.Recorder, data []byte) {
+	x.recMu.Lock()
+	r := *rec
+	x.recMu.Unlock()
+
+	if r == nil {
+		return
+	}
+	if err := r.Enqueue(data); err != nil {
+		log.Println(err)
+	}
+}
+
+// TxRecording indicates if the tx audio is being recorded.
+func (x *Trx) TxRecording() bool {
+	x.RLock()
+	defer x.RUnlock()
+	return x.txRecorder != nil
+}
+
+// Recordings returns the recordings in the store.
+func (x *Trx) Recordings() ([]recorder.Recording, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.recordings == nil {
+		return nil, errNoRecorder
+	}
+	return x.recordings.Recordings()
+}
+
+// RecordingPath returns the path of a recording (e.g. for downloading).
+func (x *Trx) RecordingPath(name string) (string, error) {
+	x.RLock()
+	defer x.RUnlock()
+	if x.recordings == nil {
+		return "", errNoRecorder
+	}
+	return x.recordings.Path(name)
+}
+
+// DeleteRecording removes a recording from the store.
+func (x *Trx) DeleteRecording(name string) error {
+	x.RLock()
+	defer x.RUnlock()
+	if x.recordings == nil {
+		return errNoRecorder
+	}
+	return x.recordings.Delete(name)
+}
+
+// rxLabel returns the label of the rx recordings, consisting of the
+// server name and the user who is transmitting. This method is not safe
+// for concurrent access.
+func (x *Trx) rxLabel() string {
+	if x.curServer == nil {
+		return recorder.Label("rx")
+	}
+	return recorder.Label("rx", x.curServer.Name(), x.curServer.TxUser())
+}
+
+// txLabel returns the label of the tx recordings, consisting of the
+// server name and our user name. This method is not safe for concurrent
+// access.
+func (x *Trx) txLabel() string {
+	if x.curServer == nil {
+		return recorder.Label("tx", x.toNetwork.UserID())
+	}
+	return recorder.Label("tx", x.curServer.Name(), x.toNetwork.UserID())
+}
+
+// updateRecorderLabels updates the names of the recordings after the
+// server or the transmitting user has changed. While nobody is
+// transmitting, the rx recording continues in the current file. This
+// method is not safe for concurrent access.
+func (x *Trx) updateRecorderLabels(serverChanged bool) {
+	if x.rxRecorder != nil && x.curServer != nil {
+		if serverChanged || len(x.curServer.TxUser()) > 0 {
+			x.rxRecorder.SetLabel(x.rxLabel())
+		}
+	}
+	if x.txRecorder != nil {
+		x.txRecorder.SetLabel(x.txLabel())
+	}
+}
@@ -3,9 +3,20 @@ package trx
 import (
 	"fmt"
 	"log"
+	"net"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/dh1tw/remoteAudio/audio"
+	"github.com/dh1tw/remoteAudio/audio/nodes/aec"
+	"github.com/dh1tw/remoteAudio/audio/nodes/analyser"
+	"github.com/dh1tw/remoteAudio/audio/nodes/compressor"
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+	"github.com/dh1tw/remoteAudio/audio/nodes/meter"
+	"github.com/dh1tw/remoteAudio/audio/nodes/notch"
+	"github.com/dh1tw/remoteAudio/audio/nodes/nr"
+	"github.com/dh1tw/remoteAudio/audio/nodes/tap"
 	"github.com/dh1tw/remoteAudio/audio/nodes/vox"
 	"github.com/dh1tw/remoteAudio/audio/sinks/pbWriter"
 
@@ -13,7 +24,11 @@ import (
 
 	"github.com/asim/go-micro/v3/broker"
 	"github.com/dh1tw/remoteAudio/audio/chain"
+	"github.com/dh1tw/remoteAudio/audiocodec"
+	"github.com/dh1tw/remoteAudio/keyer"
 	"github.com/dh1tw/remoteAudio/proxy"
+	"github.com/dh1tw/remoteAudio/recorder"
+	"github.com/dh1tw/remoteAudio/rtp"
 )
 
 // Trx is a data structure which holds the components needed for a 2-way
@@ -28,10 +43,41 @@ type Trx struct {
 	broker               broker.Broker
 	servers              map[string]*proxy.AudioServer
 	rxAudioSub           broker.Subscriber
+	fbSub                broker.Subscriber
 	curServer            *proxy.AudioServer
 	pttActive            bool
 	voxActive            bool
 	vox                  *vox.Vox
+	compressor           *compressor.Compressor
+	rxEQ                 *eq.EQ
+	txEQ                 *eq.EQ
+	nr                   *nr.NR
+	notch                *notch.Notch
+	rxMeter              *meter.Meter
+	txMeter              *meter.Meter
+	analyser             *analyser.Analyser
+	aec                  *aec.AEC
+	keyer                *keyer.Store
+	keyerState           KeyerState
+	keyerStop            chan struct{} // closed when the keyer playback is stopped
+	keyerSrc             audio.Source  // source of the keyer message being played
+	mic                  audio.Source
+	txMixer              *audio.Mixer
+	mixerGains           map[string]float32 // gains of the tx mixer's sources
+	recordings           *recorder.Store
+	recOpts              []recorder.Option
+	recMu                sync.Mutex // protects the recorders in the network callbacks
+	rxRecorder           *recorder.Recorder
+	txRecorder           *recorder.Recorder
+	encoders             map[string]audiocodec.Encoder
+	newEncoder           EncoderFactory
+	rxProfile            string // desired rx profile; empty for the default stream
+	autoRxProfile        bool   // select the rx profile based on the latency
+	rxAddress            string // address we are currently subscribed to
+	rxStream             string // address of the audio stream received (broker or media path)
+	media                *rtp.Conn
+	mediaServer          *proxy.AudioServer // server of the open media session
+	mediaSession         uint32
 	notifyServerChangeCb func()
 }
 
@@ -44,8 +90,30 @@ type Options struct {
 	ToNetwork   *pbWriter.PbWriter
 	Broker      broker.Broker
 	Vox         *vox.Vox
+	Compressor  *compressor.Compressor // optional; speech compressor in the tx chain
+	RxEQ        *eq.EQ                 // optional; filter / equalizer in the rx chain
+	TxEQ        *eq.EQ                 // optional; filter / equalizer in the tx chain
+	NR          *nr.NR                 // optional; noise reduction in the rx chain
+	Notch       *notch.Notch           // optional; auto / manual notch in the rx chain
+	RxMeter     *meter.Meter           // optional; level meter in the rx chain
+	TxMeter     *meter.Meter           // optional; level meter of the vox input in the tx chain
+	Analyser    *analyser.Analyser     // optional; spectrum analyser in the rx chain
+	AEC         *aec.AEC               // optional; echo canceller in the tx chain
+	SpeakerTap  *tap.Tap               // optional; provides the speaker audio (end of the rx chain) to the AEC and the anti-vox
+	Keyer       *keyer.Store           // optional; messages of the voice keyer
+	Mic         audio.Source           // optional; monitored by the vox while the voice keyer is on air
+	TxMixer     *audio.Mixer           // optional; selector of the tx chain which mixes the voice keyer with the mic
+	Recordings  *recorder.Store        // optional; store for the recordings of the rx / tx audio
+	RecordOpts  []recorder.Option      // optional; settings (e.g. rotation) of the recordings
+	Media       *rtp.Conn              // optional; used for servers with an RTP media path
+	NewEncoder  EncoderFactory         // optional; creates the encoders for the negotiated codecs
 }
 
+// EncoderFactory creates an encoder for the given codec. The encoder must
+// encode the audio in the format which has been offered to the remote
+// audio servers for that codec (see proxy.Codecs).
+type EncoderFactory func(codec string) (audiocodec.Encoder, error)
+
 // NewTrx is the constructor method of a Trx object.
 func NewTrx(opts Options) (*Trx, error) {
 
@@ -72,11 +140,57 @@ func NewTrx(opts Options) (*Trx, error) {
 		toNetwork:   opts.ToNetwork,
 		broker:      opts.Broker,
 		vox:         opts.Vox,
+		compressor:  opts.Compressor,
+		rxEQ:        opts.RxEQ,
+		txEQ:        opts.TxEQ,
+		nr:          opts.NR,
+		notch:       opts.Notch,
+		rxMeter:     opts.RxMeter,
+		txMeter:     opts.TxMeter,
+		analyser:    opts.Analyser,
+		aec:         opts.AEC,
+		keyer:       opts.Keyer,
+		mic:         opts.Mic,
+		txMixer:     opts.TxMixer,
+		mixerGains:  make(map[string]float32),
+		recordings:  opts.Recordings,
+		recOpts:     opts.RecordOpts,
+		media:       opts.Media,
 		servers:     make(map[string]*proxy.AudioServer),
+		encoders:    make(map[string]audiocodec.Encoder),
+		newEncoder:  opts.NewEncoder,
+	}
+
+	if trx.newEncoder == nil {
+		trx.newEncoder = trx.defaultEncoder
+	}
+
+	// keep the configured encoder, so that we can switch back to it
+	if enc := trx.toNetwork.Encoder(); enc != nil {
+		trx.encoders[enc.Name()] = enc
 	}
 
 	trx.toNetwork.SetToWireCb(trx.toWireCb)
 
+	// the audio played on the speaker is the reference for the
+	// echo canceller and the anti-vox in the tx chain
+	if opts.SpeakerTap != nil {
+		opts.SpeakerTap.SetRefCb(func(msg audio.Msg) {
+			if opts.AEC != nil {
+				opts.AEC.WriteReference(msg)
+			}
+			if opts.Vox != nil {
+				opts.Vox.WriteAntiVox(msg)
+			}
+		})
+	}
+
+	if trx.media != nil {
+		trx.media.SetHandler(trx.fromMediaCb)
+	}
+
+	go trx.feedbackLoop()
+
 	return trx, nil
 }
 
@@ -116,6 +230,12 @@ func (x *Trx) onAudioServersChanged() {
 	if x.notifyServerChangeCb != nil {
 		go x.notifyServerChangeCb()
 	}
+	// the user who is transmitting might have changed
+	x.updateRecorderLabels(false)
+	// the latency might have changed
+	if x.autoRxProfile && x.curServer != nil {
+		go x.adjustRxProfile()
+	}
 }
 
 // Server returns a particular AudioServer. If no
@@ -153,6 +273,13 @@ func (x *Trx) RemoveServer(asName string) error {
 
 	delete(x.servers, asName)
 
+	// the server is gone, so there is no need to close the session
+	if as == x.mediaServer {
+		x.mediaServer = nil
+		x.mediaSession = 0
+		x.media.SetRemote("")
+	}
+
 	if as.Name() == x.curServer.Name() && len(x.servers) > 0 {
 		for _, svr := range x.servers {
 			go x.SelectServer(svr.Name()) // must be async to avoid deadlock
@@ -184,24 +311,296 @@ func (x *Trx) SelectServer(name string) error {
 		return fmt.Errorf("unknown audio server: %v", name)
 	}
 
+	x.curServer = newSvr
+	x.updateRecorderLabels(true)
+
+	if err := x.setEncoder(newSvr.Codec()); err != nil {
+		return fmt.Errorf("SelectServer: %v", err)
+	}
+
+	if err := newSvr.SelectRxProfile(x.desiredRxProfile(newSvr)); err != nil {
+		log.Println(err)
+		newSvr.SelectRxProfile("") // fall back to the default stream
+	}
+
+	if err := x.subscribeRx(newSvr.RxAddress()); err != nil {
+		return fmt.Errorf("SelectServer: %v", err)
+	}
+
+	if err := x.subscribeFeedback(newSvr.TxFeedbackAddress()); err != nil {
+		return fmt.Errorf("SelectServer: %v", err)
+	}
+
+	return nil
+}
+
+// subscribeRx subscribes to the audio stream published on the given
+// address. If the selected audio server offers an RTP media path, the
+// audio is received through a media session instead of the broker.
+// This method is not safe for concurrent access.
+func (x *Trx) subscribeRx(address string) error {
+
+	// each rx profile is encoded on the server with its own sequence
+	// numbers, so the jitter buffer has to start over
+	if address != x.rxStream {
+		x.fromNetwork.Reset()
+		x.rxStream = address
+	}
+
+	if x.media != nil && len(x.curServer.MediaAddress()) > 0 {
+		err := x.openMediaSession(address)
+		if err == nil {
+			return x.unsubscribeRx()
+		}
+		log.Printf("unable to open media session with %s (%v); falling back to broker\n",
+			x.curServer.Name(), err)
+	}
+
+	if err := x.closeMediaSession(); err != nil {
+		log.Println(err)
+	}
+
 	if x.rxAudioSub != nil {
+		if address == x.rxAddress {
+			return nil
+		}
 		if err := x.rxAudioSub.Unsubscribe(); err != nil {
-			return fmt.Errorf("select server unsubscribe: %v", err)
+			return fmt.Errorf("unsubscribe: %v", err)
 		}
+		x.rxAudioSub = nil
 	}
 
-	x.curServer = newSvr
-
-	sub, err := x.broker.Subscribe(newSvr.RxAddress(), x.fromWireCb)
+	sub, err := x.broker.Subscribe(address, x.fromWireCb)
 	if err != nil {
-		return fmt.Errorf("SelectServer subscribe: %v", err)
+		return fmt.Errorf("subscribe: %v", err)
 	}
 
 	x.rxAudioSub = sub
+	x.rxAddress = address
+
+	return nil
+}
+
+// unsubscribeRx removes the subscription of the audio stream from the
+// broker. This method is not safe for concurrent access.
+func (x *Trx) unsubscribeRx() error {
+
+	if x.rxAudioSub == nil {
+		return nil
+	}
+
+	if err := x.rxAudioSub.Unsubscribe(); err != nil {
+		return fmt.Errorf("unsubscribe: %v", err)
+	}
+	x.rxAudioSub = nil
+	x.rxAddress = ""
+
+	return nil
+}
+
+// openMediaSession opens (or updates) a session on the RTP media path
+// of the selected audio server for the audio stream published on the
+// given address. This method is not safe for concurrent access.
+func (x *Trx) openMediaSession(address string) error {
+
+	if x.mediaServer != x.curServer {
+		if err := x.closeMediaSession(); err != nil {
+			log.Println(err)
+		}
+	}
+
+	session, mediaAddress, err := x.curServer.OpenMediaSession(x.mediaSession,
+		address, x.toNetwork.UserID())
+	if err != nil {
+		return err
+	}
+
+	x.media.SetSSRC(session)
+	if err := x.media.SetRemote(mediaAddress); err != nil {
+		return err
+	}
+
+	// let the server learn our address right away
+	if err := x.media.Write(nil); err != nil {
+		return err
+	}
+
+	if x.mediaSession != session {
+		log.Printf("exchanging audio with %s through RTP media path %s\n",
+			x.curServer.Name(), mediaAddress)
+	}
+
+	x.mediaServer = x.curServer
+	x.mediaSession = session
 
 	return nil
 }
 
+// closeMediaSession closes the session on the RTP media path (if any).
+// This method is not safe for concurrent access.
+func (x *Trx) closeMediaSession() error {
+
+	if x.mediaServer == nil {
+		return nil
+	}
+
+	svr, session := x.mediaServer, x.mediaSession
+	x.mediaServer = nil
+	x.mediaSession = 0
+	x.media.SetRemote("")
+
+	return svr.CloseMediaSession(session)
+}
+
+// SelectRxProfile selects the profile (e.g. high, medium, low) with which
+// the audio from the remote audio server will be received. An empty string
+// selects the server's default audio stream. The automatic profile
+// selection will be disabled.
+func (x *Trx) SelectRxProfile(name string) error {
+	x.Lock()
+	defer x.Unlock()
+
+	x.rxProfile = name
+	x.autoRxProfile = false
+
+	if x.curServer == nil {
+		return nil
+	}
+
+	if err := x.curServer.SelectRxProfile(name); err != nil {
+		return err
+	}
+
+	return x.subscribeRx(x.curServer.RxAddress())
+}
+
+// RxProfile returns the name of the rx profile of the currently selected
+// audio server. An empty string is returned if the default audio stream
+// is received.
+func (x *Trx) RxProfile() string {
+	x.RLock()
+	defer x.RUnlock()
+
+	if x.curServer == nil {
+		return ""
+	}
+	return x.curServer.RxProfile()
+}
+
+// SetAutoRxProfile enables / disables the automatic selection of the
+// rx profile. If enabled, the profile is chosen based on the latency to
+// the remote audio server.
+func (x *Trx) SetAutoRxProfile(enabled bool) {
+	x.Lock()
+	x.autoRxProfile = enabled
+	x.Unlock()
+
+	if enabled {
+		x.adjustRxProfile()
+	}
+}
+
+// AutoRxProfile returns true if the rx profile is selected automatically.
+func (x *Trx) AutoRxProfile() bool {
+	x.RLock()
+	defer x.RUnlock()
+	return x.autoRxProfile
+}
+
+// adjustRxProfile selects the rx profile of the current audio server
+// based on its latency.
+func (x *Trx) adjustRxProfile() {
+	x.Lock()
+	defer x.Unlock()
+
+	if !x.autoRxProfile || x.curServer == nil {
+		return
+	}
+
+	name := x.desiredRxProfile(x.curServer)
+	if name == x.curServer.RxProfile() {
+		return
+	}
+
+	if err := x.curServer.SelectRxProfile(name); err != nil {
+		log.Println(err)
+		return
+	}
+
+	if err := x.subscribeRx(x.curServer.RxAddress()); err != nil {
+		log.Println(err)
+		return
+	}
+
+	log.Printf("receiving audio from %s with rx profile '%s' (latency %dms)\n",
+		x.curServer.Name(), name, x.curServer.Latency())
+}
+
+// desiredRxProfile returns the name of the rx profile which should be
+// used for the given audio server. This method is not safe for
+// concurrent access.
+func (x *Trx) desiredRxProfile(svr *proxy.AudioServer) string {
+	if x.autoRxProfile {
+		return rxProfileForLatency(svr.RxProfiles(), svr.Latency())
+	}
+	return x.rxProfile
+}
+
+// rxProfileForLatency picks the rx profile for a given latency (in ms).
+// The higher the latency, the lower the bitrate of the selected profile.
+func rxProfileForLatency(profiles []proxy.RxProfile, latency int) string {
+	if len(profiles) == 0 {
+		return ""
+	}
+
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Bitrate > profiles[j].Bitrate
+	})
+
+	switch {
+	case latency < 100:
+		return profiles[0].Name
+	case latency < 300:
+		return profiles[len(profiles)/2].Name
+	}
+	return profiles[len(profiles)-1].Name
+}
+
+// setEncoder makes sure that the audio sent to the remote audio server
+// is encoded with the negotiated codec. Encoders are created on demand
+// by the EncoderFactory. This method is not safe for concurrent access.
+func (x *Trx) setEncoder(codec string) error {
+	if len(codec) == 0 {
+		return nil
+	}
+
+	if cur := x.toNetwork.Encoder(); cur != nil && cur.Name() == codec {
+		return nil
+	}
+
+	enc, ok := x.encoders[codec]
+	if !ok {
+		var err error
+		enc, err = x.newEncoder(codec)
+		if err != nil {
+			return err
+		}
+		x.encoders[codec] = enc
+	}
+
+	log.Printf("sending audio with %s codec\n", codec)
+
+	return x.toNetwork.SetEncoder(enc)
+}
+
+// defaultEncoder creates an encoder with the default settings of the
+// codec registry.
+func (x *Trx) defaultEncoder(codec string) (audiocodec.Encoder, error) {
+	return audiocodec.NewEncoder(codec, audiocodec.Options{
+		Channels: x.toNetwork.Channels(),
+	})
+}
+
 // SelectedServer returns the name of the currently selected Audio Server.
 func (x *Trx) SelectedServer() string {
 	x.RLock()
@@ -273,6 +672,11 @@ func (x *Trx) SetVOX(voxState bool) error {
 		return nil
 	}
 
+	// speaking into the mic aborts the voice keyer (repeat interval)
+	if voxState {
+		x.stopKeyer(true)
+	}
+
 	x.voxActive = voxState
 
 	// already sending audio since PTT is active
@@ -335,13 +739,27 @@ func (x *Trx) VOXHoldTime() time.Duration {
 	return x.vox.Holdtime()
 }
 
-//SetVOXHoldTime sets the vox hold time
+// SetVOXHoldTime sets the vox hold time
 func (x *Trx) SetVOXHoldTime(t time.Duration) {
 	x.Lock()
 	defer x.Unlock()
 	x.vox.SetHoldTime(t)
 }
 
+// AntiVOXGain returns the gain of the anti-vox
+func (x *Trx) AntiVOXGain() float32 {
+	x.Lock()
+	defer x.Unlock()
+	return x.vox.AntiVoxGain()
+}
+
+// SetAntiVOXGain sets the gain of the anti-vox
+func (x *Trx) SetAntiVOXGain(g float32) {
+	x.Lock()
+	defer x.Unlock()
+	x.vox.SetAntiVoxGain(g)
+}
+
 // SetPTT (Push To Talk) turns on/off the audio stream sent to the remote
 // audio server. In case VOX is active, the application will continue
 // streaming audio to the server.
@@ -355,6 +773,11 @@ func (x *Trx) SetPTT(pttState bool) error {
 
 	x.pttActive = pttState
 
+	// manual PTT aborts the voice keyer
+	if x.pttActive {
+		x.stopKeyer(true)
+	}
+
 	// already sending audio since VOX is active
 	if x.pttActive && x.voxActive {
 		return nil
@@ -369,6 +792,11 @@ func (x *Trx) SetPTT(pttState bool) error {
 		return nil
 	}
 
+	// don't disable the audio stream since the voice keyer is playing
+	if x.keyerState.OnAir {
+		return nil
+	}
+
 	// !x.pttEnabled
 	return x.setTxState(false)
 }
@@ -382,6 +810,13 @@ func (x *Trx) setTxState(on bool) error {
 		return nil
 	}
 
+	// only the audio sent to the server is recorded
+	if x.txRecorder != nil && x.txRecorder.Format() != recorder.FormatOpus {
+		if err := x.tx.Sinks.EnableSink(recorderSinkName, on); err != nil {
+			log.Println(err)
+		}
+	}
+
 	if on {
 		return x.tx.Enable(true)
 	}
@@ -407,6 +842,28 @@ func (x *Trx) SetRxState(on bool) error {
 	return err
 }
 
+// SelectTxSource selects the source of the tx chain (e.g. the local
+// microphone or a browser) from which the audio is sent to the remote
+// audio server.
+func (x *Trx) SelectTxSource(name string) error {
+	x.Lock()
+	defer x.Unlock()
+	if err := x.tx.Sources.SetSource(name); err != nil {
+		return err
+	}
+	if name != keyerSourceName {
+		x.stopKeyer(false)
+	}
+	return nil
+}
+
+// DefaultTxSource returns the name of the default source of the tx chain.
+func (x *Trx) DefaultTxSource() string {
+	x.RLock()
+	defer x.RUnlock()
+	return x.tx.DefaultSource()
+}
+
 // TxState returns a boolean if audio is currently sent to the remote
 // audio server, or not.
 func (x *Trx) TxState() (bool, error) {
@@ -457,9 +914,37 @@ func (x *Trx) fromWireCb(pub broker.Event) error {
 	if x.fromNetwork == nil {
 		return nil
 	}
+	x.recordFrame(&x.rxRecorder, pub.Message().Body)
 	return x.fromNetwork.Enqueue(pub.Message().Body)
 }
 
+// fromMediaCb is a callback that is executed when a packet is received
+// through the RTP media path. Packets which don't originate from the
+// audio server of the media session are discarded.
+func (x *Trx) fromMediaCb(from *net.UDPAddr, h rtp.Header, payload []byte) {
+
+	// empty packets are only sent to keep the NAT binding alive
+	if len(payload) == 0 {
+		return
+	}
+
+	remote := x.media.Remote()
+	if remote == nil || !remote.IP.Equal(from.IP) || remote.Port != from.Port {
+		return
+	}
+
+	x.RLock()
+	defer x.RUnlock()
+
+	if x.fromNetwork == nil {
+		return
+	}
+	x.recordFrame(&x.rxRecorder, payload)
+	if err := x.fromNetwork.Enqueue(payload); err != nil {
+		log.Println(err)
+	}
+}
+
 // toWireCb is a callback that is executed when audio is ready to
 // be sent to the audio server. Typically this callback is called from
 // an audio sync (e.g. pbWriter).
@@ -475,6 +960,15 @@ func (x *Trx) toWireCb(data []byte) {
 		return
 	}
 
+	x.recordFrame(&x.txRecorder, data)
+
+	if x.media != nil && x.media.Remote() != nil {
+		if err := x.media.Write(data); err != nil {
+			log.Println("toWireCb:", err)
+		}
+		return
+	}
+
 	err := x.broker.Publish(x.curServer.TxAddress(), msg)
 	if err != nil {
 		log.Fatal("toWireCb:", err)
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 )
 
 func (web *WebServer) webSocketHdlr(w http.ResponseWriter, req *http.Request) {
@@ -20,7 +22,7 @@ func (web *WebServer) webSocketHdlr(w http.ResponseWriter, req *http.Request) {
 
 	wsClient := &wsClient{
 		ws:           conn,
-		send:         make(chan []byte),
+		send:         make(chan []byte, wsBufferSize),
 		removeClient: web.removeWsClient,
 	}
 
@@ -30,6 +32,150 @@ func (web *WebServer) webSocketHdlr(w http.ResponseWriter, req *http.Request) {
 	web.addWsClient <- wsClient
 }
 
+// spectrumWebSocketHdlr streams the spectra of the rx audio (see
+// analyser.Spectrum) as JSON messages to the websocket client.
+func (web *WebServer) spectrumWebSocketHdlr(w http.ResponseWriter, req *http.Request) {
+
+	if _, err := web.trx.AnalyserSize(); err != nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.Printf("unable to open spectrum ws for %v\n", req.RemoteAddr)
+		return
+	}
+
+	wsClient := &wsClient{
+		ws:           conn,
+		send:         make(chan []byte, spectrumBufferSize),
+		removeClient: web.removeSpectrumClient,
+	}
+
+	go wsClient.write()
+	go wsClient.read()
+
+	web.addSpectrumClient <- wsClient
+}
+
+// audioTalkMsg is a text message which can be sent by a client on the
+// /ws/audio endpoint to indicate that it has stopped talking.
+type audioTalkMsg struct {
+	Talk *bool `json:"talk"`
+}
+
+// audioWebSocketHdlr streams the rx audio to the websocket client. Binary
+// messages received from the client (e.g. the microphone of a browser)
+// are fed into the tx chain. While the client is talking, its audio
+// replaces the local microphone. Only one client can talk at a time; the
+// audio of the other clients is dropped until the talking client stops.
+func (web *WebServer) audioWebSocketHdlr(w http.ResponseWriter, req *http.Request) {
+
+	if web.options.AudioSink == nil && web.options.AudioSource == nil {
+		http.NotFound(w, req)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		log.Printf("unable to open audio ws for %v\n", req.RemoteAddr)
+		return
+	}
+
+	log.Println("WebSocket audio client connected from", conn.RemoteAddr())
+
+	if web.options.AudioSink != nil {
+		if err := web.options.AudioSink.AddConn(conn); err != nil {
+			log.Println(err)
+			conn.Close()
+			return
+		}
+	}
+
+	defer func() {
+		if web.options.AudioSink != nil {
+			web.options.AudioSink.RemoveConn(conn)
+		}
+		web.stopTalking(conn)
+		conn.Close()
+		log.Println("WebSocket audio client disconnected", conn.RemoteAddr())
+	}()
+
+	rejected := false // log only once that another client is talking
+
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		switch msgType {
+		case websocket.BinaryMessage:
+			if web.options.AudioSource == nil {
+				continue
+			}
+			if !web.startTalking(conn) {
+				if !rejected {
+					log.Println("dropping audio from", conn.RemoteAddr(),
+						"while another client is talking")
+					rejected = true
+				}
+				continue
+			}
+			rejected = false
+			if err := web.options.AudioSource.Enqueue(data); err != nil {
+				log.Println(err)
+			}
+
+		case websocket.TextMessage:
+			var msg audioTalkMsg
+			if err := json.Unmarshal(data, &msg); err != nil {
+				continue
+			}
+			if msg.Talk != nil && !*msg.Talk {
+				web.stopTalking(conn)
+			}
+		}
+	}
+}
+
+// startTalking selects the audio of the client as tx source, unless
+// another client is already talking. It returns true if the client
+// is the talking client.
+func (web *WebServer) startTalking(conn *websocket.Conn) bool {
+	web.Lock()
+	defer web.Unlock()
+
+	if web.talker != nil {
+		return web.talker == conn
+	}
+
+	if err := web.trx.SelectTxSource(web.options.TxSource); err != nil {
+		log.Println(err)
+		return false
+	}
+	web.talker = conn
+
+	return true
+}
+
+// stopTalking restores the default tx source if the client is the
+// talking client.
+func (web *WebServer) stopTalking(conn *websocket.Conn) {
+	web.Lock()
+	defer web.Unlock()
+
+	if web.talker != conn {
+		return
+	}
+	web.talker = nil
+
+	if err := web.trx.SelectTxSource(web.trx.DefaultTxSource()); err != nil {
+		log.Println(err)
+	}
+}
+
 func (web *WebServer) txStateHdlr(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
@@ -88,12 +234,14 @@ func (web *WebServer) txVoxStateHdlr(w http.ResponseWriter, req *http.Request) {
 		voxActive := web.trx.VOX()
 		voxThreshold := web.trx.VOXThreshold()
 		voxHoldtime := web.trx.VOXHoldTime()
+		antiVoxGain := web.trx.AntiVOXGain()
 
 		voxCtlMsg := &AudioControlVox{
 			VoxActive:    &voxActive,
 			VoxEnabled:   &voxEnabled,
 			VoxThreshold: &voxThreshold,
 			VoxHoldtime:  &voxHoldtime,
+			AntiVoxGain:  &antiVoxGain,
 		}
 		if err := json.NewEncoder(w).Encode(voxCtlMsg); err != nil {
 			log.Println(err)
@@ -121,12 +269,620 @@ func (web *WebServer) txVoxStateHdlr(w http.ResponseWriter, req *http.Request) {
 		if voxCtlMsg.VoxThreshold != nil {
 			web.trx.SetVOXThreshold(*voxCtlMsg.VoxThreshold)
 		}
+		if voxCtlMsg.AntiVoxGain != nil {
+			web.trx.SetAntiVOXGain(*voxCtlMsg.AntiVoxGain)
+		}
+		web.updateWsClients()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) txAECHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	enabled, err := web.trx.AECEnabled()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find echo canceller node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		aecCtlMsg := &AudioControlAEC{
+			Enabled: &enabled,
+		}
+		if err := json.NewEncoder(w).Encode(aecCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlAEC msg"))
+		}
+
+	case "PUT":
+		var aecCtlMsg AudioControlAEC
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&aecCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if aecCtlMsg.Enabled != nil {
+			web.trx.SetAECEnabled(*aecCtlMsg.Enabled)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) txMixerHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	gains, err := web.trx.MixerGains()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find tx mixer"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		mixerCtlMsg := &AudioControlMixer{
+			Gains: gains,
+		}
+		if err := json.NewEncoder(w).Encode(mixerCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlMixer msg"))
+		}
+
+	case "PUT":
+		var mixerCtlMsg AudioControlMixer
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&mixerCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		for name, gain := range mixerCtlMsg.Gains {
+			if err := web.trx.SetMixerGain(name, gain); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("400 - " + err.Error()))
+				return
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) keyerHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	state, err := web.trx.KeyerState()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find voice keyer"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		msgs, err := web.trx.KeyerMessages()
+		if err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to read voice keyer messages"))
+			return
+		}
+		keyerCtlMsg := &AudioControlKeyer{
+			Message:  &state.Message,
+			Repeat:   &state.Repeat,
+			Interval: &state.Interval,
+			OnAir:    &state.OnAir,
+			Messages: msgs,
+		}
+		if err := json.NewEncoder(w).Encode(keyerCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlKeyer msg"))
+		}
+
+	case "PUT":
+		var keyerCtlMsg AudioControlKeyer
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&keyerCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if keyerCtlMsg.Message == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - message missing"))
+			return
+		}
+		if len(*keyerCtlMsg.Message) == 0 {
+			web.trx.StopKeyer()
+			web.updateWsClients()
+			return
+		}
+		repeat := false
+		if keyerCtlMsg.Repeat != nil {
+			repeat = *keyerCtlMsg.Repeat
+		}
+		var interval time.Duration
+		if keyerCtlMsg.Interval != nil {
+			interval = *keyerCtlMsg.Interval
+		}
+		if interval < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - interval must not be negative"))
+			return
+		}
+		if err := web.trx.PlayKeyer(*keyerCtlMsg.Message, repeat, interval); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - " + err.Error()))
+			return
+		}
 		web.updateWsClients()
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 }
 
+func (web *WebServer) keyerMessageHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	vars := mux.Vars(req)
+	name := vars["message"]
+
+	switch req.Method {
+	case "PUT":
+		msg, err := web.trx.SaveKeyerMessage(name, req.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - " + err.Error()))
+			return
+		}
+		if err := json.NewEncoder(w).Encode(msg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode voice keyer message"))
+		}
+
+	case "DELETE":
+		if err := web.trx.DeleteKeyerMessage(name); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - " + err.Error()))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) recordingHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	switch req.Method {
+	case "GET":
+		rx := web.trx.RxRecording()
+		tx := web.trx.TxRecording()
+		recCtlMsg := &AudioControlRecording{
+			Rx: &rx,
+			Tx: &tx,
+		}
+		if err := json.NewEncoder(w).Encode(recCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlRecording msg"))
+		}
+
+	case "PUT":
+		var recCtlMsg AudioControlRecording
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&recCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if recCtlMsg.Rx != nil {
+			if err := web.trx.SetRxRecording(*recCtlMsg.Rx); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("500 - " + err.Error()))
+				return
+			}
+		}
+		if recCtlMsg.Tx != nil {
+			if err := web.trx.SetTxRecording(*recCtlMsg.Tx); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("500 - " + err.Error()))
+				return
+			}
+		}
+		web.updateWsClients()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) recordingsHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	recs, err := web.trx.Recordings()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to read the recordings"))
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(recs); err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to encode the recordings"))
+	}
+}
+
+func (web *WebServer) recordingFileHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+
+	vars := mux.Vars(req)
+	name := vars["recording"]
+
+	switch req.Method {
+	case "GET":
+		path, err := web.trx.RecordingPath(name)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 - " + err.Error()))
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		http.ServeFile(w, req, path)
+
+	case "DELETE":
+		if err := web.trx.DeleteRecording(name); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - " + err.Error()))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) txCompressorHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	enabled, err := web.trx.CompressorEnabled()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find compressor node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		threshold, _ := web.trx.CompressorThreshold()
+		ratio, _ := web.trx.CompressorRatio()
+		makeupGain, _ := web.trx.CompressorMakeupGain()
+		limit, _ := web.trx.CompressorLimit()
+		gainReduction, _ := web.trx.CompressorGainReduction()
+
+		compCtlMsg := &AudioControlCompressor{
+			Enabled:       &enabled,
+			Threshold:     &threshold,
+			Ratio:         &ratio,
+			MakeupGain:    &makeupGain,
+			Limit:         &limit,
+			GainReduction: &gainReduction,
+		}
+		if err := json.NewEncoder(w).Encode(compCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlCompressor msg"))
+		}
+
+	case "PUT":
+		var compCtlMsg AudioControlCompressor
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&compCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if err := checkCompressor(compCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - " + err.Error()))
+			return
+		}
+		if err := web.setCompressor(compCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to set compressor"))
+			return
+		}
+		web.updateWsClients()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// checkCompressor returns an error if a setting of the compressor is out
+// of range. The settings are checked before any of them is applied.
+func checkCompressor(msg AudioControlCompressor) error {
+	ranges := []struct {
+		name     string
+		value    *float32
+		min, max float32
+	}{
+		{"threshold", msg.Threshold, -60, 0},
+		{"ratio", msg.Ratio, 1, 20},
+		{"makeup_gain", msg.MakeupGain, 0, 30},
+		{"limit", msg.Limit, -30, 0},
+	}
+	for _, r := range ranges {
+		if r.value != nil && !(*r.value >= r.min && *r.value <= r.max) {
+			return fmt.Errorf("%s must be within [%v...%v]", r.name, r.min, r.max)
+		}
+	}
+	return nil
+}
+
+// setCompressor applies the settings of the compressor contained in msg.
+func (web *WebServer) setCompressor(msg AudioControlCompressor) error {
+	if msg.Enabled != nil {
+		if err := web.trx.SetCompressorEnabled(*msg.Enabled); err != nil {
+			return err
+		}
+	}
+	if msg.Threshold != nil {
+		if err := web.trx.SetCompressorThreshold(*msg.Threshold); err != nil {
+			return err
+		}
+	}
+	if msg.Ratio != nil {
+		if err := web.trx.SetCompressorRatio(*msg.Ratio); err != nil {
+			return err
+		}
+	}
+	if msg.MakeupGain != nil {
+		if err := web.trx.SetCompressorMakeupGain(*msg.MakeupGain); err != nil {
+			return err
+		}
+	}
+	if msg.Limit != nil {
+		if err := web.trx.SetCompressorLimit(*msg.Limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (web *WebServer) rxEQHdlr(w http.ResponseWriter, req *http.Request) {
+	web.eqHdlr(w, req, false)
+}
+
+func (web *WebServer) txEQHdlr(w http.ResponseWriter, req *http.Request) {
+	web.eqHdlr(w, req, true)
+}
+
+// eqHdlr gets / sets the equalizer of the tx (or rx) chain
+func (web *WebServer) eqHdlr(w http.ResponseWriter, req *http.Request, tx bool) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	enabled, err := web.trx.EQEnabled(tx)
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find equalizer node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		sections, _ := web.trx.EQSections(tx)
+		eqCtlMsg := &AudioControlEQ{
+			Enabled:  &enabled,
+			Sections: &sections,
+		}
+		if err := json.NewEncoder(w).Encode(eqCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlEQ msg"))
+		}
+
+	case "PUT":
+		var eqCtlMsg AudioControlEQ
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&eqCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if eqCtlMsg.Sections != nil {
+			if err := web.trx.SetEQSections(tx, *eqCtlMsg.Sections); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("400 - " + err.Error()))
+				return
+			}
+		}
+		if eqCtlMsg.Enabled != nil {
+			web.trx.SetEQEnabled(tx, *eqCtlMsg.Enabled)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) rxNRHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	enabled, err := web.trx.NREnabled()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find noise reduction node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		strength, _ := web.trx.NRStrength()
+		nrCtlMsg := &AudioControlNR{
+			Enabled:  &enabled,
+			Strength: &strength,
+		}
+		if err := json.NewEncoder(w).Encode(nrCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlNR msg"))
+		}
+
+	case "PUT":
+		var nrCtlMsg AudioControlNR
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&nrCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if nrCtlMsg.Enabled != nil {
+			web.trx.SetNREnabled(*nrCtlMsg.Enabled)
+		}
+		if nrCtlMsg.Strength != nil {
+			web.trx.SetNRStrength(*nrCtlMsg.Strength)
+		}
+		web.updateWsClients()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) rxNotchHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	auto, err := web.trx.NotchAuto()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find notch filter node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		manual, _ := web.trx.NotchManual()
+		frequency, _ := web.trx.NotchFrequency()
+		notchCtlMsg := &AudioControlNotch{
+			Auto:      &auto,
+			Manual:    &manual,
+			Frequency: &frequency,
+		}
+		if err := json.NewEncoder(w).Encode(notchCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlNotch msg"))
+		}
+
+	case "PUT":
+		var notchCtlMsg AudioControlNotch
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&notchCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if notchCtlMsg.Frequency != nil {
+			web.trx.SetNotchFrequency(*notchCtlMsg.Frequency)
+		}
+		if notchCtlMsg.Auto != nil {
+			web.trx.SetNotchAuto(*notchCtlMsg.Auto)
+		}
+		if notchCtlMsg.Manual != nil {
+			web.trx.SetNotchManual(*notchCtlMsg.Manual)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (web *WebServer) rxAnalyserHdlr(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+
+	size, err := web.trx.AnalyserSize()
+	if err != nil {
+		log.Println(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("500 - unable to find spectrum analyser node"))
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		rate, _ := web.trx.AnalyserRate()
+		analyserCtlMsg := &AudioControlAnalyser{
+			Size: &size,
+			Rate: &rate,
+		}
+		if err := json.NewEncoder(w).Encode(analyserCtlMsg); err != nil {
+			log.Println(err)
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte("500 - unable to encode AudioControlAnalyser msg"))
+		}
+
+	case "PUT":
+		var analyserCtlMsg AudioControlAnalyser
+		dec := json.NewDecoder(req.Body)
+
+		if err := dec.Decode(&analyserCtlMsg); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("400 - invalid JSON"))
+			return
+		}
+		if analyserCtlMsg.Size != nil {
+			if err := web.trx.SetAnalyserSize(*analyserCtlMsg.Size); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("400 - " + err.Error()))
+				return
+			}
+		}
+		if analyserCtlMsg.Rate != nil {
+			if err := web.trx.SetAnalyserRate(*analyserCtlMsg.Rate); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("400 - " + err.Error()))
+				return
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
 func (web *WebServer) rxVolumeHdlr(w http.ResponseWriter, req *http.Request) {
 	defer req.Body.Close()
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
@@ -11,12 +11,21 @@ var vm = new Vue({
         connectionState: false,
         blockRxVolumeUpdate: false,
         blockTxVolumeUpdate: false,
+        blockNrStrengthUpdate: false,
+        nrEnabled: false,
+        rxRecording: false,
+        txRecording: false,
+        levels: {}, // audio levels, periodically sent by the client
         audioServers: {},
         wsConnected: false,
         hideWsConnectionMsg: false,
     },
     components: {
         'audioservers': AudioServers,
+        'browseraudio': BrowserAudio,
+        'levelmeter': LevelMeter,
+        'spectrum': Spectrum,
+        'recordings': Recordings,
     },
     mounted: function () {
         this.openWebsocket();
@@ -24,7 +33,7 @@ var vm = new Vue({
     methods: {
         openWebsocket: function () {
             var self = this;
-            this.ws = new ReconnectingWebSocket('ws://' + window.location.host + '/ws');
+            this.ws = new ReconnectingWebSocket(wsScheme() + window.location.host + '/ws');
             this.ws.addEventListener('message', this.processWsMsg);
             this.ws.addEventListener('open', function () {
                 self.wsConnected = true
@@ -41,6 +50,12 @@ var vm = new Vue({
             var msg = JSON.parse(rawMsg.data);
             // console.log(msg);
 
+            // the levels are sent separately from the application state
+            if (msg.levels !== undefined) {
+                this.levels = msg.levels;
+                return
+            }
+
             if (msg.tx_on !== null) {
                 this.txOn = msg.tx_on;
             }
@@ -62,6 +77,27 @@ var vm = new Vue({
                 }
             }
 
+            if (msg.nr_enabled !== null) {
+                this.nrEnabled = msg.nr_enabled;
+            }
+
+            if (msg.nr_strength !== null) {
+                if (!this.blockNrStrengthUpdate) {
+                    var strength = Math.round(msg.nr_strength * 100);
+                    if (nrStrengthSlider.noUiSlider.get() != strength) {
+                        nrStrengthSlider.noUiSlider.set(strength);
+                    }
+                }
+            }
+
+            if (msg.rx_recording !== null) {
+                this.rxRecording = msg.rx_recording;
+            }
+
+            if (msg.tx_recording !== null) {
+                this.txRecording = msg.tx_recording;
+            }
+
             if (msg.connected !== null) {
                 this.connectionState = msg.connected;
             }
@@ -103,9 +139,12 @@ var vm = new Vue({
                 }));
         },
         sendTxOn: function () {
+            this.setTxState(!this.txOn);
+        },
+        setTxState: function (txState) {
             this.$http.put("/api/v1.0/tx/state",
                 JSON.stringify({
-                    on: !this.txOn,
+                    on: txState,
                 }));
         },
         sendRxVolume: function (value) {
@@ -120,6 +159,18 @@ var vm = new Vue({
                     volume: Math.round(value)
                 }));
         },
+        sendNrEnabled: function () {
+            this.$http.put("/api/v1.0/rx/nr",
+                JSON.stringify({
+                    enabled: !this.nrEnabled
+                }));
+        },
+        sendNrStrength: function (value) {
+            this.$http.put("/api/v1.0/rx/nr",
+                JSON.stringify({
+                    strength: Math.round(value) / 100
+                }));
+        },
         updateAudioServers: function (aServers) {
 
             // copy this into the variable self, as this will change
@@ -153,6 +204,12 @@ var vm = new Vue({
                 }
             })
         },
+        channelLabel: function (name, channels, ch) {
+            if (channels < 2) {
+                return name;
+            }
+            return name + (ch == 0 ? " L" : " R");
+        },
         sortByKey: function(array, key) {
             return array.sort(function(a, b) {
                 var x = a[key]; var y = b[key];
@@ -174,6 +231,7 @@ var vm = new Vue({
 
 var rxVolumeSlider = document.getElementById('rxVolumeSlider');
 var txVolumeSlider = document.getElementById('txVolumeSlider');
+var nrStrengthSlider = document.getElementById('nrStrengthSlider');
 
 noUiSlider.create(rxVolumeSlider, {
     start: [1],
@@ -203,6 +261,20 @@ noUiSlider.create(txVolumeSlider, {
     }
 });
 
+noUiSlider.create(nrStrengthSlider, {
+    start: [50],
+    connect: [true, false],
+    range: {
+        'min': 0,
+        'max': 100,
+    },
+    pips: { // Show a scale with the slider
+        mode: 'steps',
+        stepped: true,
+        density: 5
+    }
+});
+
 // rxVolumeSlider
 // block the Volume slider to be updated through websocket while we
 // modify the slider
@@ -243,4 +315,17 @@ $(document).ready(function () {
     txVolumeSlider.noUiSlider.on('end', function (values, handle) {
         vm.blockTxVolumeUpdate = false;
     });
-});
\ No newline at end of file
+});
+
+// nrStrengthSlider
+$(document).ready(function () {
+    nrStrengthSlider.noUiSlider.on('start', function (values, handle) {
+        vm.blockNrStrengthUpdate = true;
+    });
+    nrStrengthSlider.noUiSlider.on('change', function (values, handle) {
+        vm.sendNrStrength(Number(values[handle]));
+    });
+    nrStrengthSlider.noUiSlider.on('end', function (values, handle) {
+        vm.blockNrStrengthUpdate = false;
+    });
+});
@@ -0,0 +1,180 @@
+// wsScheme returns the scheme of the websockets; pages loaded via https
+// must use secure websockets.
+function wsScheme() {
+    return window.location.protocol === 'https:' ? 'wss://' : 'ws://';
+}
+
+var BrowserAudio = {
+    template: `
+    <div class="col-lg-4 col-md-4 col-sm-6">
+        <div class="panel panel-primary">
+            <div class="panel-heading">Browser Audio</div>
+            <div class="panel-body">
+                <div class="list-group">
+                    <div class="list-group-item">
+                        <button class="btn btn-default btn-raised" v-bind:class="{'btn-success': listening}" @click="toggleListen"><i class="fa fa-headphones" aria-hidden="true"></i> Listen</button>
+                        <button class="btn btn-default btn-raised" v-bind:class="{'btn-danger': talking}" @click="toggleTalk"><i class="fa fa-microphone" aria-hidden="true"></i> Talk</button>
+                    </div>
+                    <div class="list-group-item text-danger" v-if="error">{{ error }}</div>
+                </div>
+            </div>
+        </div>
+    </div>
+    `,
+    data: function () {
+        return {
+            ws: null, // websocket carrying the audio
+            ctx: null, // AudioContext
+            format: null, // format of the received audio
+            nextTime: 0, // playout time of the next audio buffer
+            listening: false,
+            talking: false,
+            mic: null, // MediaStream of the microphone
+            micNode: null,
+            processor: null,
+            error: "", // shown if the microphone can't be accessed
+        }
+    },
+    methods: {
+        openAudio: function () {
+            if (this.ws !== null) {
+                return
+            }
+            if (this.ctx === null) {
+                this.ctx = new (window.AudioContext || window.webkitAudioContext)({ sampleRate: 48000 });
+            }
+            this.ctx.resume();
+
+            var self = this;
+            this.ws = new WebSocket(wsScheme() + window.location.host + '/ws/audio');
+            this.ws.binaryType = 'arraybuffer';
+            this.ws.addEventListener('message', this.processAudioMsg);
+            this.ws.addEventListener('close', function () {
+                self.ws = null;
+                self.format = null;
+                self.listening = false;
+                self.stopTalk();
+            });
+        },
+        closeAudio: function () {
+            if (this.listening || this.talking || this.ws === null) {
+                return
+            }
+            this.ws.close();
+        },
+        processAudioMsg: function (msg) {
+            // the first message contains the format of the audio stream
+            if (typeof msg.data === 'string') {
+                this.format = JSON.parse(msg.data);
+                return
+            }
+            if (!this.listening || this.format === null) {
+                return
+            }
+
+            var samples = new Int16Array(msg.data);
+            var channels = this.format.channels;
+            var frames = samples.length / channels;
+            var buf = this.ctx.createBuffer(channels, frames, this.format.samplerate);
+
+            for (var ch = 0; ch < channels; ch++) {
+                var data = buf.getChannelData(ch);
+                for (var i = 0; i < frames; i++) {
+                    data[i] = samples[i * channels + ch] / 32768;
+                }
+            }
+
+            var src = this.ctx.createBufferSource();
+            src.buffer = buf;
+            src.connect(this.ctx.destination);
+
+            // keep a small safety margin to absorb network jitter
+            var now = this.ctx.currentTime;
+            if (this.nextTime < now) {
+                this.nextTime = now + 0.1;
+            }
+            src.start(this.nextTime);
+            this.nextTime += buf.duration;
+        },
+        toggleListen: function () {
+            if (this.listening) {
+                this.listening = false;
+                this.closeAudio();
+                return
+            }
+            this.openAudio();
+            this.listening = true;
+        },
+        toggleTalk: function () {
+            if (this.talking) {
+                this.stopTalk();
+                this.closeAudio();
+                return
+            }
+            this.startTalk();
+        },
+        startTalk: function () {
+            var self = this;
+            this.error = "";
+            // the microphone is only available in a secure context
+            // (https or localhost)
+            if (!navigator.mediaDevices || !navigator.mediaDevices.getUserMedia) {
+                this.error = "The microphone is only accessible if the web interface is served via https.";
+                return
+            }
+            navigator.mediaDevices.getUserMedia({ audio: true, video: false })
+                .then(function (stream) {
+                    self.openAudio();
+                    self.mic = stream;
+                    self.micNode = self.ctx.createMediaStreamSource(stream);
+                    self.processor = self.ctx.createScriptProcessor(1024, 1, 1);
+                    self.processor.onaudioprocess = self.sendMicAudio;
+                    self.micNode.connect(self.processor);
+                    self.processor.connect(self.ctx.destination);
+                    self.talking = true;
+                    self.$emit('set-txstate', true);
+                })
+                .catch(function (err) {
+                    console.log("unable to access microphone:", err);
+                    self.error = "Unable to access the microphone: " + err.message;
+                });
+        },
+        stopTalk: function () {
+            if (!this.talking) {
+                return
+            }
+            this.talking = false;
+            this.$emit('set-txstate', false);
+
+            if (this.processor !== null) {
+                this.processor.disconnect();
+                this.processor = null;
+            }
+            if (this.micNode !== null) {
+                this.micNode.disconnect();
+                this.micNode = null;
+            }
+            if (this.mic !== null) {
+                this.mic.getTracks().forEach(function (track) {
+                    track.stop();
+                });
+                this.mic = null;
+            }
+            if (this.ws !== null && this.ws.readyState === WebSocket.OPEN) {
+                this.ws.send(JSON.stringify({ talk: false }));
+            }
+        },
+        sendMicAudio: function (e) {
+            if (this.ws === null || this.ws.readyState !== WebSocket.OPEN) {
+                return
+            }
+            var input = e.inputBuffer.getChannelData(0);
+            var samples = new Int16Array(input.length);
+            for (var i = 0; i < input.length; i++) {
+                var s = Math.max(-1, Math.min(1, input[i]));
+                samples[i] = s < 0 ? s * 32768 : s * 32767;
+            }
+            this.ws.send(samples.buffer);
+        },
+    },
+}
@@ -0,0 +1,41 @@
+var LevelMeter = {
+    template: `
+                <div class="level-meter">
+                    <span class="level-meter-label">{{label}}</span>
+                    <div class="progress level-meter-bar">
+                        <div class="progress-bar" v-bind:class="{'progress-bar-danger': clipping, 'progress-bar-success': !clipping}" v-bind:style="{width: rmsPosition + '%'}"></div>
+                        <div class="level-meter-peak" v-bind:class="{'level-meter-clip': clipping}" v-bind:style="{left: peakPosition + '%'}"></div>
+                        <div class="level-meter-threshold" v-if="threshold > 0" v-bind:style="{left: thresholdPosition + '%'}"></div>
+                    </div>
+                </div>`,
+    props: {
+        label: String,
+        peak: Number,
+        rms: Number,
+        threshold: Number, // optional, e.g. the vox threshold
+    },
+    methods: {
+        // position of a level (0...1) on the -60...0 dBFS scale in percent
+        position: function (level) {
+            if (!level || level <= 0) {
+                return 0;
+            }
+            var db = 20 * Math.log10(level);
+            return Math.max(0, Math.min(100, (db + 60) / 60 * 100));
+        },
+    },
+    computed: {
+        rmsPosition: function () {
+            return this.position(this.rms);
+        },
+        peakPosition: function () {
+            return this.position(this.peak);
+        },
+        thresholdPosition: function () {
+            return this.position(this.threshold);
+        },
+        clipping: function () {
+            return this.peak >= 0.99;
+        },
+    },
+}
@@ -0,0 +1,71 @@
+var Recordings = {
+    props: ['rxRecording', 'txRecording'],
+    template: `
+    <div class="col-lg-8 col-md-8 col-sm-12">
+        <div class="panel panel-primary">
+            <div class="panel-heading">Recordings</div>
+            <div class="panel-body">
+                <div class="list-group">
+                    <div class="list-group-item">
+                        <button class="btn btn-default btn-raised" v-bind:class="{'btn-danger': rxRecording}" @click="setRecording('rx', !rxRecording)"><i class="fa fa-circle" aria-hidden="true"></i> Record RX</button>
+                        <button class="btn btn-default btn-raised" v-bind:class="{'btn-danger': txRecording}" @click="setRecording('tx', !txRecording)"><i class="fa fa-circle" aria-hidden="true"></i> Record TX</button>
+                    </div>
+                    <div class="list-group-item recordings-list">
+                        <table class="table table-condensed">
+                            <tr v-for="rec in recordings" :key="rec.name">
+                                <td><a :href="'/api/v1.0/recordings/' + rec.name">{{rec.name}}</a></td>
+                                <td class="text-right">{{size(rec.size)}}</td>
+                                <td class="text-right">
+                                    <button class="btn btn-default btn-xs" @click="deleteRecording(rec.name)"><i class="fa fa-trash" aria-hidden="true"></i></button>
+                                </td>
+                            </tr>
+                        </table>
+                        <span v-if="recordings.length === 0">no recordings</span>
+                    </div>
+                </div>
+            </div>
+        </div>
+    </div>
+    `,
+    data: function () {
+        return {
+            recordings: [],
+            timer: null, // periodic refresh of the list (file sizes)
+        }
+    },
+    mounted: function () {
+        this.getRecordings();
+        this.timer = setInterval(this.getRecordings, 5000);
+    },
+    beforeDestroy: function () {
+        clearInterval(this.timer);
+    },
+    methods: {
+        getRecordings: function () {
+            var self = this;
+            this.$http.get("/api/v1.0/recordings").then(function (res) {
+                // newest recordings first
+                self.recordings = res.body.reverse();
+            });
+        },
+        setRecording: function (direction, state) {
+            var msg = {};
+            msg[direction] = state;
+            this.$http.put("/api/v1.0/recording", JSON.stringify(msg))
+                .then(this.getRecordings);
+        },
+        deleteRecording: function (name) {
+            if (!confirm("Delete " + name + "?")) {
+                return
+            }
+            this.$http.delete("/api/v1.0/recordings/" + name)
+                .then(this.getRecordings);
+        },
+        size: function (bytes) {
+            if (bytes < 1024 * 1024) {
+                return Math.round(bytes / 1024) + " kB";
+            }
+            return (bytes / 1024 / 1024).toFixed(1) + " MB";
+        },
+    },
+}
@@ -0,0 +1,145 @@
+var Spectrum = {
+    template: `
+    <div class="col-lg-12 col-md-12 col-sm-12">
+        <div class="panel panel-primary">
+            <div class="panel-heading">Spectrum</div>
+            <div class="panel-body">
+                <div class="list-group">
+                    <div class="list-group-item spectrum-controls">
+                        <label>Span
+                            <select class="form-control" v-model.number="span">
+                                <option v-for="s in spans" :value="s">{{s / 1000}} kHz</option>
+                            </select>
+                        </label>
+                        <label>Floor
+                            <select class="form-control" v-model.number="floor">
+                                <option v-for="f in floors" :value="f">{{f}} dB</option>
+                            </select>
+                        </label>
+                        <span class="spectrum-cursor" v-if="cursor !== null">{{cursor}} Hz</span>
+                    </div>
+                    <div class="list-group-item">
+                        <canvas ref="spectrum" class="spectrum-canvas" height="150"
+                            @mousemove="setCursor" @mouseleave="cursor = null"></canvas>
+                        <canvas ref="waterfall" class="spectrum-canvas" height="250"
+                            @mousemove="setCursor" @mouseleave="cursor = null"></canvas>
+                    </div>
+                </div>
+            </div>
+        </div>
+    </div>
+    `,
+    data: function () {
+        return {
+            ws: null, // websocket carrying the spectra
+            spans: [3000, 4000, 6000, 12000, 24000],
+            span: 4000, // displayed frequency range (Hz)
+            floors: [-140, -120, -100, -80],
+            floor: -120, // level (dBFS) at the bottom of the display
+            cursor: null, // frequency under the mouse pointer
+        }
+    },
+    mounted: function () {
+        this.ws = new ReconnectingWebSocket(wsScheme() + window.location.host + '/ws/spectrum');
+        this.ws.addEventListener('message', this.processSpectrumMsg);
+        window.addEventListener('resize', this.resize);
+        this.resize();
+    },
+    beforeDestroy: function () {
+        window.removeEventListener('resize', this.resize);
+        this.ws.close();
+    },
+    methods: {
+        // match the canvas resolution to its displayed size
+        resize: function () {
+            var sp = this.$refs.spectrum;
+            var wf = this.$refs.waterfall;
+            sp.width = sp.clientWidth;
+            wf.width = wf.clientWidth;
+        },
+        setCursor: function (event) {
+            var rect = event.target.getBoundingClientRect();
+            this.cursor = Math.round((event.clientX - rect.left) / rect.width * this.span);
+        },
+        // level (dBFS) of the bins within the span for each pixel column
+        columns: function (msg, width) {
+            var binWidth = msg.samplerate / msg.size;
+            var cols = new Array(width);
+            for (var x = 0; x < width; x++) {
+                var first = Math.floor(x / width * this.span / binWidth);
+                var last = Math.floor((x + 1) / width * this.span / binWidth);
+                var level = -Infinity;
+                // show the strongest bin if several bins share a column
+                for (var k = first; k <= last && k < msg.bins.length; k++) {
+                    level = Math.max(level, msg.bins[k]);
+                }
+                cols[x] = level;
+            }
+            return cols;
+        },
+        // position (0...1) of a level between the floor and 0 dBFS
+        scale: function (level) {
+            return Math.max(0, Math.min(1, (level - this.floor) / -this.floor));
+        },
+        // color of a level in the waterfall (black - blue - yellow - red)
+        color: function (level) {
+            var v = this.scale(level);
+            if (v < 0.33) {
+                return [0, 0, Math.round(v / 0.33 * 255)];
+            }
+            if (v < 0.66) {
+                var t = (v - 0.33) / 0.33;
+                return [Math.round(t * 255), Math.round(t * 255), Math.round((1 - t) * 255)];
+            }
+            var r = (v - 0.66) / 0.34;
+            return [255, Math.round((1 - r) * 255), 0];
+        },
+        processSpectrumMsg: function (rawMsg) {
+            var msg = JSON.parse(rawMsg.data);
+            var sp = this.$refs.spectrum;
+            var wf = this.$refs.waterfall;
+            var width = sp.width;
+            if (width === 0) {
+                return
+            }
+            var cols = this.columns(msg, width);
+
+            // spectrum
+            var ctx = sp.getContext('2d');
+            ctx.fillStyle = '#000';
+            ctx.fillRect(0, 0, width, sp.height);
+            ctx.strokeStyle = '#333';
+            for (var f = 500; f < this.span; f += 500) {
+                var gx = Math.round(f / this.span * width) + 0.5;
+                ctx.beginPath();
+                ctx.moveTo(gx, 0);
+                ctx.lineTo(gx, sp.height);
+                ctx.stroke();
+            }
+            ctx.strokeStyle = '#ffeb3b';
+            ctx.beginPath();
+            for (var x = 0; x < width; x++) {
+                var y = sp.height - this.scale(cols[x]) * sp.height;
+                if (x === 0) {
+                    ctx.moveTo(x, y);
+                } else {
+                    ctx.lineTo(x, y);
+                }
+            }
+            ctx.stroke();
+
+            // waterfall; scroll down by one line and draw the new line on top
+            var wctx = wf.getContext('2d');
+            wctx.drawImage(wf, 0, 0, wf.width, wf.height - 1, 0, 1, wf.width, wf.height - 1);
+            var line = wctx.createImageData(wf.width, 1);
+            for (var x = 0; x < wf.width; x++) {
+                var c = this.color(cols[x]);
+                line.data[x * 4] = c[0];
+                line.data[x * 4 + 1] = c[1];
+                line.data[x * 4 + 2] = c[2];
+                line.data[x * 4 + 3] = 255;
+            }
+            wctx.putImageData(line, 0, 0);
+        },
+    },
+}
@@ -0,0 +1,50 @@
+package webserver
+
+import (
+	"github.com/dh1tw/remoteAudio/audio/sinks/wsWriter"
+	"github.com/dh1tw/remoteAudio/audio/sources/wsReader"
+)
+
+// Option is the type for a function option
+type Option func(*Options)
+
+// Options is the data structure which holds the optional values of the
+// WebServer. The values are typically provided as functional options.
+type Options struct {
+	AudioSink   *wsWriter.WsWriter
+	AudioSource *wsReader.WsReader
+	TxSource    string
+	TLSCert     string
+	TLSKey      string
+}
+
+// AudioSink is a functional option to set the sink (part of the rx chain)
+// which streams the audio to the clients connected on the /ws/audio
+// endpoint.
+func AudioSink(s *wsWriter.WsWriter) Option {
+	return func(args *Options) {
+		args.AudioSink = s
+	}
+}
+
+// AudioSource is a functional option to set the source (part of the tx
+// chain) into which the audio received on the /ws/audio endpoint (e.g.
+// the microphone of a browser) is fed. The name is the name of the source
+// in the tx chain, which will be selected while a client is talking.
+func AudioSource(s *wsReader.WsReader, name string) Option {
+	return func(args *Options) {
+		args.AudioSource = s
+		args.TxSource = name
+	}
+}
+
+// TLS is a functional option to serve the web interface via HTTPS with
+// the certificate and key (PEM files). Browsers only grant access to the
+// microphone on secure origins, so TLS is required for talking through a
+// browser on another computer.
+func TLS(certFile, keyFile string) Option {
+	return func(args *Options) {
+		args.TLSCert = certFile
+		args.TLSKey = keyFile
+	}
+}
@@ -3,11 +3,26 @@ package webserver
 func (web *WebServer) routes() {
 	web.router.HandleFunc("/api/v1.0/rx/volume", web.rxVolumeHdlr)
 	web.router.HandleFunc("/api/v1.0/tx/volume", web.txVolumeHdlr)
+	web.router.HandleFunc("/api/v1.0/rx/eq", web.rxEQHdlr)
+	web.router.HandleFunc("/api/v1.0/rx/nr", web.rxNRHdlr)
+	web.router.HandleFunc("/api/v1.0/rx/notch", web.rxNotchHdlr)
+	web.router.HandleFunc("/api/v1.0/rx/analyser", web.rxAnalyserHdlr)
+	web.router.HandleFunc("/api/v1.0/tx/eq", web.txEQHdlr)
 	web.router.HandleFunc("/api/v1.0/tx/state", web.txStateHdlr)
 	web.router.HandleFunc("/api/v1.0/tx/vox", web.txVoxStateHdlr)
+	web.router.HandleFunc("/api/v1.0/tx/compressor", web.txCompressorHdlr)
+	web.router.HandleFunc("/api/v1.0/tx/aec", web.txAECHdlr)
+	web.router.HandleFunc("/api/v1.0/tx/mixer", web.txMixerHdlr)
+	web.router.HandleFunc("/api/v1.0/keyer", web.keyerHdlr)
+	web.router.HandleFunc("/api/v1.0/keyer/messages/{message}", web.keyerMessageHdlr)
+	web.router.HandleFunc("/api/v1.0/recording", web.recordingHdlr)
+	web.router.HandleFunc("/api/v1.0/recordings", web.recordingsHdlr).Methods("GET")
+	web.router.HandleFunc("/api/v1.0/recordings/{recording}", web.recordingFileHdlr)
 	web.router.HandleFunc("/api/v1.0/servers", web.serversHdlr).Methods("GET")
 	web.router.HandleFunc("/api/v1.0/server/{server}", web.serverHdlr).Methods("GET")
 	web.router.HandleFunc("/api/v1.0/server/{server}/selected", web.serverSelectedHdlr)
 	web.router.HandleFunc("/api/v1.0/server/{server}/state", web.serverStateHdlr)
 	web.router.HandleFunc("/ws", web.webSocketHdlr)
+	web.router.HandleFunc("/ws/audio", web.audioWebSocketHdlr)
+	web.router.HandleFunc("/ws/spectrum", web.spectrumWebSocketHdlr)
 }
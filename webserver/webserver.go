@@ -12,6 +12,10 @@ import (
 	"time"
 
 	nfs "github.com/dh1tw/nolistfs"
+	"github.com/dh1tw/remoteAudio/audio/nodes/analyser"
+	"github.com/dh1tw/remoteAudio/audio/nodes/eq"
+	"github.com/dh1tw/remoteAudio/audio/nodes/meter"
+	"github.com/dh1tw/remoteAudio/keyer"
 	"github.com/dh1tw/remoteAudio/trx"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
@@ -39,6 +43,11 @@ type ApplicationState struct {
 	VoxEnabled     bool                   `json:"vox_enabled"`
 	VoxThreshold   float32                `json:"vox_threshold"`
 	VoxHoldtime    time.Duration          `json:"vox_holdtime"`
+	AntiVoxGain    float32                `json:"anti_vox_gain"`
+	NrEnabled      bool                   `json:"nr_enabled"`
+	NrStrength     float32                `json:"nr_strength"`
+	RxRecording    bool                   `json:"rx_recording"`
+	TxRecording    bool                   `json:"tx_recording"`
 }
 
 // AudioServer is a data structure which is provided through the
@@ -51,6 +60,35 @@ type AudioServer struct {
 	Latency int    `json:"latency"`
 }
 
+// levelUpdateInterval is the interval in which the audio levels are
+// sent to the websocket clients
+const levelUpdateInterval = time.Millisecond * 100
+
+// Levels is a data structure which is periodically sent to the clients
+// connected on the /ws endpoint. It contains the levels of the rx audio
+// and of the tx audio (the vox input).
+type Levels struct {
+	Rx           *meter.Level `json:"rx,omitempty"`
+	Tx           *meter.Level `json:"tx,omitempty"`
+	VoxEnabled   bool         `json:"vox_enabled"`
+	VoxThreshold float32      `json:"vox_threshold"`
+}
+
+// levelsMsg wraps the Levels, so that the clients can distinguish
+// them from the ApplicationState
+type levelsMsg struct {
+	Levels Levels `json:"levels"`
+}
+
+// spectrumBufferSize is the amount of spectra which are buffered for a
+// client on the /ws/spectrum endpoint. Further spectra are dropped until
+// the client has caught up.
+const spectrumBufferSize = 4
+
+// wsBufferSize is the amount of messages which are buffered for a client
+// on the /ws endpoint. Level updates are dropped while the buffer is full.
+const wsBufferSize = 8
+
 var upgrader = websocket.Upgrader{}
 
 // WebServer is the webserver's data structure holding internal
@@ -65,7 +103,15 @@ type WebServer struct {
 	wsClients      map[*wsClient]bool
 	addWsClient    chan *wsClient
 	removeWsClient chan *wsClient
-	trx            *trx.Trx
+	// clients of the /ws/spectrum endpoint
+	spectrumClients      map[*wsClient]bool
+	addSpectrumClient    chan *wsClient
+	removeSpectrumClient chan *wsClient
+	spectrum             chan analyser.Spectrum
+	// client of the /ws/audio endpoint whose audio is transmitted
+	talker  *websocket.Conn
+	trx     *trx.Trx
+	options Options
 }
 
 // AudioControlState is a data structure which can be get/set through the
@@ -90,6 +136,90 @@ type AudioControlVox struct {
 	VoxEnabled   *bool          `json:"vox_enabled"`
 	VoxThreshold *float32       `json:"vox_threshold"`
 	VoxHoldtime  *time.Duration `json:"vox_holdtime"`
+	AntiVoxGain  *float32       `json:"anti_vox_gain"`
+}
+
+// AudioControlAEC is a data structure which can be get/set through the
+// /api/v{version}/tx/aec endpoint.
+// It is used to enable / disable the acoustic echo canceller.
+type AudioControlAEC struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// AudioControlMixer is a data structure which can be get/set through the
+// /api/v{version}/tx/mixer endpoint.
+// It is used to set the gains (0...2) of the mixer's sources (microphone
+// and voice keyer).
+type AudioControlMixer struct {
+	Gains map[string]float32 `json:"gains"`
+}
+
+// AudioControlKeyer is a data structure which can be get/set through the
+// /api/v{version}/keyer endpoint.
+// It is used to start / stop the playback of a voice keyer message. An
+// empty message stops the playback. OnAir and Messages are read only.
+type AudioControlKeyer struct {
+	Message  *string         `json:"message"`
+	Repeat   *bool           `json:"repeat"`
+	Interval *time.Duration  `json:"interval"`
+	OnAir    *bool           `json:"on_air,omitempty"`
+	Messages []keyer.Message `json:"messages,omitempty"`
+}
+
+// AudioControlRecording is a data structure which can be get/set through
+// the /api/v{version}/recording endpoint.
+// It is used to start / stop the recording of the rx and tx audio.
+type AudioControlRecording struct {
+	Rx *bool `json:"rx"`
+	Tx *bool `json:"tx"`
+}
+
+// AudioControlCompressor is a data structure which can be get/set through
+// the /api/v{version}/tx/compressor endpoint.
+// It is used to get / set the settings of the compressor / limiter in the
+// tx chain. The gain reduction is read only.
+type AudioControlCompressor struct {
+	Enabled       *bool    `json:"enabled"`
+	Threshold     *float32 `json:"threshold"`
+	Ratio         *float32 `json:"ratio"`
+	MakeupGain    *float32 `json:"makeup_gain"`
+	Limit         *float32 `json:"limit"`
+	GainReduction *float32 `json:"gain_reduction,omitempty"`
+}
+
+// AudioControlEQ is a data structure which can be get/set through the
+// /api/v{version}/rx/eq and /api/v{version}/tx/eq endpoints.
+// It is used to get / set the filter sections of the equalizers.
+type AudioControlEQ struct {
+	Enabled  *bool         `json:"enabled"`
+	Sections *[]eq.Section `json:"sections"`
+}
+
+// AudioControlNR is a data structure which can be get/set through the
+// /api/v{version}/rx/nr endpoint.
+// It is used to get / set the noise reduction of the rx audio.
+type AudioControlNR struct {
+	Enabled  *bool    `json:"enabled"`
+	Strength *float32 `json:"strength"`
+}
+
+// AudioControlNotch is a data structure which can be get/set through the
+// /api/v{version}/rx/notch endpoint.
+// It is used to get / set the automatic and manual notch filters of the
+// rx audio.
+type AudioControlNotch struct {
+	Auto      *bool    `json:"auto"`
+	Manual    *bool    `json:"manual"`
+	Frequency *float64 `json:"frequency"`
+}
+
+// AudioControlAnalyser is a data structure which can be get/set through
+// the /api/v{version}/rx/analyser endpoint.
+// It is used to get / set the resolution (fft size) and the rate
+// (spectra per second) of the spectrum analyser.
+type AudioControlAnalyser struct {
+	Size *int     `json:"size"`
+	Rate *float64 `json:"rate"`
 }
 
 // AudioControlSelected is a data structure which can be get/set through the
@@ -101,18 +231,26 @@ type AudioControlSelected struct {
 
 // NewWebServer is the constructor method for a remoteAudio web server.
 // The web server is only available on clients.
-func NewWebServer(url string, port int, trx *trx.Trx) (*WebServer, error) {
+func NewWebServer(url string, port int, trx *trx.Trx, opts ...Option) (*WebServer, error) {
 
 	web := &WebServer{
-		url:            url,
-		port:           port,
-		wsClients:      make(map[*wsClient]bool),
-		addWsClient:    make(chan *wsClient),
-		removeWsClient: make(chan *wsClient),
-		apiVersion:     "1.0",
-		apiMatch:       regexp.MustCompile(`api\/v\d\.\d\/`),
-		router:         mux.NewRouter().StrictSlash(true),
-		trx:            trx,
+		url:                  url,
+		port:                 port,
+		wsClients:            make(map[*wsClient]bool),
+		addWsClient:          make(chan *wsClient),
+		removeWsClient:       make(chan *wsClient),
+		spectrumClients:      make(map[*wsClient]bool),
+		addSpectrumClient:    make(chan *wsClient),
+		removeSpectrumClient: make(chan *wsClient),
+		spectrum:             make(chan analyser.Spectrum, 1),
+		apiVersion:           "1.0",
+		apiMatch:             regexp.MustCompile(`api\/v\d\.\d\/`),
+		router:               mux.NewRouter().StrictSlash(true),
+		trx:                  trx,
+	}
+
+	for _, option := range opts {
+		option(&web.options)
 	}
 
 	return web, nil
@@ -124,6 +262,9 @@ func (web *WebServer) Start() {
 
 	web.trx.SetNotifyServerChangeCb(web.updateWsClients)
 
+	// the spectrum analyser is optional
+	web.trx.SetSpectrumCb(web.onSpectrum)
+
 	// load the HTTP routes with their respective endpoints
 	web.routes()
 
@@ -139,15 +280,26 @@ func (web *WebServer) Start() {
 	web.router.PathPrefix("/").Handler(fileServer)
 
 	serverURL := fmt.Sprintf("%s:%d", web.url, web.port)
-
-	log.Println("webserver listening on", serverURL)
+	handler := web.apiRedirectRouter(web.router)
 
 	go func() {
-		log.Fatal(http.ListenAndServe(serverURL, web.apiRedirectRouter(web.router)))
+		if len(web.options.TLSCert) > 0 {
+			log.Println("webserver listening on", serverURL, "(https)")
+			log.Fatal(http.ListenAndServeTLS(serverURL, web.options.TLSCert,
+				web.options.TLSKey, handler))
+		}
+		log.Println("webserver listening on", serverURL)
+		log.Fatal(http.ListenAndServe(serverURL, handler))
 	}()
 
+	levelTicker := time.NewTicker(levelUpdateInterval)
+	defer levelTicker.Stop()
+
 	for {
 		select {
+		case <-levelTicker.C:
+			web.sendLevels()
+
 		case wsClient := <-web.addWsClient:
 			log.Println("WebSocket client connected from", wsClient.ws.RemoteAddr())
 			web.Lock()
@@ -163,6 +315,20 @@ func (web *WebServer) Start() {
 				close(wsClient.send)
 			}
 			web.Unlock()
+
+		case sp := <-web.spectrum:
+			web.sendSpectrum(sp)
+
+		case wsClient := <-web.addSpectrumClient:
+			log.Println("WebSocket spectrum client connected from", wsClient.ws.RemoteAddr())
+			web.spectrumClients[wsClient] = true
+
+		case wsClient := <-web.removeSpectrumClient:
+			log.Println("WebSocket spectrum client disconnected", wsClient.ws.RemoteAddr())
+			if _, ok := web.spectrumClients[wsClient]; ok {
+				delete(web.spectrumClients, wsClient)
+				close(wsClient.send)
+			}
 		}
 	}
 }
@@ -217,6 +383,15 @@ func (web *WebServer) getAppState() (ApplicationState, error) {
 		VoxEnabled:     web.trx.VOXEnabled(),
 		VoxHoldtime:    web.trx.VOXHoldTime(),
 		VoxThreshold:   web.trx.VOXThreshold(),
+		AntiVoxGain:    web.trx.AntiVOXGain(),
+		RxRecording:    web.trx.RxRecording(),
+		TxRecording:    web.trx.TxRecording(),
+	}
+
+	// the noise reduction is optional
+	if nrEnabled, err := web.trx.NREnabled(); err == nil {
+		appState.NrEnabled = nrEnabled
+		appState.NrStrength, _ = web.trx.NRStrength()
 	}
 
 	return appState, nil
@@ -241,6 +416,79 @@ func (web *WebServer) updateWsClients() {
 	}
 }
 
+// sendLevels sends the current audio levels to all connected websockets.
+func (web *WebServer) sendLevels() {
+
+	web.RLock()
+	defer web.RUnlock()
+
+	if len(web.wsClients) == 0 {
+		return
+	}
+
+	levels := Levels{
+		VoxEnabled:   web.trx.VOXEnabled(),
+		VoxThreshold: web.trx.VOXThreshold(),
+	}
+
+	if rx, err := web.trx.RxLevel(); err == nil {
+		levels.Rx = &rx
+	}
+	if tx, err := web.trx.TxLevel(); err == nil {
+		levels.Tx = &tx
+	}
+
+	if levels.Rx == nil && levels.Tx == nil {
+		return
+	}
+
+	data, err := json.Marshal(levelsMsg{Levels: levels})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	// slow clients skip levels instead of blocking the others
+	for client := range web.wsClients {
+		select {
+		case client.send <- data:
+		default:
+		}
+	}
+}
+
+// onSpectrum is called by the spectrum analyser with each new spectrum.
+// If the previous spectrum hasn't been sent yet, the new one is dropped.
+func (web *WebServer) onSpectrum(sp analyser.Spectrum) {
+	select {
+	case web.spectrum <- sp:
+	default:
+	}
+}
+
+// sendSpectrum sends the spectrum to all clients connected on the
+// /ws/spectrum endpoint. Slow clients skip spectra instead of blocking
+// the others.
+func (web *WebServer) sendSpectrum(sp analyser.Spectrum) {
+
+	if len(web.spectrumClients) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(sp)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	for client := range web.spectrumClients {
+		select {
+		case client.send <- data:
+		default:
+		}
+	}
+}
+
 // write is a function which instantiates a go-routine through which
 // concurrent writing to a websocket is handled.
 func (c *wsClient) write() {